@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"fmt"
 	"log"
@@ -9,14 +10,21 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"1337b04rd/internal/adapters/dhash"
 	"1337b04rd/internal/adapters/fileUtils"
 	"1337b04rd/internal/adapters/handlers"
+	"1337b04rd/internal/adapters/identicon"
+	"1337b04rd/internal/adapters/memory"
+	"1337b04rd/internal/adapters/outlookChain"
 	"1337b04rd/internal/adapters/postgres"
 	"1337b04rd/internal/adapters/rickMorty"
+	"1337b04rd/internal/adapters/scrypt"
 	"1337b04rd/internal/adapters/triples"
+	"1337b04rd/internal/domain"
 	"1337b04rd/internal/services"
 
 	_ "github.com/lib/pq"
@@ -29,13 +37,42 @@ func main() {
 	}
 	defer db.Close()
 
+	secret := challengeSecret()
 	file_utils := fileUtils.NewFileUtils()
 	imageStorage := triples.NewTriples(1414)
-	userOutlook := rickMorty.NewRickMortyAPI()
 
 	userRepo := postgres.NewUserRepository(db)
+	characterRepo := postgres.NewCharacterRepository(db)
 	postRepo := postgres.NewPostRepository(db, "posts")
-	commentRepo := postgres.NewCommentRepository(db, "comments")
+	commentRepo := postgres.NewCommentRepository(db, "comments", secret)
+	moderatorRepo := postgres.NewModeratorRepository(db)
+	reportRepo := postgres.NewReportRepository(db)
+	auditRepo := postgres.NewAuditRepository(db)
+	banRepo := postgres.NewBanRepository(db)
+	shadowBanRepo := postgres.NewShadowBanRepository(db)
+	filterRepo := postgres.NewWordFilterRepository(db)
+	boardSettingsRepo := postgres.NewBoardSettingsRepository(db)
+	queueRepo := postgres.NewQueueRepository(db)
+	threadRepo := postgres.NewThreadRepository(db)
+	fingerprintRepo := postgres.NewFingerprintRepository(db)
+	spamCorpusRepo := postgres.NewSpamCorpusRepository(db)
+	linkRuleRepo := postgres.NewLinkRuleRepository(db)
+	imageRepo := postgres.NewImageRepository(db)
+	notificationRepo := postgres.NewNotificationRepository(db)
+	watchRepo := postgres.NewWatchRepository(db)
+
+	// Background jobs stop together with the server
+	appCtx, stopApp := context.WithCancel(context.Background())
+	defer stopApp()
+
+	characterPool := rickMorty.NewCharacterPool(rickMorty.NewRickMortyAPI(), characterRepo, 24*time.Hour)
+	characterPool.Start(appCtx)
+
+	// Rick & Morty characters when the API is reachable, local identicons otherwise
+	userOutlook := outlookChain.NewOutlookChain(
+		characterPool,
+		identicon.NewIdenticon(),
+	)
 
 	err = imageStorage.CreateBucket("posts")
 	if err != nil {
@@ -49,11 +86,59 @@ func main() {
 		return
 	}
 
-	userService := services.NewUserService(userRepo, userOutlook)
-	postServices := services.NewPostService(postRepo, imageStorage, file_utils, *userService, "posts")
-	commentServices := services.NewCommentService(commentRepo, *userService, imageStorage, file_utils, "comments")
+	var avatarAssigner domain.AvatarAssigner = postgres.NewSequenceAssigner(db)
+	if os.Getenv("AVATAR_ASSIGNMENT") == "random" {
+		avatarAssigner = services.NewShuffleAssigner(rickMorty.CharacterCount, time.Now().UnixNano())
+	}
+
+	uniqueUsernames := os.Getenv("UNIQUE_USERNAMES") == "true"
 
-	router := handlers.NewRouter(*userService, *postServices, *commentServices)
+	auditService := services.NewAuditService(auditRepo)
+	userService := services.NewUserService(userRepo, userOutlook, avatarAssigner, uniqueUsernames, *auditService)
+	banService := services.NewBanService(banRepo, *auditService)
+	imageService := services.NewImageService(imageRepo, dhash.NewDHash(), *banService)
+	filterService := services.NewWordFilterService(filterRepo, *auditService)
+	boardService := services.NewBoardService(boardSettingsRepo, *auditService)
+	duplicateService := services.NewDuplicateService(fingerprintRepo, *boardService)
+	spamScorer := services.NewBayesSpamScorer(spamCorpusRepo)
+	linkService := services.NewLinkPolicyService(linkRuleRepo, *boardService, *auditService)
+	queueService := services.NewQueueService(queueRepo, *auditService)
+	threadService := services.NewThreadService(threadRepo, postRepo, commentRepo, *auditService)
+	notificationService := services.NewNotificationService(notificationRepo)
+	notificationService.Start(appCtx)
+	watchService := services.NewWatchService(watchRepo, postRepo)
+
+	// Several instances have to share their rate limits through the database
+	var rateLimitStore domain.RateLimitStore = memory.NewRateLimitStore()
+	if os.Getenv("RATE_LIMIT_STORE") == "postgres" {
+		rateLimitStore = postgres.NewRateLimitStore(db)
+	}
+	rateLimiter := services.NewRateLimiter(rateLimitStore, domain.DefaultRateLimits)
+
+	// Used challenges and form tokens are shared the same way,
+	// POW_DIFFICULTY=0 turns challenges off
+	var challengeStore domain.ChallengeStore = memory.NewChallengeStore()
+	if os.Getenv("RATE_LIMIT_STORE") == "postgres" {
+		challengeStore = postgres.NewChallengeStore(db)
+	}
+	challengeService := services.NewChallengeService(challengeStore, secret, challengeDifficulty())
+	botTrapService := services.NewBotTrapService(shadowBanRepo, challengeStore, *auditService, secret, botMinFillTime(), botAction())
+	postServices := services.NewPostService(postRepo, imageStorage, file_utils, *userService, *imageService, *auditService, *filterService, *boardService, *duplicateService, *linkService, spamScorer, "posts", secret)
+	commentServices := services.NewCommentService(commentRepo, postRepo, *userService, *imageService, *auditService, *filterService, *boardService, *duplicateService, *linkService, spamScorer, imageStorage, file_utils, "comments")
+	moderatorService := services.NewModeratorService(moderatorRepo, scrypt.NewScrypt())
+	moderatorService.Start(appCtx)
+	reportService := services.NewReportService(reportRepo, postRepo, commentRepo, *banService, *auditService, spamScorer)
+	shadowBanService := services.NewShadowBanService(shadowBanRepo, *auditService)
+
+	// First admin account comes from the environment
+	if adminName, adminPassword := os.Getenv("ADMIN_USERNAME"), os.Getenv("ADMIN_PASSWORD"); adminName != "" {
+		if err := moderatorService.EnsureAdmin(appCtx, adminName, adminPassword); err != nil {
+			slog.Error("Failed to create admin account", "error", err)
+			return
+		}
+	}
+
+	router := handlers.NewRouter(*userService, *postServices, *commentServices, *moderatorService, *reportService, *banService, *shadowBanService, *auditService, *filterService, *linkService, *imageService, *boardService, *queueService, *threadService, *notificationService, *watchService, *rateLimiter, *challengeService, *botTrapService)
 
 	handler := enableCORS(router)
 
@@ -81,6 +166,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopApp()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -113,6 +199,63 @@ func enableCORS(next http.Handler) http.Handler {
 	})
 }
 
+// Challenges and form tokens signed by one instance are only accepted by others when they
+// share POW_SECRET, a random secret is fine for a single instance. Poster IDs
+// are keyed by it too, with a random secret they change on every restart.
+func challengeSecret() []byte {
+	if secret := os.Getenv("POW_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate challenge secret: %v", err)
+	}
+	return secret
+}
+
+// Leading zero bits asked from posters while the board is quiet
+func challengeDifficulty() int {
+	value := os.Getenv("POW_DIFFICULTY")
+	if value == "" {
+		return 16
+	}
+
+	difficulty, err := strconv.Atoi(value)
+	if err != nil || difficulty < 0 || difficulty > 24 {
+		log.Fatalf("Invalid POW_DIFFICULTY %q, expected 0 to 24", value)
+	}
+	return difficulty
+}
+
+// What happens to posting bots: BOT_ACTION=drop (default), shadowban or off
+func botAction() domain.BotAction {
+	value := os.Getenv("BOT_ACTION")
+	if value == "" {
+		return domain.BotActionDrop
+	}
+
+	action := domain.BotAction(value)
+	if !action.Valid() {
+		log.Fatalf("Invalid BOT_ACTION %q, expected drop, shadowban or off", value)
+	}
+	return action
+}
+
+// Seconds a person needs at least to fill in the posting form
+func botMinFillTime() time.Duration {
+	value := os.Getenv("BOT_MIN_FILL_SECONDS")
+	if value == "" {
+		return domain.DefaultMinFormFillTime
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 || seconds > 600 {
+		log.Fatalf("Invalid BOT_MIN_FILL_SECONDS %q, expected 0 to 600", value)
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 func initDB() (*sql.DB, error) {
 	dbURL := os.Getenv("DATABASE_URL")
 
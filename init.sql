@@ -11,10 +11,35 @@ CREATE TABLE IF NOT EXISTS user_sessions (
     session_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     avatar_url TEXT NOT NULL,               -- URL from Rick and Morty API
     username TEXT NOT NULL,           -- Character name from API
+    username_key TEXT NOT NULL DEFAULT '',  -- Skeleton of the name used to spot lookalikes
+    shadow_banned BOOLEAN NOT NULL DEFAULT FALSE, -- Posts are only shown back to the session itself
+    shadow_banned_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     expires_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW() + INTERVAL '7 days'
 );
 
+-- One-time recovery phrases used to move a session to another browser
+CREATE TABLE IF NOT EXISTS session_recovery_keys (
+    key_hash TEXT PRIMARY KEY,              -- SHA-256 of the normalized phrase
+    session_id UUID NOT NULL REFERENCES user_sessions(session_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    used_at TIMESTAMP WITH TIME ZONE,       -- Set when the phrase was exchanged
+    revoked_at TIMESTAMP WITH TIME ZONE     -- Set when a newer phrase was issued
+);
+
+-- Characters of the avatar API, cached so restarts do not refetch them
+CREATE TABLE IF NOT EXISTS character_pool (
+    character_id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    avatar_url TEXT NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Character of the next session, cycles through all characters of the API.
+-- MAXVALUE has to match rickMorty.CharacterCount
+CREATE SEQUENCE IF NOT EXISTS avatar_character_seq
+    MINVALUE 1 MAXVALUE 826 CYCLE;
+
 -- Posts table
 CREATE TABLE IF NOT EXISTS posts (
     post_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -22,10 +47,21 @@ CREATE TABLE IF NOT EXISTS posts (
     title TEXT NOT NULL,
     content TEXT NOT NULL,
     image_urls TEXT[],
+    board TEXT NOT NULL DEFAULT 'b',
+    ip_address INET,                         -- Client address, only used for bans
+    unarchived_at TIMESTAMP WITH TIME ZONE,  -- Restarts the inactivity clock after a moderator unarchives or approves
+    status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('pending', 'published', 'rejected')),
+    pending_reason TEXT,                     -- Why the post went to the approval queue
+    sticky BOOLEAN NOT NULL DEFAULT FALSE,   -- Pinned to the top, never archived
+    locked BOOLEAN NOT NULL DEFAULT FALSE,   -- No new comments
+    cyclical BOOLEAN NOT NULL DEFAULT FALSE, -- Oldest comments are pruned past a cap
     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     archived_at TIMESTAMP WITH TIME ZONE,    -- When post was moved to archive
-    is_archived BOOLEAN NOT NULL DEFAULT FALSE
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    deleted_at TIMESTAMP WITH TIME ZONE,     -- Soft delete, hidden from catalog and archive
+    deleted_by TEXT,                         -- 'poster', 'system' or moderator UUID
+    delete_reason TEXT
 );
 
 -- Comments table
@@ -36,15 +72,215 @@ CREATE TABLE IF NOT EXISTS comments (
     session_id UUID REFERENCES user_sessions(session_id) ON DELETE SET NULL,
     content TEXT NOT NULL,
     image_urls TEXT[],
+    ip_address INET,                         -- Client address, only used for bans
+    status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('pending', 'published', 'rejected')),
+    pending_reason TEXT,                     -- Why the comment went to the approval queue
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE,     -- Soft delete, shown as a tombstone
+    deleted_by TEXT,                         -- 'poster', 'system' or moderator UUID
+    delete_reason TEXT
+);
+
+-- Moderator accounts, unlike users they log in with a password
+CREATE TABLE IF NOT EXISTS moderators (
+    moderator_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,            -- scrypt hash with its parameters
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Roles of moderators, board '*' means every board
+CREATE TABLE IF NOT EXISTS moderator_roles (
+    moderator_id UUID NOT NULL REFERENCES moderators(moderator_id) ON DELETE CASCADE,
+    board TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('janitor', 'moderator', 'admin')),
+    PRIMARY KEY (moderator_id, board)
+);
+
+-- Admin sessions, separate from the anonymous user sessions
+CREATE TABLE IF NOT EXISTS moderator_sessions (
+    token_hash TEXT PRIMARY KEY,            -- SHA-256 of the cookie value
+    moderator_id UUID NOT NULL REFERENCES moderators(moderator_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Reports filed by users against posts and comments
+CREATE TABLE IF NOT EXISTS reports (
+    report_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    target_type TEXT NOT NULL CHECK (target_type IN ('post', 'comment')),
+    target_id UUID NOT NULL,
+    session_id UUID NOT NULL REFERENCES user_sessions(session_id) ON DELETE CASCADE,
+    category TEXT NOT NULL CHECK (category IN ('spam', 'illegal', 'harassment', 'off_topic', 'other')),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_by UUID REFERENCES moderators(moderator_id) ON DELETE SET NULL,
+    resolution TEXT
+);
+
+-- Bans, exactly one of session_id, ip_range and image_sha256 is set
+CREATE TABLE IF NOT EXISTS bans (
+    ban_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind TEXT NOT NULL CHECK (kind IN ('session', 'ip', 'image', 'phash')),
+    session_id UUID,
+    ip_range CIDR,                          -- IPv4 or IPv6, single addresses are /32 or /128
+    image_sha256 TEXT,
+    image_phash TEXT,                       -- 16 hex digits, matched by Hamming distance
+    reason TEXT NOT NULL,                   -- Shown to the banned user
+    expires_at TIMESTAMP WITH TIME ZONE,    -- NULL for permanent bans
+    created_by UUID REFERENCES moderators(moderator_id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (
+        (kind = 'session' AND session_id IS NOT NULL AND ip_range IS NULL AND image_sha256 IS NULL AND image_phash IS NULL) OR
+        (kind = 'ip' AND ip_range IS NOT NULL AND session_id IS NULL AND image_sha256 IS NULL AND image_phash IS NULL) OR
+        (kind = 'image' AND image_sha256 IS NOT NULL AND session_id IS NULL AND ip_range IS NULL AND image_phash IS NULL) OR
+        (kind = 'phash' AND image_phash IS NOT NULL AND session_id IS NULL AND ip_range IS NULL AND image_sha256 IS NULL)
+    )
+);
+
+-- Per-board settings, the '*' row holds the defaults of every board
+CREATE TABLE IF NOT EXISTS board_settings (
+    board TEXT PRIMARY KEY,
+    premod_minutes INTEGER NOT NULL DEFAULT 0, -- Sessions younger than this post into the approval queue
+    duplicate_minutes INTEGER NOT NULL DEFAULT 60, -- Window in which repeated text is rejected, 0 is off
+    robot9000 BOOLEAN NOT NULL DEFAULT FALSE, -- Reject any text ever posted on the board
+    max_links INTEGER NOT NULL DEFAULT 3,   -- Most links in one post, 0 allows none
+    link_min_session_minutes INTEGER NOT NULL DEFAULT 60, -- Younger sessions can not post links
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Word filters, applied to new posts and comments in creation order
+CREATE TABLE IF NOT EXISTS word_filters (
+    filter_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    board TEXT NOT NULL,                    -- '*' for every board
+    pattern TEXT NOT NULL,
+    is_regex BOOLEAN NOT NULL DEFAULT FALSE,
+    action TEXT NOT NULL CHECK (action IN ('replace', 'reject', 'hold')),
+    replacement TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',       -- Shown to the poster on reject
+    created_by UUID REFERENCES moderators(moderator_id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Allowed and denied link domains, subdomains included
+CREATE TABLE IF NOT EXISTS link_rules (
+    rule_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    board TEXT NOT NULL,                    -- '*' for every board
+    domain TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('allow', 'deny')),
+    created_by UUID REFERENCES moderators(moderator_id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (board, domain)
+);
+
+-- Token buckets of the posting rate limits, see RATE_LIMIT_STORE
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    bucket_key TEXT PRIMARY KEY,            -- Action and session or address, e.g. 'thread:ip:203.0.113.7'
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Replies and quotes of what a session posted, pruned once the session expires
+CREATE TABLE IF NOT EXISTS notifications (
+    notification_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES user_sessions(session_id) ON DELETE CASCADE, -- Who is notified
+    comment_id UUID NOT NULL REFERENCES comments(comment_id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('reply', 'quote')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (session_id, comment_id)
+);
+
+-- Threads a session watches, replies after last_viewed_at count as new
+CREATE TABLE IF NOT EXISTS thread_watches (
+    session_id UUID NOT NULL REFERENCES user_sessions(session_id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_viewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (session_id, post_id)
+);
+
+-- Hashes of every uploaded image, found in posts and comments by URL
+CREATE TABLE IF NOT EXISTS images (
+    image_url TEXT PRIMARY KEY,
+    sha256 TEXT NOT NULL,
+    phash BIGINT,                           -- 64 bit dHash stored as signed, NULL when not decodable
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Normalized text hashes of every post and comment, for duplicate detection
+CREATE TABLE IF NOT EXISTS content_fingerprints (
+    fingerprint_id BIGSERIAL PRIMARY KEY,
+    board TEXT NOT NULL,
+    content_hash TEXT NOT NULL,             -- SHA-256 of the normalized text
+    simhash BIGINT NOT NULL,                -- 64 bits stored as signed, 0 for short texts
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Naive Bayes spam scorer, trained from moderator decisions
+CREATE TABLE IF NOT EXISTS spam_tokens (
+    token TEXT PRIMARY KEY,                 -- Normalized word
+    spam_count INTEGER NOT NULL DEFAULT 0,  -- Spam texts containing the token
+    ham_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS spam_totals (
+    is_spam BOOLEAN PRIMARY KEY,
+    texts INTEGER NOT NULL DEFAULT 0        -- Texts learned of this kind
+);
+
+-- Spent proof-of-work challenges, kept until they expire
+CREATE TABLE IF NOT EXISTS used_challenges (
+    nonce TEXT PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Moderator actions, append-only (see trigger_audit_log_append_only)
+CREATE TABLE IF NOT EXISTS audit_log (
+    entry_id BIGSERIAL PRIMARY KEY,
+    moderator_id UUID NOT NULL,             -- No foreign key, entries outlive accounts
+    moderator TEXT NOT NULL,                -- Username at the time of the action
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    before JSONB,                           -- Snapshot of the target before the action
+    after JSONB,                            -- Snapshot of the target after the action
     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
 );
 
 -- Indexes for performance
 CREATE INDEX IF NOT EXISTS idx_posts_created ON posts(created_at);
 CREATE INDEX IF NOT EXISTS idx_posts_archived ON posts(is_archived, archived_at);
+CREATE INDEX IF NOT EXISTS idx_posts_board ON posts(board, created_at);
 CREATE INDEX IF NOT EXISTS idx_comments_post ON comments(post_id);
 CREATE INDEX IF NOT EXISTS idx_comments_parent ON comments(parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_post_created ON comments(post_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_thread_watches_post ON thread_watches(post_id);
 CREATE INDEX IF NOT EXISTS idx_user_sessions_expires ON user_sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_username_key ON user_sessions(username_key);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_shadow_banned ON user_sessions(session_id) WHERE shadow_banned;
+CREATE INDEX IF NOT EXISTS idx_recovery_keys_session ON session_recovery_keys(session_id);
+CREATE INDEX IF NOT EXISTS idx_moderator_sessions_expires ON moderator_sessions(expires_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_open_unique ON reports(target_type, target_id, session_id) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_reports_open ON reports(target_type, target_id) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_moderator ON audit_log(moderator_id, entry_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_posts_pending ON posts(created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_comments_pending ON comments(created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated ON rate_limit_buckets(updated_at);
+CREATE INDEX IF NOT EXISTS idx_used_challenges_expires ON used_challenges(expires_at);
+CREATE INDEX IF NOT EXISTS idx_content_fingerprints_hash ON content_fingerprints(content_hash, board);
+CREATE INDEX IF NOT EXISTS idx_content_fingerprints_created ON content_fingerprints(created_at) WHERE simhash <> 0;
+CREATE INDEX IF NOT EXISTS idx_bans_session ON bans(session_id) WHERE kind = 'session';
+CREATE INDEX IF NOT EXISTS idx_bans_ip_range ON bans USING gist (ip_range inet_ops) WHERE kind = 'ip';
+CREATE INDEX IF NOT EXISTS idx_bans_image ON bans(image_sha256) WHERE kind = 'image';
+CREATE INDEX IF NOT EXISTS idx_notifications_session ON notifications(session_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_image_urls ON posts USING gin (image_urls);
+CREATE INDEX IF NOT EXISTS idx_comments_image_urls ON comments USING gin (image_urls);
 
 -- Function to update timestamp on post update
 CREATE OR REPLACE FUNCTION update_post_timestamp()
@@ -60,28 +296,51 @@ BEFORE UPDATE ON posts
 FOR EACH ROW
 EXECUTE FUNCTION update_post_timestamp();
 
+-- The audit log can only be appended to
+CREATE OR REPLACE FUNCTION audit_log_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_audit_log_append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER trigger_audit_log_no_truncate
+BEFORE TRUNCATE ON audit_log
+FOR EACH STATEMENT
+EXECUTE FUNCTION audit_log_append_only();
+
 -- Function to archive old posts
 CREATE OR REPLACE FUNCTION archive_old_posts()
 RETURNS VOID AS $$
 BEGIN
-    -- Archive posts without comments after 10 minutes
+    -- Archive posts without comments after 10 minutes, sticky threads and
+    -- threads waiting for approval stay
     UPDATE posts
     SET is_archived = TRUE, archived_at = NOW()
     WHERE is_archived = FALSE
-    AND created_at < NOW() - INTERVAL '10 minutes'
+    AND sticky = FALSE
+    AND status = 'published'
+    AND GREATEST(created_at, unarchived_at) < NOW() - INTERVAL '10 minutes'
     AND NOT EXISTS (
         SELECT 1 FROM comments 
-        WHERE comments.post_id = posts.post_id
+        WHERE comments.post_id = posts.post_id AND comments.status = 'published'
     );
     
     -- Archive posts with comments after 15 minutes of inactivity
     UPDATE posts
     SET is_archived = TRUE, archived_at = NOW()
     WHERE is_archived = FALSE
-    AND (
+    AND sticky = FALSE
+    AND status = 'published'
+    AND GREATEST((
         SELECT MAX(created_at) FROM comments 
-        WHERE comments.post_id = posts.post_id
-    ) < NOW() - INTERVAL '15 minutes';
+        WHERE comments.post_id = posts.post_id AND comments.status = 'published'
+    ), unarchived_at) < NOW() - INTERVAL '15 minutes';
 END;
 $$ LANGUAGE plpgsql;
 
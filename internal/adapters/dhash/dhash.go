@@ -0,0 +1,94 @@
+package dhash
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"1337b04rd/internal/domain"
+)
+
+// DHash computes difference hashes: the image is shrunk to a 9x8 grayscale
+// grid and every bit tells whether a cell is brighter than its right
+// neighbour. Resizing and re-encoding keep the gradients, so copies end up
+// a few bits apart.
+type DHash struct {
+	samples   int // Sample points per cell side
+	maxPixels int // Larger images are not decoded at all, decoding takes 4 bytes per pixel
+}
+
+var _ domain.PerceptualHasher = (*DHash)(nil)
+
+const (
+	gridWidth  = 9
+	gridHeight = 8
+)
+
+func NewDHash() *DHash {
+	return &DHash{
+		samples:   4,
+		maxPixels: 16_000_000,
+	}
+}
+
+func (d *DHash) Hash(data []byte) (uint64, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read image header: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > d.maxPixels {
+		return 0, fmt.Errorf("image size %dx%d is not supported", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	grid := d.shrink(img)
+
+	var hash uint64
+	for y := 0; y < gridHeight; y++ {
+		for x := 0; x < gridWidth-1; x++ {
+			hash <<= 1
+			if grid[y][x] > grid[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash, nil
+}
+
+// Average brightness of every grid cell, sampled on a few points per cell
+// instead of reading every pixel of a large image
+func (d *DHash) shrink(img image.Image) [gridHeight][gridWidth]float64 {
+	var grid [gridHeight][gridWidth]float64
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	for gy := 0; gy < gridHeight; gy++ {
+		for gx := 0; gx < gridWidth; gx++ {
+			var sum float64
+			for sy := 0; sy < d.samples; sy++ {
+				for sx := 0; sx < d.samples; sx++ {
+					// Centers of the sub-cells, scaled to the image
+					x := bounds.Min.X + ((gx*d.samples+sx)*2+1)*width/(gridWidth*d.samples*2)
+					y := bounds.Min.Y + ((gy*d.samples+sy)*2+1)*height/(gridHeight*d.samples*2)
+					sum += luma(img, x, y)
+				}
+			}
+			grid[gy][gx] = sum / float64(d.samples*d.samples)
+		}
+	}
+	return grid
+}
+
+// Rec. 601 luma, transparent pixels count as black
+func luma(img image.Image, x, y int) float64 {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+}
@@ -0,0 +1,97 @@
+package dhash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/bits"
+	"testing"
+)
+
+// Diagonal gradient with a bright disc, drawn at any size
+func drawPicture(width, height int, inverted bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			fx, fy := float64(x)/float64(width), float64(y)/float64(height)
+			v := uint8(255 * (fx + fy) / 2)
+			if dx, dy := fx-0.3, fy-0.6; dx*dx+dy*dy < 0.04 {
+				v = 255 - v/4
+			}
+			if inverted {
+				v = 255 - v
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 40}); err != nil {
+		t.Fatalf("failed to encode jpeg: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDHash_SimilarImages(t *testing.T) {
+	hasher := NewDHash()
+
+	original, err := hasher.Hash(encodePNG(t, drawPicture(640, 480, false)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	again, err := hasher.Hash(encodePNG(t, drawPicture(640, 480, false)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again != original {
+		t.Errorf("expected the same hash for the same image, got %016x and %016x", original, again)
+	}
+
+	// Smaller copy saved as a low quality JPEG
+	copied, err := hasher.Hash(encodeJPEG(t, drawPicture(200, 150, false)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if distance := bits.OnesCount64(original ^ copied); distance > 8 {
+		t.Errorf("expected resized copy within 8 bits, got %d", distance)
+	}
+
+	different, err := hasher.Hash(encodePNG(t, drawPicture(640, 480, true)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if distance := bits.OnesCount64(original ^ different); distance < 20 {
+		t.Errorf("expected different image over 20 bits away, got %d", distance)
+	}
+}
+
+func TestDHash_NotAnImage(t *testing.T) {
+	if _, err := NewDHash().Hash([]byte("GIF89a but not really")); err == nil {
+		t.Fatal("expected error for broken image")
+	}
+}
+
+func TestDHash_TooLarge(t *testing.T) {
+	hasher := &DHash{samples: 4, maxPixels: 640 * 480}
+
+	if _, err := hasher.Hash(encodePNG(t, drawPicture(640, 480, false))); err != nil {
+		t.Fatalf("unexpected error at the limit: %v", err)
+	}
+	if _, err := hasher.Hash(encodePNG(t, drawPicture(641, 480, false))); err == nil {
+		t.Fatal("expected images over the pixel limit to be refused")
+	}
+}
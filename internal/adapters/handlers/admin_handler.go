@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type AdminHandlers struct {
+	moderatorService services.ModeratorService
+	userService      services.UserService
+	postService      services.PostService
+	commentService   services.CommentService
+}
+
+func newAdminHandlers(moderatorService services.ModeratorService, userService services.UserService, postService services.PostService, commentService services.CommentService) *AdminHandlers {
+	return &AdminHandlers{
+		moderatorService: moderatorService,
+		userService:      userService,
+		postService:      postService,
+		commentService:   commentService,
+	}
+}
+
+func (h *AdminHandlers) login(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Admin login handler:")
+
+	var req domain.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid login request", http.StatusBadRequest)
+		return
+	}
+
+	token, moderator, err := h.moderatorService.Login(r.Context(), req.Username, req.Password, clientIP(r))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	setAdminToken(w, token)
+	respondJSON(w, r, moderator, http.StatusOK)
+}
+
+func (h *AdminHandlers) logout(w http.ResponseWriter, r *http.Request) {
+	token, err := getAdminToken(r)
+	if err == nil {
+		if err := h.moderatorService.Logout(r.Context(), token); err != nil {
+			slog.Error("Error when deleting admin session:", "error", err)
+		}
+	}
+
+	clearAdminToken(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandlers) getMe(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, moderatorFromContext(r.Context()), http.StatusOK)
+}
+
+func (h *AdminHandlers) listModerators(w http.ResponseWriter, r *http.Request) {
+	moderators, err := h.moderatorService.ListModerators(r.Context())
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, moderators, http.StatusOK)
+}
+
+func (h *AdminHandlers) createModerator(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateModeratorReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid moderator request", http.StatusBadRequest)
+		return
+	}
+
+	moderator, err := h.moderatorService.CreateModerator(r.Context(), &req)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, moderator, http.StatusCreated)
+}
+
+func (h *AdminHandlers) setModeratorRoles(w http.ResponseWriter, r *http.Request) {
+	var req domain.SetRolesReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid roles request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.moderatorService.SetRoles(r.Context(), r.PathValue("id"), req.Roles); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandlers) deletePost(w http.ResponseWriter, r *http.Request) {
+	var req domain.ModerationReq
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		respondError(w, r, "Invalid moderation request", http.StatusBadRequest)
+		return
+	}
+
+	err := h.postService.DeletePostByModerator(r.Context(), r.PathValue("id"), moderatorFromContext(r.Context()), req.Reason, req.Spam)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandlers) deleteComment(w http.ResponseWriter, r *http.Request) {
+	var req domain.ModerationReq
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		respondError(w, r, "Invalid moderation request", http.StatusBadRequest)
+		return
+	}
+
+	err := h.commentService.DeleteCommentByModerator(r.Context(), r.PathValue("id"), moderatorFromContext(r.Context()), req.Reason, req.Spam)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Handler turning a thread flag on (PUT) or off (DELETE)
+
+func (h *AdminHandlers) setThreadFlag(flag domain.ThreadFlag, on bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req domain.ModerationReq
+		if err := decodeOptionalJSON(r, &req); err != nil {
+			respondError(w, r, "Invalid moderation request", http.StatusBadRequest)
+			return
+		}
+
+		err := h.postService.SetThreadFlag(r.Context(), r.PathValue("id"), moderatorFromContext(r.Context()), flag, on, req.Reason)
+		if err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *AdminHandlers) unarchivePost(w http.ResponseWriter, r *http.Request) {
+	var req domain.ModerationReq
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		respondError(w, r, "Invalid moderation request", http.StatusBadRequest)
+		return
+	}
+
+	err := h.postService.UnarchivePost(r.Context(), r.PathValue("id"), moderatorFromContext(r.Context()), req.Reason)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandlers) renameSession(w http.ResponseWriter, r *http.Request) {
+	var req domain.RenameSessionReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid rename request", http.StatusBadRequest)
+		return
+	}
+
+	err := h.userService.RenameByModerator(r.Context(), moderatorFromContext(r.Context()), r.PathValue("id"), req.Username, req.Reason)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type AuditHandlers struct {
+	auditService services.AuditService
+}
+
+func newAuditHandlers(auditService services.AuditService) *AuditHandlers {
+	return &AuditHandlers{
+		auditService: auditService,
+	}
+}
+
+var auditCSVHeader = []string{"id", "created_at", "moderator_id", "moderator", "action", "target_type", "target_id", "reason", "before", "after"}
+
+// Newest entries first, filtered by ?moderator=&action=&target_type=&target_id=&since=&until=
+// and paged by ?limit=&offset=
+
+func (h *AuditHandlers) listAudit(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		respondError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.auditService.FindEntries(r.Context(), filter)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, entries, http.StatusOK)
+}
+
+// Every matching entry, oldest first, as ?format=csv (default) or jsonl
+
+func (h *AuditHandlers) exportAudit(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		respondError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	var write func(*domain.AuditEntry) error
+	var flush func()
+
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit.csv"`)
+		if err := writer.Write(auditCSVHeader); err != nil {
+			return
+		}
+		write = func(entry *domain.AuditEntry) error {
+			return writer.Write([]string{
+				strconv.FormatInt(entry.ID, 10),
+				entry.CreatedAt.UTC().Format(time.RFC3339),
+				entry.ModeratorID,
+				entry.Moderator,
+				entry.Action,
+				string(entry.TargetType),
+				entry.TargetID,
+				entry.Reason,
+				string(entry.Before),
+				string(entry.After),
+			})
+		}
+		flush = writer.Flush
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+		write = func(entry *domain.AuditEntry) error {
+			return encoder.Encode(entry)
+		}
+		flush = func() {}
+	default:
+		respondError(w, r, "Export format must be csv or jsonl", http.StatusBadRequest)
+		return
+	}
+
+	// Headers are already sent, a failure can only cut the export short
+	if err := h.auditService.EachEntry(r.Context(), filter, write); err != nil {
+		slog.Error("Audit export failed:", "error", err)
+	}
+	flush()
+}
+
+func parseAuditFilter(query url.Values) (*domain.AuditFilter, error) {
+	filter := &domain.AuditFilter{
+		ModeratorID: query.Get("moderator"),
+		Action:      query.Get("action"),
+		TargetType:  domain.TargetType(query.Get("target_type")),
+		TargetID:    query.Get("target_id"),
+	}
+
+	for name, dest := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dest = &t
+		}
+	}
+
+	for name, dest := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dest = n
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type BanHandlers struct {
+	banService services.BanService
+}
+
+func newBanHandlers(banService services.BanService) *BanHandlers {
+	return &BanHandlers{
+		banService: banService,
+	}
+}
+
+func (h *BanHandlers) listBans(w http.ResponseWriter, r *http.Request) {
+	bans, err := h.banService.ListBans(r.Context())
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, bans, http.StatusOK)
+}
+
+func (h *BanHandlers) createBan(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateBanReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid ban request", http.StatusBadRequest)
+		return
+	}
+
+	ban, err := h.banService.CreateBan(r.Context(), moderatorFromContext(r.Context()), &req)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, ban, http.StatusCreated)
+}
+
+func (h *BanHandlers) liftBan(w http.ResponseWriter, r *http.Request) {
+	if err := h.banService.LiftBan(r.Context(), moderatorFromContext(r.Context()), r.PathValue("id")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
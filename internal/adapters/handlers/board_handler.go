@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type BoardHandlers struct {
+	boardService services.BoardService
+}
+
+func newBoardHandlers(boardService services.BoardService) *BoardHandlers {
+	return &BoardHandlers{
+		boardService: boardService,
+	}
+}
+
+func (h *BoardHandlers) getSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.boardService.GetSettings(r.Context(), r.PathValue("board"))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, settings, http.StatusOK)
+}
+
+func (h *BoardHandlers) updateSettings(w http.ResponseWriter, r *http.Request) {
+	var req domain.UpdateBoardSettingsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid board settings", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.boardService.UpdateSettings(r.Context(), moderatorFromContext(r.Context()), r.PathValue("board"), &req)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, settings, http.StatusOK)
+}
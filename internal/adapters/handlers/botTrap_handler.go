@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type BotTrapHandlers struct {
+	botTrapService services.BotTrapService
+}
+
+func newBotTrapHandlers(botTrapService services.BotTrapService) *BotTrapHandlers {
+	return &BotTrapHandlers{
+		botTrapService: botTrapService,
+	}
+}
+
+// Signed timestamp requested by the posting pages when they load, tokens are
+// bound to the session so it has to exist first
+
+func (h *BotTrapHandlers) getFormToken(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := h.botTrapService.IssueFormToken(sessionID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, r, token, http.StatusOK)
+}
+
+func (h *BotTrapHandlers) getStats(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, h.botTrapService.Stats(), http.StatusOK)
+}
+
+// Run the bot heuristics on the multipart form of a new thread or comment.
+// Returns true when the request was answered: dropped bot submissions get an
+// empty success response, so the bot has no reason to try again.
+func trapBot(botTrapService services.BotTrapService, w http.ResponseWriter, r *http.Request) bool {
+	submission := &domain.FormSubmission{
+		FormToken:      r.FormValue("form_token"),
+		UserAgent:      r.UserAgent(),
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+	}
+	for _, field := range domain.HoneypotFields {
+		submission.Honeypots = append(submission.Honeypots, r.FormValue(field))
+	}
+
+	sessionID, _ := getSessionID(r)
+	drop, err := botTrapService.Check(r.Context(), sessionID, submission)
+	if err != nil {
+		respondPostingError(w, r, err)
+		return true
+	}
+	if drop {
+		respondJSON(w, r, map[string]string{}, http.StatusCreated)
+		return true
+	}
+	return false
+}
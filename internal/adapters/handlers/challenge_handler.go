@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"1337b04rd/internal/services"
+)
+
+type ChallengeHandlers struct {
+	challengeService services.ChallengeService
+}
+
+func newChallengeHandlers(challengeService services.ChallengeService) *ChallengeHandlers {
+	return &ChallengeHandlers{
+		challengeService: challengeService,
+	}
+}
+
+// Fresh proof-of-work challenge, solved by the browser before posting
+
+func (h *ChallengeHandlers) getChallenge(w http.ResponseWriter, r *http.Request) {
+	challenge, err := h.challengeService.Issue()
+	if err != nil {
+		respondError(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, r, challenge, http.StatusOK)
+}
+
+// Solved challenge sent with the multipart form of a new thread or comment
+func verifyChallenge(challengeService services.ChallengeService, r *http.Request) error {
+	return challengeService.Verify(r.Context(), r.FormValue("pow_challenge"), r.FormValue("pow_solution"))
+}
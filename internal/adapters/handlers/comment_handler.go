@@ -10,12 +10,18 @@ import (
 )
 
 type CommentHandlers struct {
-	commentService services.CommentService
+	commentService   services.CommentService
+	challengeService services.ChallengeService
+	botTrapService   services.BotTrapService
+	watchService     services.WatchService
 }
 
-func newCommentHandlers(commentService services.CommentService) *CommentHandlers {
+func newCommentHandlers(commentService services.CommentService, challengeService services.ChallengeService, botTrapService services.BotTrapService, watchService services.WatchService) *CommentHandlers {
 	return &CommentHandlers{
-		commentService: commentService,
+		commentService:   commentService,
+		challengeService: challengeService,
+		botTrapService:   botTrapService,
+		watchService:     watchService,
 	}
 }
 
@@ -32,6 +38,15 @@ func (h *CommentHandlers) createCommentAPI(w http.ResponseWriter, r *http.Reques
 
 	slog.Info("Parsed multipart form")
 
+	if trapBot(h.botTrapService, w, r) {
+		return
+	}
+
+	if err := verifyChallenge(h.challengeService, r); err != nil {
+		respondPostingError(w, r, err)
+		return
+	}
+
 	createReq.Content = r.FormValue("content")
 	createReq.PostID = r.FormValue("thread_id")
 
@@ -50,9 +65,11 @@ func (h *CommentHandlers) createCommentAPI(w http.ResponseWriter, r *http.Reques
 
 	slog.Info("Got sessionID")
 
+	createReq.ClientIP = clientIP(r)
+
 	comment, err := h.commentService.CreateComment(r.Context(), &createReq)
 	if err != nil {
-		respondError(w, r, err.Error(), http.StatusInternalServerError)
+		respondPostingError(w, r, err)
 		return
 	}
 
@@ -69,7 +86,8 @@ func (h *CommentHandlers) loadCommentsApi(w http.ResponseWriter, r *http.Request
 
 	slog.Info("Got id from URL:", "id", postID)
 
-	comments, err := h.commentService.LoadComments(r.Context(), postID)
+	viewer, _ := getSessionID(r)
+	comments, err := h.commentService.LoadComments(r.Context(), postID, viewer)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			respondError(w, r, "Post not found", http.StatusNotFound)
@@ -82,6 +100,26 @@ func (h *CommentHandlers) loadCommentsApi(w http.ResponseWriter, r *http.Request
 
 	slog.Info("Loaded comments")
 
+	// Replies of a watched thread are seen once they are loaded
+	h.watchService.MarkViewed(r.Context(), viewer, postID)
+
 	respondJSON(w, r, comments, http.StatusOK)
 	return
 }
+
+// Author deletes their own comment, it stays in the thread as a tombstone
+
+func (h *CommentHandlers) deleteCommentAPI(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.commentService.DeleteCommentByPoster(r.Context(), r.PathValue("id"), sessionID); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
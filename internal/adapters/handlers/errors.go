@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"1337b04rd/internal/domain"
+)
+
+// Status codes of policy errors that are not plain validation failures
+var policyStatus = map[*domain.PolicyError]int{
+	domain.ErrUsernameReserved:  http.StatusForbidden,
+	domain.ErrUsernameTaken:     http.StatusConflict,
+	domain.ErrModeratorExists:   http.StatusConflict,
+	domain.ErrThreadLocked:      http.StatusForbidden,
+	domain.ErrNotArchived:       http.StatusConflict,
+	domain.ErrNotPending:        http.StatusConflict,
+	domain.ErrLinkRuleExists:    http.StatusConflict,
+	domain.ErrChallengeRequired: http.StatusForbidden,
+	domain.ErrChallengeInvalid:  http.StatusForbidden,
+	domain.ErrChallengeUsed:     http.StatusForbidden,
+	domain.ErrChallengeUnsolved: http.StatusForbidden,
+	domain.ErrFormExpired:       http.StatusForbidden,
+	domain.ErrFormUsed:          http.StatusForbidden,
+	domain.ErrTooManyImages:     http.StatusRequestEntityTooLarge,
+}
+
+// Respond with the error returned by a service, the status code is picked by
+// the kind of the error. Unknown errors are logged and hidden from the client.
+
+func respondServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var policyErr *domain.PolicyError
+	var banNotice *domain.BanNotice
+	var rateErr *domain.RateLimitError
+
+	switch {
+	case errors.As(err, &rateErr):
+		w.Header().Set("Retry-After", strconv.Itoa(rateErr.RetrySeconds()))
+		respondJSON(w, r, map[string]any{
+			"error":       rateErr.Error(),
+			"code":        "rate_limited",
+			"retry_after": rateErr.RetrySeconds(),
+		}, http.StatusTooManyRequests)
+	case errors.As(err, &banNotice):
+		respondJSON(w, r, map[string]any{
+			"error":      banNotice.Error(),
+			"code":       "banned",
+			"reason":     banNotice.Reason,
+			"expires_at": banNotice.ExpiresAt,
+		}, http.StatusForbidden)
+	case errors.As(err, &policyErr):
+		status, ok := policyStatus[policyErr]
+		if !ok {
+			status = http.StatusUnprocessableEntity
+		}
+		respondJSON(w, r, map[string]string{"error": policyErr.Message, "code": policyErr.Code}, status)
+	case errors.Is(err, domain.ErrNotFound):
+		respondError(w, r, "Not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrTooManyAttempts):
+		respondError(w, r, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrUnauthorized):
+		respondError(w, r, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, domain.ErrForbidden):
+		respondError(w, r, err.Error(), http.StatusForbidden)
+	default:
+		slog.Error("Internal server error:", "error", err)
+		respondError(w, r, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Errors of the posting pipeline: bans, rate limits and rule violations are
+// reported like any service error, other failures keep their message for the
+// poster
+
+func respondPostingError(w http.ResponseWriter, r *http.Request, err error) {
+	var policyErr *domain.PolicyError
+	var banNotice *domain.BanNotice
+	var rateErr *domain.RateLimitError
+
+	if errors.As(err, &policyErr) || errors.As(err, &banNotice) || errors.As(err, &rateErr) || errors.Is(err, domain.ErrNotFound) {
+		respondServiceError(w, r, err)
+		return
+	}
+	respondError(w, r, err.Error(), http.StatusInternalServerError)
+}
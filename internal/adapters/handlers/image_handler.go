@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type ImageHandlers struct {
+	imageService services.ImageService
+}
+
+func newImageHandlers(imageService services.ImageService) *ImageHandlers {
+	return &ImageHandlers{
+		imageService: imageService,
+	}
+}
+
+// Images that look like ?url= of an uploaded image or ?phash=, closest first,
+// within ?distance= bits
+
+func (h *ImageHandlers) findSimilar(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	distance := domain.PHashDefaultDistance
+	if value := query.Get("distance"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			respondError(w, r, "Invalid distance", http.StatusBadRequest)
+			return
+		}
+		distance = parsed
+	}
+
+	images, err := h.imageService.FindSimilar(r.Context(), moderatorFromContext(r.Context()), query.Get("url"), query.Get("phash"), distance)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, images, http.StatusOK)
+}
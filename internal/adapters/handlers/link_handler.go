@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type LinkPolicyHandlers struct {
+	linkService services.LinkPolicyService
+}
+
+func newLinkPolicyHandlers(linkService services.LinkPolicyService) *LinkPolicyHandlers {
+	return &LinkPolicyHandlers{
+		linkService: linkService,
+	}
+}
+
+func (h *LinkPolicyHandlers) listRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.linkService.ListRules(r.Context(), moderatorFromContext(r.Context()))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, rules, http.StatusOK)
+}
+
+func (h *LinkPolicyHandlers) createRule(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateLinkRuleReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid link rule request", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.linkService.CreateRule(r.Context(), moderatorFromContext(r.Context()), &req)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, rule, http.StatusCreated)
+}
+
+func (h *LinkPolicyHandlers) deleteRule(w http.ResponseWriter, r *http.Request) {
+	if err := h.linkService.DeleteRule(r.Context(), moderatorFromContext(r.Context()), r.PathValue("id")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
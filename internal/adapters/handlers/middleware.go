@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type contextKey int
+
+const moderatorKey contextKey = iota
+
+// Only logged in moderators get through, the moderator is stored in the
+// request context for the handlers below
+
+func requireModerator(moderatorService services.ModeratorService, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := getAdminToken(r)
+		if err != nil {
+			respondServiceError(w, r, domain.ErrUnauthorized)
+			return
+		}
+
+		moderator, err := moderatorService.Authenticate(r.Context(), token)
+		if err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), moderatorKey, moderator)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Only moderators with the role on every board get through
+
+func requireGlobalRole(role domain.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(w, r, domain.AllBoards, role) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Check that the current moderator has the role on the board, responds with
+// 403 and returns false otherwise
+
+func authorize(w http.ResponseWriter, r *http.Request, board string, role domain.Role) bool {
+	moderator := moderatorFromContext(r.Context())
+	if moderator == nil {
+		respondServiceError(w, r, domain.ErrUnauthorized)
+		return false
+	}
+	if !moderator.HasRole(board, role) {
+		respondServiceError(w, r, domain.ErrForbidden)
+		return false
+	}
+	return true
+}
+
+func moderatorFromContext(ctx context.Context) *domain.Moderator {
+	moderator, _ := ctx.Value(moderatorKey).(*domain.Moderator)
+	return moderator
+}
+
+// Banned sessions and addresses can not post, they get the reason and expiry
+// of their ban instead
+
+func rejectBanned(banService services.BanService, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID, _ := getSessionID(r)
+
+		if err := banService.CheckPoster(r.Context(), sessionID, clientIP(r)); err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Threads, replies and their images are counted against the rate limits of
+// the session and the address, over the limit the poster gets 429 with
+// Retry-After
+
+func limitPosting(rateLimiter services.RateLimiter, action domain.RateAction, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID, _ := getSessionID(r)
+		ip := clientIP(r)
+
+		if err := rateLimiter.AllowPost(r.Context(), sessionID, ip, action); err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
+
+		// A form that fails to parse is reported by the handler
+		if err := r.ParseMultipartForm(10 << 20); err == nil {
+			images := len(r.MultipartForm.File["images"])
+			if err := rateLimiter.AllowImages(r.Context(), sessionID, ip, images); err != nil {
+				respondServiceError(w, r, err)
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
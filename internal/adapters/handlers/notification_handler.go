@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type NotificationHandlers struct {
+	notificationService services.NotificationService
+}
+
+func newNotificationHandlers(notificationService services.NotificationService) *NotificationHandlers {
+	return &NotificationHandlers{
+		notificationService: notificationService,
+	}
+}
+
+func (h *NotificationHandlers) getNotifications(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	inbox, err := h.notificationService.Inbox(r.Context(), sessionID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, r, inbox, http.StatusOK)
+}
+
+// Marks the notifications listed in the body read, or all of them without a body
+func (h *NotificationHandlers) markRead(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.MarkNotificationsReadReq
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		respondError(w, r, "Invalid notifications request", http.StatusBadRequest)
+		return
+	}
+
+	marked, err := h.notificationService.MarkRead(r.Context(), sessionID, &req)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, map[string]int{"marked": marked}, http.StatusOK)
+}
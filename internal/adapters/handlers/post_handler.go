@@ -9,12 +9,16 @@ import (
 )
 
 type PostHandlers struct {
-	postService services.PostService
+	postService      services.PostService
+	challengeService services.ChallengeService
+	botTrapService   services.BotTrapService
 }
 
-func newPostHandlers(postService services.PostService) *PostHandlers {
+func newPostHandlers(postService services.PostService, challengeService services.ChallengeService, botTrapService services.BotTrapService) *PostHandlers {
 	return &PostHandlers{
-		postService: postService,
+		postService:      postService,
+		challengeService: challengeService,
+		botTrapService:   botTrapService,
 	}
 }
 
@@ -27,6 +31,15 @@ func (h *PostHandlers) createPostAPI(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if trapBot(h.botTrapService, w, r) {
+		return
+	}
+
+	if err := verifyChallenge(h.challengeService, r); err != nil {
+		respondPostingError(w, r, err)
+		return
+	}
+
 	sessionID, err := getSessionID(r)
 	if err != nil {
 		respondError(w, r, "Failed to get session id from cookies", http.StatusBadRequest)
@@ -39,13 +52,15 @@ func (h *PostHandlers) createPostAPI(w http.ResponseWriter, r *http.Request) {
 	files := r.MultipartForm.File["images"]
 
 	post, err := h.postService.CreatePost(r.Context(), &domain.CreatePostReq{
+		Board:     r.FormValue("board"),
 		Title:     title,
 		Content:   content,
 		ImageData: files,
 		SessionID: sessionID,
+		ClientIP:  clientIP(r),
 	})
 	if err != nil {
-		respondError(w, r, err.Error(), http.StatusInternalServerError)
+		respondPostingError(w, r, err)
 		return
 	}
 
@@ -55,7 +70,8 @@ func (h *PostHandlers) createPostAPI(w http.ResponseWriter, r *http.Request) {
 func (h *PostHandlers) getPostApi(w http.ResponseWriter, r *http.Request) {
 	// Extract the ID from the URL path
 	postID := r.URL.Path[len("/threads/view/"):] // Gets id from url path
-	post, err := h.postService.GetPostByID(r.Context(), postID)
+	viewer, _ := getSessionID(r)
+	post, err := h.postService.GetPostByID(r.Context(), postID, viewer)
 	if err != nil {
 		respondError(w, r, "Internal server error", http.StatusInternalServerError)
 		return
@@ -66,7 +82,8 @@ func (h *PostHandlers) getPostApi(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *PostHandlers) getActivePostsApi(w http.ResponseWriter, r *http.Request) {
-	posts, err := h.postService.GetActivePosts(r.Context())
+	viewer, _ := getSessionID(r)
+	posts, err := h.postService.GetActivePosts(r.Context(), viewer)
 	if err != nil {
 		respondError(w, r, "Internal server error", http.StatusInternalServerError)
 		return
@@ -77,7 +94,8 @@ func (h *PostHandlers) getActivePostsApi(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *PostHandlers) getArchivedPostsApi(w http.ResponseWriter, r *http.Request) {
-	posts, err := h.postService.GetArchivedPosts(r.Context())
+	viewer, _ := getSessionID(r)
+	posts, err := h.postService.GetArchivedPosts(r.Context(), viewer)
 	if err != nil {
 		respondError(w, r, "Internal server error", http.StatusInternalServerError)
 		return
@@ -95,3 +113,20 @@ func (h *PostHandlers) archiveOldPostsApi(w http.ResponseWriter, r *http.Request
 	}
 	return
 }
+
+// Author deletes their own thread
+
+func (h *PostHandlers) deletePostAPI(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.postService.DeletePostByPoster(r.Context(), r.PathValue("id"), sessionID); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
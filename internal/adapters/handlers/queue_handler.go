@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type QueueHandlers struct {
+	queueService services.QueueService
+}
+
+func newQueueHandlers(queueService services.QueueService) *QueueHandlers {
+	return &QueueHandlers{
+		queueService: queueService,
+	}
+}
+
+// Posts and comments waiting for approval
+
+func (h *QueueHandlers) listPending(w http.ResponseWriter, r *http.Request) {
+	items, err := h.queueService.ListPending(r.Context(), moderatorFromContext(r.Context()))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, items, http.StatusOK)
+}
+
+func (h *QueueHandlers) approve(w http.ResponseWriter, r *http.Request) {
+	var req domain.ModerationReq
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		respondError(w, r, "Invalid moderation request", http.StatusBadRequest)
+		return
+	}
+
+	targetType := domain.TargetType(r.PathValue("type"))
+	if err := h.queueService.Approve(r.Context(), moderatorFromContext(r.Context()), targetType, r.PathValue("id"), req.Reason); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *QueueHandlers) reject(w http.ResponseWriter, r *http.Request) {
+	var req domain.ModerationReq
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		respondError(w, r, "Invalid moderation request", http.StatusBadRequest)
+		return
+	}
+
+	targetType := domain.TargetType(r.PathValue("type"))
+	if err := h.queueService.Reject(r.Context(), moderatorFromContext(r.Context()), targetType, r.PathValue("id"), req.Reason); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
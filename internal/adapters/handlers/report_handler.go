@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type ReportHandlers struct {
+	reportService services.ReportService
+}
+
+func newReportHandlers(reportService services.ReportService) *ReportHandlers {
+	return &ReportHandlers{
+		reportService: reportService,
+	}
+}
+
+func (h *ReportHandlers) createReportAPI(w http.ResponseWriter, r *http.Request) {
+	slog.Info("API creating report:")
+
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.CreateReportReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid report request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.reportService.CreateReport(r.Context(), sessionID, &req); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Moderator queue of open reports grouped by target
+
+func (h *ReportHandlers) listReports(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.reportService.ListOpenReports(r.Context(), moderatorFromContext(r.Context()))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, summaries, http.StatusOK)
+}
+
+func (h *ReportHandlers) resolveReports(w http.ResponseWriter, r *http.Request) {
+	var req domain.ResolveReportsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid resolve request", http.StatusBadRequest)
+		return
+	}
+
+	targetType := domain.TargetType(r.PathValue("type"))
+	resolved, err := h.reportService.ResolveReports(r.Context(), moderatorFromContext(r.Context()), targetType, r.PathValue("id"), &req)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, map[string]int{"resolved": resolved}, http.StatusOK)
+}
@@ -3,24 +3,102 @@ package handlers
 import (
 	"net/http"
 
+	"1337b04rd/internal/domain"
 	"1337b04rd/internal/services"
 )
 
-func NewRouter(userService services.UserService, postService services.PostService, commentService services.CommentService) *http.ServeMux {
+func NewRouter(userService services.UserService, postService services.PostService, commentService services.CommentService, moderatorService services.ModeratorService, reportService services.ReportService, banService services.BanService, shadowBanService services.ShadowBanService, auditService services.AuditService, filterService services.WordFilterService, linkService services.LinkPolicyService, imageService services.ImageService, boardService services.BoardService, queueService services.QueueService, threadService services.ThreadService, notificationService services.NotificationService, watchService services.WatchService, rateLimiter services.RateLimiter, challengeService services.ChallengeService, botTrapService services.BotTrapService) *http.ServeMux {
 	mux := http.NewServeMux()
-	userHandler := newUserHandlers(userService)
-	postHandler := newPostHandlers(postService)
-	commentHandler := newCommentHandlers(commentService)
+	userHandler := newUserHandlers(userService, banService)
+	postHandler := newPostHandlers(postService, challengeService, botTrapService)
+	commentHandler := newCommentHandlers(commentService, challengeService, botTrapService, watchService)
+	challengeHandler := newChallengeHandlers(challengeService)
+	botTrapHandler := newBotTrapHandlers(botTrapService)
+	reportHandler := newReportHandlers(reportService)
+	banHandler := newBanHandlers(banService)
+	shadowBanHandler := newShadowBanHandlers(shadowBanService)
+	adminHandler := newAdminHandlers(moderatorService, userService, postService, commentService)
+	auditHandler := newAuditHandlers(auditService)
+	filterHandler := newWordFilterHandlers(filterService)
+	linkHandler := newLinkPolicyHandlers(linkService)
+	imageHandler := newImageHandlers(imageService)
+	boardHandler := newBoardHandlers(boardService)
+	queueHandler := newQueueHandlers(queueService)
+	threadHandler := newThreadHandlers(threadService)
+	notificationHandler := newNotificationHandlers(notificationService)
+	watchHandler := newWatchHandlers(watchService)
 
 	mux.HandleFunc("GET /session/me", userHandler.getSessionMe)
 	mux.HandleFunc("POST /session/name", userHandler.changeUsername)
+	mux.HandleFunc("POST /session/export", userHandler.exportSession)
+	mux.HandleFunc("POST /session/restore", userHandler.restoreSession)
+	mux.HandleFunc("GET /session/notifications", notificationHandler.getNotifications)
+	mux.HandleFunc("POST /session/notifications/read", notificationHandler.markRead)
+	mux.HandleFunc("GET /session/watched", watchHandler.getWatched)
+	mux.HandleFunc("GET /challenge", challengeHandler.getChallenge)
+	mux.HandleFunc("GET /form-token", botTrapHandler.getFormToken)
 	mux.HandleFunc("GET /threads", postHandler.getActivePostsApi)
 	mux.HandleFunc("GET /threads/archive", postHandler.getArchivedPostsApi)
 	mux.HandleFunc("POST /threads/archive-old", postHandler.archiveOldPostsApi)
 	mux.HandleFunc("GET /threads/view/", postHandler.getPostApi)
-	mux.HandleFunc("POST /threads", postHandler.createPostAPI)
-	mux.HandleFunc("POST /threads/comment", commentHandler.createCommentAPI)
+	mux.HandleFunc("POST /threads", rejectBanned(banService, limitPosting(rateLimiter, domain.RateThread, postHandler.createPostAPI)))
+	mux.HandleFunc("POST /threads/comment", rejectBanned(banService, limitPosting(rateLimiter, domain.RateReply, commentHandler.createCommentAPI)))
 	mux.HandleFunc("GET /threads/comment", commentHandler.loadCommentsApi)
+	mux.HandleFunc("DELETE /threads/{id}", postHandler.deletePostAPI)
+	mux.HandleFunc("DELETE /comments/{id}", commentHandler.deleteCommentAPI)
+	mux.HandleFunc("POST /threads/{id}/watch", watchHandler.watch)
+	mux.HandleFunc("DELETE /threads/{id}/watch", watchHandler.unwatch)
+	mux.HandleFunc("POST /reports", reportHandler.createReportAPI)
+
+	// Admin API, everything except login requires an admin session
+	admin := http.NewServeMux()
+	admin.HandleFunc("POST /admin/logout", adminHandler.logout)
+	admin.HandleFunc("GET /admin/me", adminHandler.getMe)
+	admin.HandleFunc("GET /admin/moderators", requireGlobalRole(domain.RoleAdmin, adminHandler.listModerators))
+	admin.HandleFunc("POST /admin/moderators", requireGlobalRole(domain.RoleAdmin, adminHandler.createModerator))
+	admin.HandleFunc("PUT /admin/moderators/{id}/roles", requireGlobalRole(domain.RoleAdmin, adminHandler.setModeratorRoles))
+	admin.HandleFunc("POST /admin/threads/{id}/delete", adminHandler.deletePost)
+	admin.HandleFunc("POST /admin/comments/{id}/delete", adminHandler.deleteComment)
+	admin.HandleFunc("POST /admin/threads/{id}/unarchive", adminHandler.unarchivePost)
+	admin.HandleFunc("POST /admin/sessions/{id}/rename", adminHandler.renameSession)
+	admin.HandleFunc("POST /admin/threads/{id}/move", threadHandler.moveThread)
+	admin.HandleFunc("POST /admin/threads/{id}/merge", threadHandler.mergeThreads)
+	admin.HandleFunc("POST /admin/comments/{id}/split", threadHandler.splitThread)
+	admin.HandleFunc("PUT /admin/threads/{id}/sticky", adminHandler.setThreadFlag(domain.FlagSticky, true))
+	admin.HandleFunc("DELETE /admin/threads/{id}/sticky", adminHandler.setThreadFlag(domain.FlagSticky, false))
+	admin.HandleFunc("PUT /admin/threads/{id}/lock", adminHandler.setThreadFlag(domain.FlagLocked, true))
+	admin.HandleFunc("DELETE /admin/threads/{id}/lock", adminHandler.setThreadFlag(domain.FlagLocked, false))
+	admin.HandleFunc("PUT /admin/threads/{id}/cyclical", adminHandler.setThreadFlag(domain.FlagCyclical, true))
+	admin.HandleFunc("DELETE /admin/threads/{id}/cyclical", adminHandler.setThreadFlag(domain.FlagCyclical, false))
+	admin.HandleFunc("GET /admin/reports", reportHandler.listReports)
+	admin.HandleFunc("POST /admin/reports/{type}/{id}/resolve", reportHandler.resolveReports)
+	admin.HandleFunc("GET /admin/bans", banHandler.listBans)
+	admin.HandleFunc("POST /admin/bans", banHandler.createBan)
+	admin.HandleFunc("DELETE /admin/bans/{id}", banHandler.liftBan)
+	admin.HandleFunc("GET /admin/shadow-bans", shadowBanHandler.listShadowBanned)
+	admin.HandleFunc("POST /admin/shadow-bans/purge", shadowBanHandler.purge)
+	admin.HandleFunc("GET /admin/shadow-bans/{session}", shadowBanHandler.getShadowContent)
+	admin.HandleFunc("PUT /admin/shadow-bans/{session}", shadowBanHandler.shadowBan)
+	admin.HandleFunc("DELETE /admin/shadow-bans/{session}", shadowBanHandler.liftShadowBan)
+	admin.HandleFunc("GET /admin/filters", filterHandler.listFilters)
+	admin.HandleFunc("POST /admin/filters", filterHandler.createFilter)
+	admin.HandleFunc("DELETE /admin/filters/{id}", filterHandler.deleteFilter)
+	admin.HandleFunc("GET /admin/links", linkHandler.listRules)
+	admin.HandleFunc("POST /admin/links", linkHandler.createRule)
+	admin.HandleFunc("DELETE /admin/links/{id}", linkHandler.deleteRule)
+	admin.HandleFunc("GET /admin/images/similar", imageHandler.findSimilar)
+	admin.HandleFunc("GET /admin/queue", queueHandler.listPending)
+	admin.HandleFunc("POST /admin/queue/{type}/{id}/approve", queueHandler.approve)
+	admin.HandleFunc("POST /admin/queue/{type}/{id}/reject", queueHandler.reject)
+	admin.HandleFunc("GET /admin/boards/{board}/settings", boardHandler.getSettings)
+	admin.HandleFunc("PUT /admin/boards/{board}/settings", boardHandler.updateSettings)
+
+	admin.HandleFunc("GET /admin/audit", requireGlobalRole(domain.RoleModerator, auditHandler.listAudit))
+	admin.HandleFunc("GET /admin/audit/export", requireGlobalRole(domain.RoleModerator, auditHandler.exportAudit))
+	admin.HandleFunc("GET /admin/bots", requireGlobalRole(domain.RoleModerator, botTrapHandler.getStats))
+
+	mux.HandleFunc("POST /admin/login", adminHandler.login)
+	mux.Handle("/admin/", requireModerator(moderatorService, admin))
 
 	return mux
 }
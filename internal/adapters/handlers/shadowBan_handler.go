@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type ShadowBanHandlers struct {
+	shadowBanService services.ShadowBanService
+}
+
+func newShadowBanHandlers(shadowBanService services.ShadowBanService) *ShadowBanHandlers {
+	return &ShadowBanHandlers{
+		shadowBanService: shadowBanService,
+	}
+}
+
+func (h *ShadowBanHandlers) listShadowBanned(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.shadowBanService.ListShadowBanned(r.Context(), moderatorFromContext(r.Context()))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, sessions, http.StatusOK)
+}
+
+func (h *ShadowBanHandlers) getShadowContent(w http.ResponseWriter, r *http.Request) {
+	content, err := h.shadowBanService.GetShadowContent(r.Context(), moderatorFromContext(r.Context()), r.PathValue("session"))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, content, http.StatusOK)
+}
+
+func (h *ShadowBanHandlers) shadowBan(w http.ResponseWriter, r *http.Request) {
+	h.setShadowBan(w, r, true)
+}
+
+func (h *ShadowBanHandlers) liftShadowBan(w http.ResponseWriter, r *http.Request) {
+	h.setShadowBan(w, r, false)
+}
+
+func (h *ShadowBanHandlers) setShadowBan(w http.ResponseWriter, r *http.Request, banned bool) {
+	var req domain.ShadowBanReq
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		respondError(w, r, "Invalid shadow ban request", http.StatusBadRequest)
+		return
+	}
+
+	err := h.shadowBanService.SetShadowBan(r.Context(), moderatorFromContext(r.Context()), r.PathValue("session"), banned, req.Reason)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ShadowBanHandlers) purge(w http.ResponseWriter, r *http.Request) {
+	var req domain.PurgeShadowBannedReq
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		respondError(w, r, "Invalid purge request", http.StatusBadRequest)
+		return
+	}
+
+	purged, err := h.shadowBanService.Purge(r.Context(), moderatorFromContext(r.Context()), &req)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, map[string]int{"purged": purged}, http.StatusOK)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type ThreadHandlers struct {
+	threadService services.ThreadService
+}
+
+func newThreadHandlers(threadService services.ThreadService) *ThreadHandlers {
+	return &ThreadHandlers{
+		threadService: threadService,
+	}
+}
+
+func (h *ThreadHandlers) moveThread(w http.ResponseWriter, r *http.Request) {
+	var req domain.MoveThreadReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid move request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.threadService.MoveThread(r.Context(), r.PathValue("id"), moderatorFromContext(r.Context()), &req); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ThreadHandlers) mergeThreads(w http.ResponseWriter, r *http.Request) {
+	var req domain.MergeThreadReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid merge request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.threadService.MergeThreads(r.Context(), r.PathValue("id"), moderatorFromContext(r.Context()), &req); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ThreadHandlers) splitThread(w http.ResponseWriter, r *http.Request) {
+	var req domain.SplitThreadReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid split request", http.StatusBadRequest)
+		return
+	}
+
+	postID, err := h.threadService.SplitThread(r.Context(), r.PathValue("id"), moderatorFromContext(r.Context()), &req)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, map[string]string{"post_id": postID}, http.StatusCreated)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 
@@ -11,14 +12,24 @@ import (
 
 type UserHandlers struct {
 	userService services.UserService
+	banService  services.BanService
 }
 
-func newUserHandlers(userService services.UserService) *UserHandlers {
+func newUserHandlers(userService services.UserService, banService services.BanService) *UserHandlers {
 	return &UserHandlers{
 		userService: userService,
+		banService:  banService,
 	}
 }
 
+// The session as returned by /session/me, banned users also see why and
+// until when
+
+type sessionResponse struct {
+	*domain.User
+	Ban *domain.BanNotice `json:",omitempty"`
+}
+
 // Get the current user session, if it does not exists creating and retrieving the newly created one
 
 func (u *UserHandlers) getSessionMe(w http.ResponseWriter, r *http.Request) {
@@ -49,11 +60,24 @@ func (u *UserHandlers) getSessionMe(w http.ResponseWriter, r *http.Request) {
 			respondError(w, r, "Failed to retrieve the user session", http.StatusNotFound)
 			return
 		}
-		respondJSON(w, r, user, http.StatusOK)
+		u.respondSession(w, r, user)
 		return
 	}
 	slog.Info("Found user by session id")
-	respondJSON(w, r, user, http.StatusOK)
+	u.respondSession(w, r, user)
+}
+
+func (u *UserHandlers) respondSession(w http.ResponseWriter, r *http.Request, user *domain.User) {
+	response := sessionResponse{User: user}
+
+	ban, err := u.banService.ActiveBan(r.Context(), user.SessionID, clientIP(r))
+	if err != nil {
+		slog.Error("Failed to look up ban of session:", "error", err)
+	} else if ban != nil {
+		response.Ban = ban.Notice()
+	}
+
+	respondJSON(w, r, response, http.StatusOK)
 }
 
 // Create new user and retrieve its ID from the database
@@ -71,6 +95,10 @@ func (u *UserHandlers) changeUsername(w http.ResponseWriter, r *http.Request) {
 	slog.Info("Change username handler:")
 
 	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
 
 	var req domain.NameRequest
 	err = json.NewDecoder(r.Body).Decode(&req)
@@ -84,5 +112,71 @@ func (u *UserHandlers) changeUsername(w http.ResponseWriter, r *http.Request) {
 	err = u.userService.ChangeUsername(r.Context(), sessionID, newUsername)
 	if err != nil {
 		slog.Error("Error when changing username:", "error", err)
+		respondServiceError(w, r, err)
+		return
+	}
+
+	user, err := u.userService.FindUserByID(r.Context(), sessionID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
 	}
+
+	respondJSON(w, r, user, http.StatusOK)
+}
+
+// Issue a one-time recovery phrase for the current session
+
+func (u *UserHandlers) exportSession(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Export session handler:")
+
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	phrase, err := u.userService.ExportSession(r.Context(), sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			respondError(w, r, "Session not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("Error when exporting session:", "error", err)
+		respondError(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, r, map[string]string{"recovery_phrase": phrase}, http.StatusCreated)
+}
+
+// Bind the cookie of this browser to the session the recovery phrase was issued for
+
+func (u *UserHandlers) restoreSession(w http.ResponseWriter, r *http.Request) {
+	slog.Info("Restore session handler:")
+
+	var req domain.RestoreRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil || req.RecoveryPhrase == "" {
+		respondError(w, r, "Recovery phrase is required", http.StatusBadRequest)
+		return
+	}
+
+	user, err := u.userService.RestoreSession(r.Context(), req.RecoveryPhrase, clientIP(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTooManyAttempts):
+			respondError(w, r, err.Error(), http.StatusTooManyRequests)
+		case errors.Is(err, domain.ErrInvalidRecoveryPhrase), errors.Is(err, domain.ErrNotFound):
+			respondError(w, r, domain.ErrInvalidRecoveryPhrase.Error(), http.StatusUnauthorized)
+		default:
+			slog.Error("Error when restoring session:", "error", err)
+			respondError(w, r, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	setSessionID(w, user.SessionID)
+	slog.Info("Restored session")
+	respondJSON(w, r, user, http.StatusOK)
 }
@@ -2,12 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
+	"strings"
 	"time"
+
+	"1337b04rd/internal/domain"
 )
 
+var errInvalidSessionCookie = errors.New("session cookie is not a session ID")
+
 // JSON Response Helpers
 
 func respondJSON(w http.ResponseWriter, r *http.Request, data interface{}, status int) {
@@ -21,7 +29,18 @@ func respondError(w http.ResponseWriter, r *http.Request, message string, status
 	respondJSON(w, r, map[string]string{"error": message}, status)
 }
 
-// Retrieve session ID from cookies
+// Decode JSON body into dest, an empty body leaves dest untouched
+
+func decodeOptionalJSON(r *http.Request, dest interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(dest)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// Retrieve session ID from cookies, values that are not a UUID are treated
+// as a missing cookie
 
 func getSessionID(r *http.Request) (string, error) {
 	cookie, err := r.Cookie("session_id")
@@ -29,7 +48,10 @@ func getSessionID(r *http.Request) (string, error) {
 		slog.Error("Failed to get session_id from cookies", "error", err)
 		return "", err
 	}
-	return cookie.Value, nil
+	if !domain.IsUUID(cookie.Value) {
+		return "", errInvalidSessionCookie
+	}
+	return strings.ToLower(cookie.Value), nil
 }
 
 // Set session ID to the cookies
@@ -46,3 +68,51 @@ func setSessionID(w http.ResponseWriter, sessionID string) {
 	}
 	http.SetCookie(w, cookie)
 }
+
+// Admin session cookie, only sent to the admin API
+
+const adminCookieName = "admin_session"
+
+func getAdminToken(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(adminCookieName)
+	if err != nil {
+		return "", err
+	}
+	return cookie.Value, nil
+}
+
+func setAdminToken(w http.ResponseWriter, token string) {
+	cookie := &http.Cookie{
+		Name:     adminCookieName,
+		Value:    token,
+		Path:     "/admin",
+		Expires:  time.Now().Add(domain.ModeratorSessionTTL),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	}
+	http.SetCookie(w, cookie)
+}
+
+func clearAdminToken(w http.ResponseWriter) {
+	cookie := &http.Cookie{
+		Name:     adminCookieName,
+		Value:    "",
+		Path:     "/admin",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	}
+	http.SetCookie(w, cookie)
+}
+
+// Client address of the request without the port
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
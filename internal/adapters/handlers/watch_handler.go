@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"1337b04rd/internal/services"
+)
+
+type WatchHandlers struct {
+	watchService services.WatchService
+}
+
+func newWatchHandlers(watchService services.WatchService) *WatchHandlers {
+	return &WatchHandlers{
+		watchService: watchService,
+	}
+}
+
+func (h *WatchHandlers) watch(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.watchService.Watch(r.Context(), sessionID, r.PathValue("id")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WatchHandlers) unwatch(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.watchService.Unwatch(r.Context(), sessionID, r.PathValue("id")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WatchHandlers) getWatched(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := getSessionID(r)
+	if err != nil {
+		respondError(w, r, "Failed to get session id from cookies", http.StatusUnauthorized)
+		return
+	}
+
+	threads, err := h.watchService.Watched(r.Context(), sessionID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, r, threads, http.StatusOK)
+}
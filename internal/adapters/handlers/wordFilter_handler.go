@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+type WordFilterHandlers struct {
+	filterService services.WordFilterService
+}
+
+func newWordFilterHandlers(filterService services.WordFilterService) *WordFilterHandlers {
+	return &WordFilterHandlers{
+		filterService: filterService,
+	}
+}
+
+func (h *WordFilterHandlers) listFilters(w http.ResponseWriter, r *http.Request) {
+	filters, err := h.filterService.ListFilters(r.Context(), moderatorFromContext(r.Context()))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, filters, http.StatusOK)
+}
+
+func (h *WordFilterHandlers) createFilter(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateWordFilterReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, "Invalid word filter request", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := h.filterService.CreateFilter(r.Context(), moderatorFromContext(r.Context()), &req)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, filter, http.StatusCreated)
+}
+
+func (h *WordFilterHandlers) deleteFilter(w http.ResponseWriter, r *http.Request) {
+	if err := h.filterService.DeleteFilter(r.Context(), moderatorFromContext(r.Context()), r.PathValue("id")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,109 @@
+package identicon
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"1337b04rd/internal/domain"
+)
+
+// Identicon generates avatars and names locally, without any network calls.
+// The same id always gives the same picture and name.
+type Identicon struct {
+	cellSize int
+}
+
+var _ domain.UserOutlookAPI = (*Identicon)(nil)
+
+// The picture is a 5x5 grid mirrored around the vertical axis
+const gridSize = 5
+
+func NewIdenticon() *Identicon {
+	return &Identicon{
+		cellSize: 16,
+	}
+}
+
+func (i *Identicon) GenerateAvatarAndName(id int) (*domain.UserOutlook, error) {
+	seed := make([]byte, 8)
+	binary.BigEndian.PutUint64(seed, uint64(id))
+	hash := sha256.Sum256(seed)
+
+	avatar, err := i.render(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserOutlook{
+		AvatarURL: "data:image/png;base64," + base64.StdEncoding.EncodeToString(avatar),
+		Name:      generateName(hash),
+	}, nil
+}
+
+// Draw the grid, the first 15 bytes of the hash decide which cells are filled
+// and the last three bytes give the color
+
+func (i *Identicon) render(hash [32]byte) ([]byte, error) {
+	side := gridSize * i.cellSize
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{
+		color.RGBA{R: 240, G: 240, B: 240, A: 255},
+		color.RGBA{R: hash[29], G: hash[30], B: hash[31], A: 255},
+	})
+
+	for row := 0; row < gridSize; row++ {
+		for col := 0; col < (gridSize+1)/2; col++ {
+			if hash[row*3+col]%2 == 1 {
+				continue
+			}
+			i.fillCell(img, row, col)
+			i.fillCell(img, row, gridSize-1-col)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (i *Identicon) fillCell(img *image.Paletted, row, col int) {
+	for y := row * i.cellSize; y < (row+1)*i.cellSize; y++ {
+		for x := col * i.cellSize; x < (col+1)*i.cellSize; x++ {
+			img.SetColorIndex(x, y, 1)
+		}
+	}
+}
+
+func generateName(hash [32]byte) string {
+	adjective := adjectives[int(hash[16])%len(adjectives)]
+	noun := nouns[int(hash[17])%len(nouns)]
+	return capitalize(adjective) + " " + capitalize(noun)
+}
+
+func capitalize(word string) string {
+	return strings.ToUpper(word[:1]) + word[1:]
+}
+
+var adjectives = []string{
+	"ancient", "brave", "bright", "calm", "clever", "cosmic", "crazy", "curious",
+	"dizzy", "eager", "electric", "fancy", "fierce", "fluffy", "gentle", "giant",
+	"glowing", "grumpy", "happy", "hidden", "hungry", "icy", "jolly", "lazy",
+	"little", "lucky", "mighty", "misty", "noble", "quiet", "rapid", "rusty",
+	"shiny", "silent", "sleepy", "sneaky", "solar", "spicy", "swift", "tiny",
+	"wild", "wise", "witty", "zany",
+}
+
+var nouns = []string{
+	"badger", "beetle", "comet", "cricket", "dragon", "falcon", "ferret", "gecko",
+	"goblin", "hamster", "heron", "koala", "lemur", "lizard", "meteor", "moose",
+	"narwhal", "otter", "owl", "panda", "parrot", "penguin", "pickle", "pigeon",
+	"planet", "raccoon", "robot", "rocket", "salmon", "squid", "toaster", "turtle",
+	"walrus", "wizard", "wombat", "yeti",
+}
@@ -0,0 +1,66 @@
+package identicon
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestIdenticon_GenerateAvatarAndName(t *testing.T) {
+	gen := NewIdenticon()
+
+	first, err := gen.GenerateAvatarAndName(42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := gen.GenerateAvatarAndName(42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if *first != *second {
+		t.Errorf("expected the same outlook for the same id, got %+v and %+v", first, second)
+	}
+
+	if len(strings.Fields(first.Name)) != 2 {
+		t.Errorf("expected adjective-noun name, got %q", first.Name)
+	}
+
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(first.AvatarURL, prefix) {
+		t.Fatalf("expected PNG data URL, got %q", first.AvatarURL[:30])
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(first.AvatarURL, prefix))
+	if err != nil {
+		t.Fatalf("avatar is not valid base64: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("avatar is not a valid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 80 || img.Bounds().Dy() != 80 {
+		t.Errorf("expected 80x80 image, got %v", img.Bounds())
+	}
+}
+
+func TestIdenticon_DifferentIDs(t *testing.T) {
+	gen := NewIdenticon()
+
+	avatars := make(map[string]bool)
+	for id := 1; id <= 20; id++ {
+		outlook, err := gen.GenerateAvatarAndName(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		avatars[outlook.AvatarURL] = true
+	}
+
+	if len(avatars) < 19 {
+		t.Errorf("expected distinct avatars for distinct ids, got %d of 20", len(avatars))
+	}
+}
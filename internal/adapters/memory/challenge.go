@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// ChallengeStore remembers used challenges in the memory of one instance
+type ChallengeStore struct {
+	mu         sync.Mutex
+	used       map[string]time.Time
+	lastSweep  time.Time
+	sweepEvery time.Duration
+	now        func() time.Time
+}
+
+var _ domain.ChallengeStore = (*ChallengeStore)(nil)
+
+func NewChallengeStore() *ChallengeStore {
+	return &ChallengeStore{
+		used:       make(map[string]time.Time),
+		sweepEvery: time.Minute,
+		now:        time.Now,
+	}
+}
+
+func (s *ChallengeStore) Use(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if _, ok := s.used[nonce]; ok {
+		return false, nil
+	}
+	s.used[nonce] = expiresAt
+	return true, nil
+}
+
+// Expired challenges are rejected anyway, they do not have to be remembered
+func (s *ChallengeStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepEvery {
+		return
+	}
+	s.lastSweep = now
+
+	for nonce, expiresAt := range s.used {
+		if now.After(expiresAt) {
+			delete(s.used, nonce)
+		}
+	}
+}
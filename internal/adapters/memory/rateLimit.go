@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// RateLimitStore keeps token buckets in the memory of one instance
+type RateLimitStore struct {
+	mu         sync.Mutex
+	buckets    map[string]domain.RateBucket
+	lastSweep  time.Time
+	sweepEvery time.Duration
+	now        func() time.Time
+}
+
+var _ domain.RateLimitStore = (*RateLimitStore)(nil)
+
+func NewRateLimitStore() *RateLimitStore {
+	return &RateLimitStore{
+		buckets:    make(map[string]domain.RateBucket),
+		sweepEvery: time.Minute,
+		now:        time.Now,
+	}
+}
+
+// Buckets older than this are full again under any sane limit
+const bucketMaxAge = 24 * time.Hour
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit, n int) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	bucket, retryAfter := limit.Take(s.buckets[key], now, n)
+	if retryAfter > 0 {
+		return retryAfter, nil
+	}
+
+	s.buckets[key] = bucket
+	return 0, nil
+}
+
+func (s *RateLimitStore) Give(ctx context.Context, key string, limit domain.RateLimit, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if bucket, ok := s.buckets[key]; ok {
+		s.buckets[key] = limit.Give(bucket, n)
+	}
+	return nil
+}
+
+// Drop old buckets so the map does not grow forever
+func (s *RateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepEvery {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.UpdatedAt) > bucketMaxAge {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+func newTestStore(now *time.Time) *RateLimitStore {
+	store := NewRateLimitStore()
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestTake_BurstAndRefill(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestStore(&now)
+	limit := domain.RateLimit{Burst: 3, Every: 10 * time.Second}
+
+	for i := 0; i < 3; i++ {
+		if wait, _ := store.Take(context.Background(), "k", limit, 1); wait != 0 {
+			t.Fatalf("take %d: expected to pass, got wait %v", i, wait)
+		}
+	}
+
+	wait, _ := store.Take(context.Background(), "k", limit, 1)
+	if wait != 10*time.Second {
+		t.Fatalf("expected to wait 10s for the next token, got %v", wait)
+	}
+
+	now = now.Add(4 * time.Second)
+	if wait, _ := store.Take(context.Background(), "k", limit, 1); wait != 6*time.Second {
+		t.Fatalf("expected to wait the rest 6s, got %v", wait)
+	}
+
+	now = now.Add(6 * time.Second)
+	if wait, _ := store.Take(context.Background(), "k", limit, 1); wait != 0 {
+		t.Fatalf("expected a refilled token, got wait %v", wait)
+	}
+
+	// Other keys have their own buckets
+	if wait, _ := store.Take(context.Background(), "other", limit, 3); wait != 0 {
+		t.Fatalf("expected a full bucket for another key, got wait %v", wait)
+	}
+}
+
+func TestTake_NeverAboveBurst(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestStore(&now)
+	limit := domain.RateLimit{Burst: 2, Every: time.Second}
+
+	store.Take(context.Background(), "k", limit, 2)
+	now = now.Add(time.Hour)
+
+	if wait, _ := store.Take(context.Background(), "k", limit, 3); wait != time.Second {
+		t.Fatalf("expected a full bucket of 2 to be one token short, got wait %v", wait)
+	}
+	if wait, _ := store.Take(context.Background(), "k", limit, 2); wait != 0 {
+		t.Fatalf("expected a failed take to leave the tokens, got wait %v", wait)
+	}
+}
+
+func TestSweep_DropsOldBuckets(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestStore(&now)
+	limit := domain.RateLimit{Burst: 1, Every: time.Second}
+
+	store.Take(context.Background(), "old", limit, 1)
+	now = now.Add(bucketMaxAge + time.Minute)
+	store.Take(context.Background(), "new", limit, 1)
+
+	if _, ok := store.buckets["old"]; ok {
+		t.Error("expected the old bucket to be dropped")
+	}
+	if _, ok := store.buckets["new"]; !ok {
+		t.Error("expected the new bucket to stay")
+	}
+}
@@ -0,0 +1,44 @@
+package outlookChain
+
+import (
+	"errors"
+	"log/slog"
+
+	"1337b04rd/internal/domain"
+)
+
+// OutlookChain asks the providers in order and returns the first avatar and
+// name it gets. Put an offline generator last to never fail.
+type OutlookChain struct {
+	providers []domain.UserOutlookAPI
+}
+
+var _ domain.UserOutlookAPI = (*OutlookChain)(nil)
+
+func NewOutlookChain(providers ...domain.UserOutlookAPI) *OutlookChain {
+	return &OutlookChain{
+		providers: providers,
+	}
+}
+
+func (c *OutlookChain) GenerateAvatarAndName(id int) (*domain.UserOutlook, error) {
+	errs := make([]error, 0, len(c.providers))
+
+	for i, provider := range c.providers {
+		userOutlook, err := provider.GenerateAvatarAndName(id)
+		if err == nil && (userOutlook == nil || userOutlook.Name == "" || userOutlook.AvatarURL == "") {
+			err = errors.New("provider returned empty avatar or name")
+		}
+		if err != nil {
+			slog.Warn("Avatar provider failed, trying the next one", "provider", i, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		return userOutlook, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, errors.New("no avatar providers configured")
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,63 @@
+package outlookChain
+
+import (
+	"errors"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+type mockProvider struct {
+	outlook *domain.UserOutlook
+	err     error
+	calls   int
+}
+
+func (m *mockProvider) GenerateAvatarAndName(id int) (*domain.UserOutlook, error) {
+	m.calls++
+	return m.outlook, m.err
+}
+
+func TestOutlookChain_FirstProviderWins(t *testing.T) {
+	first := &mockProvider{outlook: &domain.UserOutlook{Name: "Rick", AvatarURL: "rick.png"}}
+	second := &mockProvider{outlook: &domain.UserOutlook{Name: "Morty", AvatarURL: "morty.png"}}
+
+	outlook, err := NewOutlookChain(first, second).GenerateAvatarAndName(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outlook.Name != "Rick" {
+		t.Errorf("expected Rick, got %s", outlook.Name)
+	}
+	if second.calls != 0 {
+		t.Errorf("expected second provider not to be called")
+	}
+}
+
+func TestOutlookChain_FallsBack(t *testing.T) {
+	down := &mockProvider{err: errors.New("api is down")}
+	empty := &mockProvider{outlook: &domain.UserOutlook{}}
+	offline := &mockProvider{outlook: &domain.UserOutlook{Name: "Sleepy Otter", AvatarURL: "data:"}}
+
+	outlook, err := NewOutlookChain(down, empty, offline).GenerateAvatarAndName(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outlook.Name != "Sleepy Otter" {
+		t.Errorf("expected fallback name, got %s", outlook.Name)
+	}
+}
+
+func TestOutlookChain_AllFail(t *testing.T) {
+	down := &mockProvider{err: errors.New("api is down")}
+
+	_, err := NewOutlookChain(down).GenerateAvatarAndName(1)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	_, err = NewOutlookChain().GenerateAvatarAndName(1)
+	if err == nil {
+		t.Fatal("expected error for empty chain, got nil")
+	}
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"1337b04rd/internal/domain"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+var _ domain.AuditRepository = (*AuditRepository)(nil)
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (r *AuditRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (moderator_id, moderator, action, target_type, target_id, reason, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING entry_id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		entry.ModeratorID,
+		entry.Moderator,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Reason,
+		jsonbValue(entry.Before),
+		jsonbValue(entry.After),
+	).Scan(
+		&entry.ID,
+		&entry.CreatedAt,
+	)
+}
+
+func (r *AuditRepository) Find(ctx context.Context, filter *domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	var entries []*domain.AuditEntry
+	err := r.query(ctx, filter, true, func(entry *domain.AuditEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// Stream every matching entry, oldest first, without loading them all
+
+func (r *AuditRepository) Each(ctx context.Context, filter *domain.AuditFilter, fn func(*domain.AuditEntry) error) error {
+	return r.query(ctx, filter, false, fn)
+}
+
+func (r *AuditRepository) query(ctx context.Context, filter *domain.AuditFilter, page bool, fn func(*domain.AuditEntry) error) error {
+	var conditions []string
+	var args []any
+
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ModeratorID != "" {
+		where("moderator_id::text = $%d", filter.ModeratorID)
+	}
+	if filter.Action != "" {
+		// "ban" matches ban.create and ban.lift, compared as a plain prefix
+		// so _ and % in the filter are no wildcards
+		where("(action = $%[1]d OR left(action, length($%[1]d) + 1) = $%[1]d || '.')", filter.Action)
+	}
+	if filter.TargetType != "" {
+		where("target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		where("target_id = $%d", filter.TargetID)
+	}
+	if filter.Since != nil {
+		where("created_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		where("created_at < $%d", *filter.Until)
+	}
+
+	query := `
+		SELECT entry_id, COALESCE(moderator_id::text, ''), moderator, action,
+			target_type, target_id, reason, before, after, created_at
+		FROM audit_log
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if page {
+		query += " ORDER BY entry_id DESC"
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	} else {
+		query += " ORDER BY entry_id ASC"
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var before, after []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ModeratorID,
+			&entry.Moderator,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&entry.Reason,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		entry.Before = before
+		entry.After = after
+
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Empty snapshots are stored as NULL
+func jsonbValue(raw []byte) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
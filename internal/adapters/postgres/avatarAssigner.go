@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"1337b04rd/internal/domain"
+)
+
+// SequenceAssigner takes character IDs from a cycling Postgres sequence, so
+// every instance of the server shares one counter.
+type SequenceAssigner struct {
+	db *sql.DB
+}
+
+var _ domain.AvatarAssigner = (*SequenceAssigner)(nil)
+
+func NewSequenceAssigner(db *sql.DB) *SequenceAssigner {
+	return &SequenceAssigner{
+		db: db,
+	}
+}
+
+func (a *SequenceAssigner) NextCharacterID(ctx context.Context) (int, error) {
+	var id int
+	err := a.db.QueryRowContext(ctx, "SELECT nextval('avatar_character_seq')").Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
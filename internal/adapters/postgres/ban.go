@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+)
+
+type BanRepository struct {
+	db *sql.DB
+}
+
+var _ domain.BanRepository = (*BanRepository)(nil)
+
+func NewBanRepository(db *sql.DB) *BanRepository {
+	return &BanRepository{
+		db: db,
+	}
+}
+
+// Every ban kind has its own column so sessions and ranges can be matched
+// with their native types
+const banColumns = `
+	ban_id, kind,
+	COALESCE(session_id::text, ip_range::text, image_sha256, image_phash),
+	reason, expires_at, COALESCE(created_by::text, ''), created_at
+`
+
+func (r *BanRepository) Create(ctx context.Context, ban *domain.Ban) (string, error) {
+	query, args, err := insertBanQuery(ban)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&ban.ID, &ban.CreatedAt); err != nil {
+		return "", err
+	}
+
+	return ban.ID, nil
+}
+
+// The insert of a ban and its arguments, reports resolved with a ban run it
+// in their own transaction
+
+func insertBanQuery(ban *domain.Ban) (string, []any, error) {
+	var sessionID, ipRange, imageHash, imagePHash sql.NullString
+	switch ban.Kind {
+	case domain.BanSession:
+		sessionID = sql.NullString{String: ban.Value, Valid: true}
+	case domain.BanIP:
+		ipRange = sql.NullString{String: ban.Value, Valid: true}
+	case domain.BanImage:
+		imageHash = sql.NullString{String: ban.Value, Valid: true}
+	case domain.BanPHash:
+		imagePHash = sql.NullString{String: ban.Value, Valid: true}
+	default:
+		return "", nil, domain.ErrInvalidBanKind
+	}
+
+	query := `
+		INSERT INTO bans (kind, session_id, ip_range, image_sha256, image_phash, reason, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ban_id, created_at
+	`
+
+	args := []any{
+		ban.Kind,
+		sessionID,
+		ipRange,
+		imageHash,
+		imagePHash,
+		ban.Reason,
+		sqlNullTime(ban.ExpiresAt),
+		ban.CreatedBy,
+	}
+	return query, args, nil
+}
+
+func (r *BanRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM bans WHERE ban_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *BanRepository) FindByID(ctx context.Context, id string) (*domain.Ban, error) {
+	query := `SELECT ` + banColumns + ` FROM bans WHERE ban_id = $1`
+	return r.findOne(ctx, query, id)
+}
+
+func (r *BanRepository) ListActive(ctx context.Context) ([]*domain.Ban, error) {
+	query := `
+		SELECT ` + banColumns + `
+		FROM bans
+		WHERE expires_at IS NULL OR expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bans []*domain.Ban
+	for rows.Next() {
+		ban, err := scanBan(rows)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+
+	return bans, rows.Err()
+}
+
+// Active ban of the session or of any range containing the address, the one
+// lasting longest wins
+
+func (r *BanRepository) FindActive(ctx context.Context, sessionID string, ip string) (*domain.Ban, error) {
+	query := `
+		SELECT ` + banColumns + `
+		FROM bans
+		WHERE (expires_at IS NULL OR expires_at > NOW())
+		AND (
+			(kind = 'session' AND session_id::text = $1)
+			OR (kind = 'ip' AND ip_range >>= $2::inet)
+		)
+		ORDER BY expires_at DESC NULLS FIRST
+		LIMIT 1
+	`
+
+	return r.findOne(ctx, query, sqlNullString(sessionID), sqlNullString(ip))
+}
+
+func (r *BanRepository) FindImageBan(ctx context.Context, sha256 string) (*domain.Ban, error) {
+	query := `
+		SELECT ` + banColumns + `
+		FROM bans
+		WHERE kind = 'image' AND image_sha256 = $1
+		AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY expires_at DESC NULLS FIRST
+		LIMIT 1
+	`
+
+	return r.findOne(ctx, query, sha256)
+}
+
+func (r *BanRepository) FindPHashBans(ctx context.Context) ([]*domain.Ban, error) {
+	query := `
+		SELECT ` + banColumns + `
+		FROM bans
+		WHERE kind = 'phash'
+		AND (expires_at IS NULL OR expires_at > NOW())
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bans []*domain.Ban
+	for rows.Next() {
+		ban, err := scanBan(rows)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+
+	return bans, rows.Err()
+}
+
+func (r *BanRepository) findOne(ctx context.Context, query string, args ...any) (*domain.Ban, error) {
+	ban, err := scanBan(r.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return ban, err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBan(row rowScanner) (*domain.Ban, error) {
+	var ban domain.Ban
+	var expiresAt sql.NullTime
+
+	err := row.Scan(
+		&ban.ID,
+		&ban.Kind,
+		&ban.Value,
+		&ban.Reason,
+		&expiresAt,
+		&ban.CreatedBy,
+		&ban.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		ban.ExpiresAt = &expiresAt.Time
+	}
+	return &ban, nil
+}
+
+func sqlNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
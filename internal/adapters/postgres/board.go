@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+)
+
+type BoardSettingsRepository struct {
+	db *sql.DB
+}
+
+var _ domain.BoardSettingsRepository = (*BoardSettingsRepository)(nil)
+
+func NewBoardSettingsRepository(db *sql.DB) *BoardSettingsRepository {
+	return &BoardSettingsRepository{
+		db: db,
+	}
+}
+
+func (r *BoardSettingsRepository) Find(ctx context.Context, board string) (*domain.BoardSettings, error) {
+	// The board row wins over the defaults of every board
+	query := `
+		SELECT premod_minutes, duplicate_minutes, robot9000, max_links, link_min_session_minutes, updated_at
+		FROM board_settings
+		WHERE board = $1 OR board = '*'
+		ORDER BY board = '*'
+		LIMIT 1
+	`
+
+	settings := domain.DefaultBoardSettings(board)
+	err := r.db.QueryRowContext(ctx, query, board).Scan(
+		&settings.PremodMinutes,
+		&settings.DuplicateMinutes,
+		&settings.Robot9000,
+		&settings.MaxLinks,
+		&settings.LinkMinSessionMinutes,
+		&settings.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (r *BoardSettingsRepository) Save(ctx context.Context, settings *domain.BoardSettings) error {
+	query := `
+		INSERT INTO board_settings (board, premod_minutes, duplicate_minutes, robot9000, max_links, link_min_session_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (board) DO UPDATE
+		SET premod_minutes = EXCLUDED.premod_minutes,
+			duplicate_minutes = EXCLUDED.duplicate_minutes,
+			robot9000 = EXCLUDED.robot9000,
+			max_links = EXCLUDED.max_links,
+			link_min_session_minutes = EXCLUDED.link_min_session_minutes,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, settings.Board, settings.PremodMinutes, settings.DuplicateMinutes, settings.Robot9000, settings.MaxLinks, settings.LinkMinSessionMinutes).Scan(&settings.UpdatedAt)
+}
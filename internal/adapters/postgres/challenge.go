@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// ChallengeStore remembers used challenges in the database so that a
+// challenge cannot be spent once on every instance
+type ChallengeStore struct {
+	db *sql.DB
+}
+
+var _ domain.ChallengeStore = (*ChallengeStore)(nil)
+
+func NewChallengeStore(db *sql.DB) *ChallengeStore {
+	return &ChallengeStore{
+		db: db,
+	}
+}
+
+func (s *ChallengeStore) Use(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO used_challenges (nonce, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (nonce) DO NOTHING
+	`, nonce, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	// Expired challenges are rejected anyway, dropping them keeps the table small
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM used_challenges WHERE expires_at < NOW()`); err != nil {
+		slog.Error("Failed to drop expired challenges", "error", err)
+	}
+
+	return rows == 1, nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"1337b04rd/internal/domain"
+)
+
+type CharacterRepository struct {
+	db *sql.DB
+}
+
+var _ domain.CharacterPoolRepository = (*CharacterRepository)(nil)
+
+func NewCharacterRepository(db *sql.DB) *CharacterRepository {
+	return &CharacterRepository{
+		db: db,
+	}
+}
+
+func (r *CharacterRepository) LoadCharacters(ctx context.Context) ([]*domain.Character, error) {
+	query := `SELECT character_id, name, avatar_url FROM character_pool`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var characters []*domain.Character
+	for rows.Next() {
+		var character domain.Character
+
+		err := rows.Scan(
+			&character.ID,
+			&character.Name,
+			&character.AvatarURL,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		characters = append(characters, &character)
+	}
+
+	return characters, rows.Err()
+}
+
+func (r *CharacterRepository) SaveCharacters(ctx context.Context, characters []*domain.Character) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO character_pool (character_id, name, avatar_url)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (character_id) DO UPDATE
+		SET name = EXCLUDED.name, avatar_url = EXCLUDED.avatar_url, fetched_at = NOW()
+	`
+
+	for _, character := range characters {
+		_, err := tx.ExecContext(ctx, query, character.ID, character.Name, character.AvatarURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
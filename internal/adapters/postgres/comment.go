@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log/slog"
 
 	"1337b04rd/internal/domain"
@@ -11,14 +12,19 @@ import (
 )
 
 type CommentRepository struct {
-	db *sql.DB
+	db           *sql.DB
+	posterSecret []byte
 }
 
 var _ domain.CommentRepository = (*CommentRepository)(nil)
 
-func NewCommentRepository(db *sql.DB, defaultBucket string) *CommentRepository {
+// Poster IDs are computed from the session of every comment here, so the
+// session IDs of other authors never leave the repository
+
+func NewCommentRepository(db *sql.DB, defaultBucket string, posterSecret []byte) *CommentRepository {
 	return &CommentRepository{
-		db: db,
+		db:           db,
+		posterSecret: posterSecret,
 	}
 }
 
@@ -34,8 +40,9 @@ func (r *CommentRepository) Save(ctx context.Context, comment *domain.Comment) (
 	query := `
         INSERT INTO comments (
             post_id, parent_id, content, 
-            image_urls, session_id
-        ) VALUES ($1, $2, $3, $4, $5)
+            image_urls, session_id, ip_address,
+            status, pending_reason
+        ) VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::inet, $7, $8)
         RETURNING comment_id, created_at
     `
 
@@ -47,6 +54,9 @@ func (r *CommentRepository) Save(ctx context.Context, comment *domain.Comment) (
 		comment.Content,
 		pq.Array(comment.ImageURLs),
 		comment.User.SessionID,
+		comment.IP,
+		comment.Status,
+		comment.PendingReason,
 	).Scan(
 		&comment.ID,        // Populate the generated UUID
 		&comment.CreatedAt, // Get actual DB timestamp
@@ -56,26 +66,81 @@ func (r *CommentRepository) Save(ctx context.Context, comment *domain.Comment) (
 		return "", err
 	}
 
+	// Comments waiting for approval notify nobody
+	if comment.Status == domain.StatusPublished {
+		if err := insertNotifications(ctx, tx, comment); err != nil {
+			return "", err
+		}
+	}
+
 	return comment.ID, tx.Commit()
 }
 
-func (r *CommentRepository) FindByPostID(ctx context.Context, postid string) ([]*domain.Comment, error) {
+func (r *CommentRepository) FindByID(ctx context.Context, id string) (*domain.Comment, error) {
+	query := `
+		SELECT
+			c.comment_id, c.post_id, c.parent_id,
+			c.content, c.image_urls,
+			c.created_at, c.session_id
+		FROM comments c
+		WHERE c.comment_id = $1 AND c.deleted_at IS NULL
+	`
+
+	var comment domain.Comment
+	var imageURLs pq.StringArray
+	var sessionID sql.NullString
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.ParentID,
+		&comment.Content,
+		&imageURLs,
+		&comment.CreatedAt,
+		&sessionID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	comment.ImageURLs = []string(imageURLs)
+	comment.User.SessionID = sessionID.String
+
+	return &comment, nil
+}
+
+func (r *CommentRepository) FindByPostID(ctx context.Context, postid string, viewer string) ([]*domain.Comment, error) {
 	slog.Info("Postgresql adapter getting comments by post id:")
 
+	// The viewer's posts are looked up once, a comment quotes one of them by
+	// replying to it or by writing its ID after the quote prefix
 	query := `
+		WITH mine AS (
+			SELECT post_id AS id FROM posts WHERE post_id = $1 AND session_id::text = $2
+			UNION ALL
+			SELECT comment_id FROM comments WHERE post_id = $1 AND session_id::text = $2
+		)
 		SELECT 
 			c.comment_id, c.post_id, c.parent_id,
 			c.content, c.image_urls,
-			c.created_at,
-			u.session_id, u.avatar_url,
-			u.username
+			c.created_at, c.deleted_at IS NOT NULL, c.status,
+			c.session_id, u.avatar_url, u.username,
+			COALESCE(c.session_id::text = $2, FALSE),
+			EXISTS (
+				SELECT 1 FROM mine m
+				WHERE m.id = c.parent_id OR strpos(c.content, $3::text || m.id::text) > 0
+			)
 		FROM comments c
 		LEFT JOIN user_sessions u ON c.session_id = u.session_id
 		WHERE c.post_id = $1
+		AND ((u.shadow_banned IS NOT TRUE AND c.status = 'published') OR u.session_id::text = $2)
 		ORDER BY c.created_at ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, postid)
+	rows, err := r.db.QueryContext(ctx, query, postid, viewer, domain.QuotePrefix)
 	if err != nil {
 		slog.Error("Error when executing query:", "error", err)
 		return nil, err
@@ -86,6 +151,7 @@ func (r *CommentRepository) FindByPostID(ctx context.Context, postid string) ([]
 	for rows.Next() {
 		var comment domain.Comment
 		var imageURLs pq.StringArray
+		var sessionID, avatarURL, username sql.NullString
 
 		err := rows.Scan(
 			&comment.ID,
@@ -94,15 +160,34 @@ func (r *CommentRepository) FindByPostID(ctx context.Context, postid string) ([]
 			&comment.Content,
 			&imageURLs,
 			&comment.CreatedAt,
-			&comment.User.SessionID,
-			&comment.User.AvatarURL,
-			&comment.User.Username,
+			&comment.IsDeleted,
+			&comment.Status,
+			&sessionID,
+			&avatarURL,
+			&username,
+			&comment.IsYou,
+			&comment.QuotesYou,
 		)
 		if err != nil {
 			return nil, err
 		}
 
+		// Deleted comments stay in the tree as tombstones without content
+		// or author
+		if comment.IsDeleted {
+			comment.Content = ""
+			comment.IsYou = false
+			comment.QuotesYou = false
+			comments = append(comments, &comment)
+			continue
+		}
+
 		comment.ImageURLs = []string(imageURLs)
+		if sessionID.Valid {
+			comment.PosterID = domain.PosterID(r.posterSecret, comment.PostID, sessionID.String)
+		}
+		comment.User.AvatarURL = avatarURL.String
+		comment.User.Username = username.String
 
 		comments = append(comments, &comment)
 	}
@@ -110,9 +195,34 @@ func (r *CommentRepository) FindByPostID(ctx context.Context, postid string) ([]
 	return comments, nil
 }
 
+// Soft delete the oldest replies of a thread so that at most keep of them
+// stay visible, returns how many were pruned
+
+func (r *CommentRepository) PruneOldest(ctx context.Context, postID string, keep int) (int, error) {
+	query := `
+		UPDATE comments
+		SET deleted_at = NOW(), deleted_by = $3, delete_reason = 'cyclical thread'
+		WHERE comment_id IN (
+			SELECT comment_id
+			FROM comments
+			WHERE post_id = $1 AND deleted_at IS NULL AND status = 'published'
+			ORDER BY created_at DESC
+			OFFSET $2
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, postID, keep, domain.DeletedBySystem)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
 func (r *CommentRepository) ExistByID(ctx context.Context, id string) bool {
 	var count int
-	query := `SELECT COUNT(*) FROM comments WHERE comment_id = $1`
+	query := `SELECT COUNT(*) FROM comments WHERE comment_id = $1 AND deleted_at IS NULL`
 	err := r.db.QueryRow(query, id).Scan(&count)
 	if err != nil {
 		slog.Error("Error when finding out if comment exists by id", "error", err)
@@ -125,3 +235,18 @@ func (r *CommentRepository) ExistByID(ctx context.Context, id string) bool {
 
 	return true
 }
+
+func (r *CommentRepository) SoftDelete(ctx context.Context, id string, deletedBy string, reason string) error {
+	query := `
+		UPDATE comments
+		SET deleted_at = NOW(), deleted_by = $2, delete_reason = $3
+		WHERE comment_id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deletedBy, reason)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+func TestCommentFindByPostID_PosterIDs(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	row := func(id string, deleted bool, sessionID string, isYou bool) []driver.Value {
+		return []driver.Value{
+			id, "p1", nil, "content", []byte("{}"),
+			created, deleted, "published",
+			sessionID, "https://avatar", "Rick",
+			isYou, false,
+		}
+	}
+	conn := &fakeConn{rows: [][]driver.Value{
+		row("c1", false, "s1", true),
+		row("c2", false, "s2", false),
+		row("c3", false, "s1", true),
+		row("c4", true, "s2", false),
+	}}
+	repo := NewCommentRepository(sql.OpenDB(conn), "comments", []byte("secret"))
+
+	got, err := repo.FindByPostID(context.Background(), "p1", "s1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got[0].PosterID != domain.PosterID([]byte("secret"), "p1", "s1") || got[0].PosterID != got[2].PosterID || got[0].PosterID == got[1].PosterID {
+		t.Errorf("expected one poster ID per session, got %q %q %q", got[0].PosterID, got[1].PosterID, got[2].PosterID)
+	}
+	if !got[0].IsYou || got[1].IsYou {
+		t.Errorf("expected only the viewer's comments to be marked, got %v %v", got[0].IsYou, got[1].IsYou)
+	}
+	if got[3].PosterID != "" || got[3].Content != "" {
+		t.Errorf("expected deleted comments to show no poster, got %+v", got[3])
+	}
+	for _, comment := range got {
+		if comment.User.SessionID != "" {
+			t.Errorf("expected no session IDs to leave the repository, got %q", comment.User.SessionID)
+		}
+	}
+
+	encoded, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(encoded), "s1") {
+		t.Errorf("expected session IDs not to be sent, got %s", encoded)
+	}
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+type FingerprintRepository struct {
+	db *sql.DB
+}
+
+var _ domain.FingerprintRepository = (*FingerprintRepository)(nil)
+
+func NewFingerprintRepository(db *sql.DB) *FingerprintRepository {
+	return &FingerprintRepository{
+		db: db,
+	}
+}
+
+// SimHashes are unsigned 64 bit values kept in a signed BIGINT, the bits are
+// the same both ways
+
+func (r *FingerprintRepository) Save(ctx context.Context, fingerprint *domain.Fingerprint) error {
+	query := `
+		INSERT INTO content_fingerprints (board, content_hash, simhash)
+		VALUES ($1, $2, $3)
+		RETURNING created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, fingerprint.Board, fingerprint.Hash, int64(fingerprint.SimHash)).Scan(&fingerprint.CreatedAt)
+}
+
+func (r *FingerprintRepository) ExistsSince(ctx context.Context, hash string, since time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM content_fingerprints
+			WHERE content_hash = $1 AND created_at > $2
+		)
+	`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, hash, since).Scan(&exists)
+	return exists, err
+}
+
+func (r *FingerprintRepository) ExistsOnBoard(ctx context.Context, hash string, board string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM content_fingerprints
+			WHERE content_hash = $1 AND board = $2
+		)
+	`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, hash, board).Scan(&exists)
+	return exists, err
+}
+
+func (r *FingerprintRepository) SimHashesSince(ctx context.Context, since time.Time, limit int) ([]uint64, error) {
+	query := `
+		SELECT simhash
+		FROM content_fingerprints
+		WHERE simhash <> 0 AND created_at > $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var simHashes []uint64
+	for rows.Next() {
+		var simHash int64
+		if err := rows.Scan(&simHash); err != nil {
+			return nil, err
+		}
+		simHashes = append(simHashes, uint64(simHash))
+	}
+
+	return simHashes, rows.Err()
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+)
+
+// --------------------
+// Fake database: records the statements it gets and answers every query with
+// the rows it was given. Rows have as many columns as the query selects, so
+// scanning them checks the destinations against the query.
+// --------------------
+
+type fakeConn struct {
+	statements []string
+	rows       [][]driver.Value
+	committed  bool
+}
+
+func (c *fakeConn) Connect(ctx context.Context) (driver.Conn, error) { return c, nil }
+func (c *fakeConn) Driver() driver.Driver                            { return nil }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{c}, nil }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.statements = append(c.statements, query)
+	return &fakeRows{columns: selectedColumns(query), values: c.rows}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.statements = append(c.statements, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) ran(statement string) bool {
+	for _, query := range c.statements {
+		if strings.Contains(query, statement) {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTx struct{ conn *fakeConn }
+
+func (t fakeTx) Commit() error   { t.conn.committed = true; return nil }
+func (t fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns int
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return make([]string, r.columns) }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// Number of expressions in the RETURNING list, or else in the outermost
+// SELECT list of the query
+func selectedColumns(query string) int {
+	upper := strings.ToUpper(query)
+	start, end := topLevelKeyword(upper, "RETURNING", 0), len(query)
+	if start >= 0 {
+		start += len("RETURNING")
+	} else if start = topLevelKeyword(upper, "SELECT", 0); start >= 0 {
+		start += len("SELECT")
+		end = topLevelKeyword(upper, "FROM", start)
+	}
+	if start < 0 || end < 0 {
+		return 0
+	}
+
+	columns, depth := 1, 0
+	for _, ch := range query[start:end] {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				columns++
+			}
+		}
+	}
+	return columns
+}
+
+// Index of the first keyword at or after from that is not inside
+// parentheses, -1 when there is none
+func topLevelKeyword(upper string, keyword string, from int) int {
+	if from < 0 {
+		return -1
+	}
+	depth := 0
+	for i := from; i < len(upper); i++ {
+		switch upper[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		default:
+			if depth == 0 && strings.HasPrefix(upper[i:], keyword) && isWordBoundary(upper, i-1) && isWordBoundary(upper, i+len(keyword)) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isWordBoundary(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+	ch := s[i]
+	return !(ch == '_' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9')
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+)
+
+type ImageRepository struct {
+	db *sql.DB
+}
+
+var _ domain.ImageRepository = (*ImageRepository)(nil)
+
+func NewImageRepository(db *sql.DB) *ImageRepository {
+	return &ImageRepository{
+		db: db,
+	}
+}
+
+// Perceptual hashes are kept in a signed BIGINT like the SimHashes of
+// content_fingerprints
+
+func (r *ImageRepository) Save(ctx context.Context, image *domain.ImageHash) error {
+	var phash sql.NullInt64
+	if image.PHash != nil {
+		phash = sql.NullInt64{Int64: int64(*image.PHash), Valid: true}
+	}
+
+	query := `
+		INSERT INTO images (image_url, sha256, phash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (image_url) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, image.URL, image.SHA256, phash)
+	return err
+}
+
+func (r *ImageRepository) FindByURL(ctx context.Context, url string) (*domain.ImageHash, error) {
+	query := `
+		SELECT image_url, sha256, phash
+		FROM images
+		WHERE image_url = $1
+	`
+
+	var image domain.ImageHash
+	var phash sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, url).Scan(&image.URL, &image.SHA256, &phash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if phash.Valid {
+		value := uint64(phash.Int64)
+		image.PHash = &value
+	}
+	return &image, nil
+}
+
+// The distance is computed for every hashed image, there is no index for
+// Hamming distance. The post or comment using the image is looked up through
+// the GIN indexes on image_urls.
+
+func (r *ImageRepository) FindSimilar(ctx context.Context, phash uint64, maxDistance int, limit int) ([]*domain.SimilarImage, error) {
+	query := `
+		SELECT i.image_url, i.phash, i.distance, i.created_at,
+			COALESCE(t.target_type, ''), COALESCE(t.target_id, ''), COALESCE(t.board, '')
+		FROM (
+			SELECT image_url, phash, created_at, bit_count((phash # $1)::bit(64)) AS distance
+			FROM images
+			WHERE phash IS NOT NULL
+		) i
+		LEFT JOIN LATERAL (
+			SELECT 'post' AS target_type, p.post_id::text AS target_id, p.board
+			FROM posts p
+			WHERE p.image_urls @> ARRAY[i.image_url]
+			UNION ALL
+			SELECT 'comment', c.comment_id::text, p.board
+			FROM comments c
+			JOIN posts p ON p.post_id = c.post_id
+			WHERE c.image_urls @> ARRAY[i.image_url]
+			LIMIT 1
+		) t ON TRUE
+		WHERE i.distance <= $2
+		ORDER BY i.distance, i.created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, int64(phash), maxDistance, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []*domain.SimilarImage
+	for rows.Next() {
+		var image domain.SimilarImage
+		var imagePHash int64
+		var targetType string
+		if err := rows.Scan(
+			&image.URL,
+			&imagePHash,
+			&image.Distance,
+			&image.CreatedAt,
+			&targetType,
+			&image.TargetID,
+			&image.Board,
+		); err != nil {
+			return nil, err
+		}
+		image.PHash = domain.FormatPHash(uint64(imagePHash))
+		image.TargetType = domain.TargetType(targetType)
+		images = append(images, &image)
+	}
+
+	return images, rows.Err()
+}
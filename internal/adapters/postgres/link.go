@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type LinkRuleRepository struct {
+	db *sql.DB
+}
+
+var _ domain.LinkRuleRepository = (*LinkRuleRepository)(nil)
+
+func NewLinkRuleRepository(db *sql.DB) *LinkRuleRepository {
+	return &LinkRuleRepository{
+		db: db,
+	}
+}
+
+const linkRuleColumns = `rule_id, board, domain, kind, COALESCE(created_by::text, ''), created_at`
+
+func (r *LinkRuleRepository) Create(ctx context.Context, rule *domain.LinkRule) (string, error) {
+	query := `
+		INSERT INTO link_rules (board, domain, kind, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING rule_id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rule.Board,
+		rule.Domain,
+		rule.Kind,
+		rule.CreatedBy,
+	).Scan(
+		&rule.ID,
+		&rule.CreatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return "", domain.ErrLinkRuleExists
+		}
+		return "", err
+	}
+
+	return rule.ID, nil
+}
+
+func (r *LinkRuleRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM link_rules WHERE rule_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *LinkRuleRepository) FindByID(ctx context.Context, id string) (*domain.LinkRule, error) {
+	query := `SELECT ` + linkRuleColumns + ` FROM link_rules WHERE rule_id = $1`
+
+	rule, err := scanLinkRule(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return rule, err
+}
+
+func (r *LinkRuleRepository) List(ctx context.Context) ([]*domain.LinkRule, error) {
+	query := `SELECT ` + linkRuleColumns + ` FROM link_rules ORDER BY board, domain`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*domain.LinkRule
+	for rows.Next() {
+		rule, err := scanLinkRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func scanLinkRule(row rowScanner) (*domain.LinkRule, error) {
+	var rule domain.LinkRule
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.Board,
+		&rule.Domain,
+		&rule.Kind,
+		&rule.CreatedBy,
+		&rule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"1337b04rd/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type ModeratorRepository struct {
+	db *sql.DB
+}
+
+var _ domain.ModeratorRepository = (*ModeratorRepository)(nil)
+
+func NewModeratorRepository(db *sql.DB) *ModeratorRepository {
+	return &ModeratorRepository{
+		db: db,
+	}
+}
+
+func (r *ModeratorRepository) Create(ctx context.Context, moderator *domain.Moderator) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO moderators (username, password_hash)
+		VALUES ($1, $2)
+		RETURNING moderator_id, created_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		moderator.Username,
+		moderator.PasswordHash,
+	).Scan(
+		&moderator.ID,
+		&moderator.CreatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return "", domain.ErrModeratorExists
+		}
+		return "", err
+	}
+
+	if err := insertRoles(ctx, tx, moderator.ID, moderator.Roles); err != nil {
+		return "", err
+	}
+
+	return moderator.ID, tx.Commit()
+}
+
+func (r *ModeratorRepository) FindByID(ctx context.Context, id string) (*domain.Moderator, error) {
+	query := `
+		SELECT moderator_id, username, password_hash, created_at
+		FROM moderators
+		WHERE moderator_id = $1
+	`
+	return r.findOne(ctx, query, id)
+}
+
+func (r *ModeratorRepository) FindByUsername(ctx context.Context, username string) (*domain.Moderator, error) {
+	query := `
+		SELECT moderator_id, username, password_hash, created_at
+		FROM moderators
+		WHERE username = $1
+	`
+	return r.findOne(ctx, query, username)
+}
+
+func (r *ModeratorRepository) FindBySession(ctx context.Context, tokenHash string) (*domain.Moderator, error) {
+	query := `
+		SELECT m.moderator_id, m.username, m.password_hash, m.created_at
+		FROM moderator_sessions s
+		JOIN moderators m ON m.moderator_id = s.moderator_id
+		WHERE s.token_hash = $1 AND s.expires_at > NOW()
+	`
+	return r.findOne(ctx, query, tokenHash)
+}
+
+func (r *ModeratorRepository) List(ctx context.Context) ([]*domain.Moderator, error) {
+	query := `
+		SELECT moderator_id, username, password_hash, created_at
+		FROM moderators
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var moderators []*domain.Moderator
+	for rows.Next() {
+		var moderator domain.Moderator
+
+		err := rows.Scan(
+			&moderator.ID,
+			&moderator.Username,
+			&moderator.PasswordHash,
+			&moderator.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		moderators = append(moderators, &moderator)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, moderator := range moderators {
+		if moderator.Roles, err = r.findRoles(ctx, moderator.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return moderators, nil
+}
+
+func (r *ModeratorRepository) SetRoles(ctx context.Context, moderatorID string, roles []domain.BoardRole) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM moderator_roles WHERE moderator_id = $1`, moderatorID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertRoles(ctx, tx, moderatorID, roles); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ModeratorRepository) CreateSession(ctx context.Context, moderatorID string, tokenHash string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO moderator_sessions (token_hash, moderator_id, expires_at)
+		VALUES ($1, $2, $3)
+	`
+	_, err := r.db.ExecContext(ctx, query, tokenHash, moderatorID, expiresAt)
+	return err
+}
+
+func (r *ModeratorRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM moderator_sessions WHERE token_hash = $1`, tokenHash)
+	return err
+}
+
+func (r *ModeratorRepository) PruneExpiredSessions(ctx context.Context) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM moderator_sessions WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+func (r *ModeratorRepository) findOne(ctx context.Context, query string, arg string) (*domain.Moderator, error) {
+	var moderator domain.Moderator
+
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(
+		&moderator.ID,
+		&moderator.Username,
+		&moderator.PasswordHash,
+		&moderator.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		slog.Error("Postgres, error when finding moderator:", "error", err)
+		return nil, err
+	}
+
+	moderator.Roles, err = r.findRoles(ctx, moderator.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &moderator, nil
+}
+
+func (r *ModeratorRepository) findRoles(ctx context.Context, moderatorID string) ([]domain.BoardRole, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT board, role FROM moderator_roles
+		WHERE moderator_id = $1
+		ORDER BY board
+	`, moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []domain.BoardRole
+	for rows.Next() {
+		var role domain.BoardRole
+		if err := rows.Scan(&role.Board, &role.Role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func insertRoles(ctx context.Context, tx *sql.Tx, moderatorID string, roles []domain.BoardRole) error {
+	query := `
+		INSERT INTO moderator_roles (moderator_id, board, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (moderator_id, board) DO UPDATE SET role = EXCLUDED.role
+	`
+
+	for _, role := range roles {
+		if _, err := tx.ExecContext(ctx, query, moderatorID, role.Board, string(role.Role)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"1337b04rd/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+var _ domain.NotificationRepository = (*NotificationRepository)(nil)
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+// Notify the sessions a new comment replies to or quotes, runs in the
+// transaction saving the comment. A session is notified once per comment,
+// a reply wins over a quote, and nobody is notified about their own comments
+// or about comments of shadow-banned sessions.
+func insertNotifications(ctx context.Context, tx *sql.Tx, comment *domain.Comment) error {
+	query := `
+		INSERT INTO notifications (session_id, comment_id, post_id, kind)
+		SELECT DISTINCT ON (recipient) recipient, $1::uuid, $2::uuid, kind
+		FROM (
+			SELECT session_id AS recipient, 'reply' AS kind, 1 AS rank
+			FROM comments
+			WHERE comment_id = $3
+			UNION ALL
+			SELECT session_id, 'quote', 2
+			FROM posts
+			WHERE post_id = $2 AND strpos($4, $6::text || post_id::text) > 0
+			UNION ALL
+			SELECT session_id, 'quote', 2
+			FROM comments
+			WHERE post_id = $2 AND strpos($4, $6::text || comment_id::text) > 0
+		) quoted
+		WHERE recipient IS NOT NULL AND recipient::text <> $5
+		AND NOT EXISTS (
+			SELECT 1 FROM user_sessions
+			WHERE session_id::text = $5 AND shadow_banned
+		)
+		ORDER BY recipient, rank
+		ON CONFLICT (session_id, comment_id) DO NOTHING
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		comment.ID,
+		comment.PostID,
+		comment.ParentID,
+		comment.Content,
+		comment.User.SessionID,
+		domain.QuotePrefix,
+	)
+	return err
+}
+
+// Comments deleted or hidden since are left out
+func (r *NotificationRepository) ListBySession(ctx context.Context, sessionID string, limit int) ([]*domain.Notification, error) {
+	query := `
+		SELECT n.notification_id, n.kind, n.post_id, n.comment_id,
+			COALESCE(u.username, ''), left(c.content, 400),
+			n.created_at, n.read_at IS NOT NULL
+		FROM notifications n
+		JOIN comments c ON c.comment_id = n.comment_id
+		LEFT JOIN user_sessions u ON u.session_id = c.session_id
+		WHERE n.session_id::text = $1
+		AND c.deleted_at IS NULL AND c.status = 'published'
+		ORDER BY n.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		var notification domain.Notification
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.Kind,
+			&notification.PostID,
+			&notification.CommentID,
+			&notification.Username,
+			&notification.Excerpt,
+			&notification.CreatedAt,
+			&notification.Read,
+		); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, &notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, sessionID string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM notifications n
+		JOIN comments c ON c.comment_id = n.comment_id
+		WHERE n.session_id::text = $1 AND n.read_at IS NULL
+		AND c.deleted_at IS NULL AND c.status = 'published'
+	`
+
+	var unread int
+	err := r.db.QueryRowContext(ctx, query, sessionID).Scan(&unread)
+	return unread, err
+}
+
+// IDs are compared as text, so malformed ones just match nothing
+func (r *NotificationRepository) MarkRead(ctx context.Context, sessionID string, ids []string) (int, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = NOW()
+		WHERE session_id::text = $1 AND read_at IS NULL
+		AND (cardinality($2::text[]) = 0 OR notification_id::text = ANY($2))
+	`
+
+	result, err := r.db.ExecContext(ctx, query, sessionID, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+func (r *NotificationRepository) PruneExpired(ctx context.Context) (int, error) {
+	query := `
+		DELETE FROM notifications n
+		USING user_sessions u
+		WHERE u.session_id = n.session_id AND u.expires_at < NOW()
+	`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
@@ -37,8 +37,9 @@ func (r *PostRepository) Save(ctx context.Context, post *domain.Post) (*domain.P
 	query := `
         INSERT INTO posts (
             session_id, title, content, 
-            image_urls
-        ) VALUES ($1, $2, $3, $4)
+            image_urls, board, ip_address,
+            status, pending_reason
+        ) VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::inet, $7, $8)
         RETURNING post_id, created_at, updated_at
     `
 
@@ -47,6 +48,10 @@ func (r *PostRepository) Save(ctx context.Context, post *domain.Post) (*domain.P
 		post.Title,
 		post.Content,
 		pq.Array(post.ImageURLs),
+		post.Board,
+		post.IP,
+		post.Status,
+		post.PendingReason,
 	).Scan(
 		&post.ID,        // Populate the generated UUID
 		&post.CreatedAt, // Get actual DB timestamp
@@ -59,25 +64,28 @@ func (r *PostRepository) Save(ctx context.Context, post *domain.Post) (*domain.P
 	return post, tx.Commit()
 }
 
-func (r *PostRepository) FindByID(ctx context.Context, id string) (*domain.Post, error) {
+func (r *PostRepository) FindByID(ctx context.Context, id string, viewer string, includeHidden bool) (*domain.Post, error) {
 	query := `
 		SELECT 
-			p.post_id, p.title, p.content,
+			p.post_id, p.board, p.title, p.content,
 			p.image_urls,
 			p.created_at, p.updated_at, p.is_archived, p.archived_at,
+			p.sticky, p.locked, p.cyclical, p.status,
 			u.session_id, u.avatar_url, 
-			u.username
+			u.username, u.session_id::text = $2
 		FROM posts p
 		JOIN user_sessions u ON p.session_id = u.session_id
-		WHERE p.post_id = $1
+		WHERE p.post_id = $1 AND p.deleted_at IS NULL
+		AND ((u.shadow_banned = FALSE AND p.status = 'published') OR u.session_id::text = $2 OR $3)
 	`
 
 	var post domain.Post
 	var imageURLs pq.StringArray
 	var archivedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, viewer, includeHidden).Scan(
 		&post.ID,
+		&post.Board,
 		&post.Title,
 		&post.Content,
 		&imageURLs,
@@ -85,9 +93,14 @@ func (r *PostRepository) FindByID(ctx context.Context, id string) (*domain.Post,
 		&post.UpdatedAt,
 		&post.IsArchived,
 		&archivedAt,
+		&post.Sticky,
+		&post.Locked,
+		&post.Cyclical,
+		&post.Status,
 		&post.User.SessionID,
 		&post.User.AvatarURL,
 		&post.User.Username,
+		&post.IsYou,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -107,21 +120,23 @@ func (r *PostRepository) FindByID(ctx context.Context, id string) (*domain.Post,
 	return &post, nil
 }
 
-func (r *PostRepository) FindActive(ctx context.Context) ([]*domain.Post, error) {
+func (r *PostRepository) FindActive(ctx context.Context, viewer string) ([]*domain.Post, error) {
 	query := `
 		SELECT 
-			p.post_id, p.title, p.content,
+			p.post_id, p.board, p.title, p.content,
 			p.image_urls,
 			p.created_at, p.updated_at,
+			p.sticky, p.locked, p.cyclical, p.status,
 			u.session_id, u.avatar_url,
 			u.username
 		FROM posts p
 		JOIN user_sessions u ON p.session_id = u.session_id
-		WHERE p.is_archived = FALSE
-		ORDER BY p.created_at DESC
+		WHERE p.is_archived = FALSE AND p.deleted_at IS NULL
+		AND ((u.shadow_banned = FALSE AND p.status = 'published') OR u.session_id::text = $1)
+		ORDER BY p.sticky DESC, p.created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, viewer)
 	if err != nil {
 		return nil, err
 	}
@@ -134,11 +149,16 @@ func (r *PostRepository) FindActive(ctx context.Context) ([]*domain.Post, error)
 
 		err := rows.Scan(
 			&post.ID,
+			&post.Board,
 			&post.Title,
 			&post.Content,
 			&imageURLs,
 			&post.CreatedAt,
 			&post.UpdatedAt,
+			&post.Sticky,
+			&post.Locked,
+			&post.Cyclical,
+			&post.Status,
 			&post.User.SessionID,
 			&post.User.AvatarURL,
 			&post.User.Username,
@@ -155,21 +175,23 @@ func (r *PostRepository) FindActive(ctx context.Context) ([]*domain.Post, error)
 	return posts, nil
 }
 
-func (r *PostRepository) FindArchived(ctx context.Context) ([]*domain.Post, error) {
+func (r *PostRepository) FindArchived(ctx context.Context, viewer string) ([]*domain.Post, error) {
 	query := `
 		SELECT 
-			p.post_id, p.title, p.content,
+			p.post_id, p.board, p.title, p.content,
 			p.image_urls,
 			p.created_at, p.updated_at,
+			p.sticky, p.locked, p.cyclical, p.status,
 			u.session_id, u.avatar_url,
 			u.username
 		FROM posts p
 		JOIN user_sessions u ON p.session_id = u.session_id
-		WHERE p.is_archived = TRUE
+		WHERE p.is_archived = TRUE AND p.deleted_at IS NULL
+		AND ((u.shadow_banned = FALSE AND p.status = 'published') OR u.session_id::text = $1)
 		ORDER BY p.created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, viewer)
 	if err != nil {
 		return nil, err
 	}
@@ -182,11 +204,16 @@ func (r *PostRepository) FindArchived(ctx context.Context) ([]*domain.Post, erro
 
 		err := rows.Scan(
 			&post.ID,
+			&post.Board,
 			&post.Title,
 			&post.Content,
 			&imageURLs,
 			&post.CreatedAt,
 			&post.UpdatedAt,
+			&post.Sticky,
+			&post.Locked,
+			&post.Cyclical,
+			&post.Status,
 			&post.User.SessionID,
 			&post.User.AvatarURL,
 			&post.User.Username,
@@ -209,6 +236,72 @@ func (r *PostRepository) ArchiveOldPosts(ctx context.Context) error {
 	return err
 }
 
+func (r *PostRepository) SoftDelete(ctx context.Context, id string, deletedBy string, reason string) error {
+	query := `
+		UPDATE posts
+		SET deleted_at = NOW(), deleted_by = $2, delete_reason = $3
+		WHERE post_id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deletedBy, reason)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+func (r *PostRepository) SetFlag(ctx context.Context, id string, flag domain.ThreadFlag, on bool) error {
+	var column string
+	switch flag {
+	case domain.FlagSticky:
+		column = "sticky"
+	case domain.FlagLocked:
+		column = "locked"
+	case domain.FlagCyclical:
+		column = "cyclical"
+	default:
+		return domain.ErrInvalidThreadFlag
+	}
+
+	query := `UPDATE posts SET ` + column + ` = $2 WHERE post_id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, on)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+func (r *PostRepository) Unarchive(ctx context.Context, id string) error {
+	query := `
+		UPDATE posts
+		SET is_archived = FALSE, archived_at = NULL, unarchived_at = NOW()
+		WHERE post_id = $1 AND is_archived = TRUE AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// Turn an update that matched nothing into ErrNotFound
+
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
 func sqlNullTime(t *time.Time) sql.NullTime {
 	if t == nil {
 		return sql.NullTime{Valid: false}
@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+func TestPostFindByID_ScansEveryColumn(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	conn := &fakeConn{rows: [][]driver.Value{{
+		"p1", "b", "title", "content",
+		[]byte("{https://img/1.png}"),
+		created, created, false, nil,
+		true, false, false, "pending",
+		"s1", "https://avatar", "Rick", true,
+	}}}
+	repo := NewPostRepository(sql.OpenDB(conn), "posts")
+
+	post, err := repo.FindByID(context.Background(), "p1", "s1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if post.Status != domain.StatusPending || !post.Sticky || post.User.Username != "Rick" || !post.IsYou || len(post.ImageURLs) != 1 {
+		t.Errorf("expected every column in its field, got %+v", post)
+	}
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type QueueRepository struct {
+	db *sql.DB
+}
+
+var _ domain.QueueRepository = (*QueueRepository)(nil)
+
+func NewQueueRepository(db *sql.DB) *QueueRepository {
+	return &QueueRepository{
+		db: db,
+	}
+}
+
+// Pending posts and comments in one list, comments carry the board of their
+// thread
+const pendingQuery = `
+	SELECT * FROM (
+		SELECT
+			'post' AS target_type, p.post_id AS target_id, p.post_id, p.board,
+			p.title, p.content, p.image_urls,
+			COALESCE(p.session_id::text, ''), COALESCE(u.username, ''),
+			COALESCE(p.pending_reason, ''), p.created_at
+		FROM posts p
+		LEFT JOIN user_sessions u ON p.session_id = u.session_id
+		WHERE p.status = 'pending' AND p.deleted_at IS NULL
+		UNION ALL
+		SELECT
+			'comment', c.comment_id, c.post_id, cp.board,
+			'', c.content, c.image_urls,
+			COALESCE(c.session_id::text, ''), COALESCE(u.username, ''),
+			COALESCE(c.pending_reason, ''), c.created_at
+		FROM comments c
+		JOIN posts cp ON c.post_id = cp.post_id
+		LEFT JOIN user_sessions u ON c.session_id = u.session_id
+		WHERE c.status = 'pending' AND c.deleted_at IS NULL
+	) pending
+`
+
+func (r *QueueRepository) FindPending(ctx context.Context) ([]*domain.PendingItem, error) {
+	rows, err := r.db.QueryContext(ctx, pendingQuery+` ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*domain.PendingItem
+	for rows.Next() {
+		item, err := scanPendingItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *QueueRepository) FindPendingItem(ctx context.Context, targetType domain.TargetType, id string) (*domain.PendingItem, error) {
+	query := pendingQuery + ` WHERE target_type = $1 AND target_id::text = $2`
+
+	item, err := scanPendingItem(r.db.QueryRowContext(ctx, query, targetType, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return item, err
+}
+
+// Approved threads start their archive clock at the approval, not at posting.
+// Approved comments notify the sessions they reply to or quote, like the
+// comments that are published right away.
+
+func (r *QueueRepository) SetStatus(ctx context.Context, targetType domain.TargetType, id string, status domain.ContentStatus) error {
+	switch targetType {
+	case domain.TargetPost:
+		return r.setPostStatus(ctx, id, status)
+	case domain.TargetComment:
+		return r.setCommentStatus(ctx, id, status)
+	default:
+		return domain.ErrInvalidReportTarget
+	}
+}
+
+func (r *QueueRepository) setPostStatus(ctx context.Context, id string, status domain.ContentStatus) error {
+	query := `
+		UPDATE posts
+		SET status = $2,
+			unarchived_at = CASE WHEN $2 = 'published' THEN NOW() ELSE unarchived_at END
+		WHERE post_id = $1 AND status = 'pending'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, status)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotPending
+	}
+	return nil
+}
+
+func (r *QueueRepository) setCommentStatus(ctx context.Context, id string, status domain.ContentStatus) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE comments SET status = $2
+		WHERE comment_id = $1 AND status = 'pending'
+		RETURNING comment_id, post_id, parent_id, content, session_id
+	`
+
+	comment := domain.Comment{Status: status}
+	var sessionID sql.NullString
+
+	err = tx.QueryRowContext(ctx, query, id, status).Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.ParentID,
+		&comment.Content,
+		&sessionID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotPending
+	}
+	if err != nil {
+		return err
+	}
+	comment.User.SessionID = sessionID.String
+
+	if status == domain.StatusPublished {
+		if err := insertNotifications(ctx, tx, &comment); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func scanPendingItem(row rowScanner) (*domain.PendingItem, error) {
+	var item domain.PendingItem
+	var imageURLs pq.StringArray
+
+	err := row.Scan(
+		&item.TargetType,
+		&item.TargetID,
+		&item.PostID,
+		&item.Board,
+		&item.Title,
+		&item.Content,
+		&imageURLs,
+		&item.SessionID,
+		&item.Username,
+		&item.Reason,
+		&item.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	item.ImageURLs = []string(imageURLs)
+	return &item, nil
+}
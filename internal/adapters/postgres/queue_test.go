@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Tests
+// --------------------
+
+func TestSetStatus_ApprovedCommentNotifies(t *testing.T) {
+	conn := &fakeConn{rows: [][]driver.Value{{"c1", "p1", nil, ">>c0 agreed", "s1"}}}
+	repo := NewQueueRepository(sql.OpenDB(conn))
+
+	if err := repo.SetStatus(context.Background(), domain.TargetComment, "c1", domain.StatusPublished); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !conn.ran("UPDATE comments") || !conn.ran("INSERT INTO notifications") {
+		t.Fatalf("expected approval and notifications, got %q", conn.statements)
+	}
+	if !conn.committed {
+		t.Error("expected approval and notifications to be committed together")
+	}
+}
+
+func TestSetStatus_RejectedCommentNotifiesNobody(t *testing.T) {
+	conn := &fakeConn{rows: [][]driver.Value{{"c1", "p1", nil, ">>c0 agreed", "s1"}}}
+	repo := NewQueueRepository(sql.OpenDB(conn))
+
+	if err := repo.SetStatus(context.Background(), domain.TargetComment, "c1", domain.StatusRejected); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conn.ran("INSERT INTO notifications") {
+		t.Error("expected rejected comments to notify nobody")
+	}
+}
+
+func TestSetStatus_CommentNotPending(t *testing.T) {
+	conn := &fakeConn{}
+	repo := NewQueueRepository(sql.OpenDB(conn))
+
+	err := repo.SetStatus(context.Background(), domain.TargetComment, "c1", domain.StatusPublished)
+	if !errors.Is(err, domain.ErrNotPending) {
+		t.Fatalf("expected ErrNotPending, got %v", err)
+	}
+	if conn.ran("INSERT INTO notifications") || conn.committed {
+		t.Error("expected nothing to be written")
+	}
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// RateLimitStore keeps token buckets in the database so that every instance
+// counts against the same limits
+type RateLimitStore struct {
+	db *sql.DB
+}
+
+var _ domain.RateLimitStore = (*RateLimitStore)(nil)
+
+func NewRateLimitStore(db *sql.DB) *RateLimitStore {
+	return &RateLimitStore{
+		db: db,
+	}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit, n int) (time.Duration, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Make sure the row exists so it can be locked, new buckets are full
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (bucket_key) DO NOTHING
+	`, key, limit.Burst)
+	if err != nil {
+		return 0, err
+	}
+
+	// The database clock is used so instances with skewed clocks agree
+	var bucket domain.RateBucket
+	var now time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT tokens, updated_at, NOW()
+		FROM rate_limit_buckets
+		WHERE bucket_key = $1
+		FOR UPDATE
+	`, key).Scan(&bucket.Tokens, &bucket.UpdatedAt, &now)
+	if err != nil {
+		return 0, err
+	}
+
+	bucket, retryAfter := limit.Take(bucket, now, n)
+	if retryAfter > 0 {
+		return retryAfter, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3
+		WHERE bucket_key = $1
+	`, key, bucket.Tokens, bucket.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// Old buckets are full again, dropping them keeps the table small
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - INTERVAL '1 day'`); err != nil {
+		slog.Error("Failed to drop old rate limit buckets", "error", err)
+	}
+
+	return 0, nil
+}
+
+func (s *RateLimitStore) Give(ctx context.Context, key string, limit domain.RateLimit, n int) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE rate_limit_buckets SET tokens = LEAST(tokens + $2, $3)
+		WHERE bucket_key = $1
+	`, key, n, limit.Burst)
+	return err
+}
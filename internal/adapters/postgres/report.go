@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type ReportRepository struct {
+	db *sql.DB
+}
+
+var _ domain.ReportRepository = (*ReportRepository)(nil)
+
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{
+		db: db,
+	}
+}
+
+func (r *ReportRepository) Save(ctx context.Context, report *domain.Report) error {
+	// A session has at most one open report per target
+	query := `
+		INSERT INTO reports (target_type, target_id, session_id, category, note)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (target_type, target_id, session_id) WHERE resolved_at IS NULL DO NOTHING
+		RETURNING report_id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		report.TargetType,
+		report.TargetID,
+		report.SessionID,
+		report.Category,
+		report.Note,
+	).Scan(
+		&report.ID,
+		&report.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrAlreadyReported
+	}
+	return err
+}
+
+func (r *ReportRepository) FindOpen(ctx context.Context) ([]*domain.ReportSummary, error) {
+	query := `
+		SELECT
+			r.target_type, r.target_id,
+			COALESCE(p.board, cp.board, ''),
+			LEFT(COALESCE(NULLIF(p.title, ''), p.content, c.content, ''), 200),
+			COUNT(*),
+			array_agg(r.category),
+			COALESCE(array_agg(r.note ORDER BY r.created_at) FILTER (WHERE r.note <> ''), '{}'),
+			MIN(r.created_at), MAX(r.created_at)
+		FROM reports r
+		LEFT JOIN posts p ON r.target_type = 'post' AND p.post_id = r.target_id
+		LEFT JOIN comments c ON r.target_type = 'comment' AND c.comment_id = r.target_id
+		LEFT JOIN posts cp ON cp.post_id = c.post_id
+		WHERE r.resolved_at IS NULL
+		GROUP BY r.target_type, r.target_id, p.board, cp.board, p.title, p.content, c.content
+		ORDER BY COUNT(*) DESC, MAX(r.created_at) DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*domain.ReportSummary
+	for rows.Next() {
+		var summary domain.ReportSummary
+		var categories, notes pq.StringArray
+
+		err := rows.Scan(
+			&summary.TargetType,
+			&summary.TargetID,
+			&summary.Board,
+			&summary.Preview,
+			&summary.Count,
+			&categories,
+			&notes,
+			&summary.FirstReportedAt,
+			&summary.LastReportedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.Categories = make(map[domain.ReportCategory]int)
+		for _, category := range categories {
+			summary.Categories[domain.ReportCategory(category)]++
+		}
+		summary.Notes = []string(notes)
+
+		summaries = append(summaries, &summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+// Board and author of the reported content, deleted content included so its
+// reports can still be resolved
+
+func (r *ReportRepository) FindTarget(ctx context.Context, targetType domain.TargetType, targetID string) (*domain.ReportTarget, error) {
+	var query string
+	switch targetType {
+	case domain.TargetPost:
+		query = `
+			SELECT board, session_id, host(ip_address), title || E'\n' || content
+			FROM posts
+			WHERE post_id = $1
+		`
+	case domain.TargetComment:
+		query = `
+			SELECT p.board, c.session_id, host(c.ip_address), c.content
+			FROM comments c
+			JOIN posts p ON p.post_id = c.post_id
+			WHERE c.comment_id = $1
+		`
+	default:
+		return nil, domain.ErrInvalidReportTarget
+	}
+
+	var target domain.ReportTarget
+	var sessionID, ip sql.NullString
+
+	err := r.db.QueryRowContext(ctx, query, targetID).Scan(&target.Board, &sessionID, &ip, &target.Text)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	target.SessionID = sessionID.String
+	target.IP = ip.String
+	return &target, nil
+}
+
+// Create the bans, delete the target and close its reports in one
+// transaction, returns how many reports were closed
+
+func (r *ReportRepository) Resolve(ctx context.Context, resolution *domain.ReportResolution) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, ban := range resolution.Bans {
+		query, args, err := insertBanQuery(ban)
+		if err != nil {
+			return 0, err
+		}
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(&ban.ID, &ban.CreatedAt); err != nil {
+			return 0, err
+		}
+	}
+
+	// Content that is already gone only needs its reports closed
+	if resolution.Action != domain.ReportDismiss {
+		var query string
+		switch resolution.TargetType {
+		case domain.TargetPost:
+			query = `
+				UPDATE posts
+				SET deleted_at = NOW(), deleted_by = $2, delete_reason = $3
+				WHERE post_id = $1 AND deleted_at IS NULL
+			`
+		case domain.TargetComment:
+			query = `
+				UPDATE comments
+				SET deleted_at = NOW(), deleted_by = $2, delete_reason = $3
+				WHERE comment_id = $1 AND deleted_at IS NULL
+			`
+		default:
+			return 0, domain.ErrInvalidReportTarget
+		}
+
+		if _, err := tx.ExecContext(ctx, query, resolution.TargetID, resolution.ModeratorID, resolution.Reason); err != nil {
+			return 0, err
+		}
+	}
+
+	query := `
+		UPDATE reports
+		SET resolved_at = NOW(), resolved_by = $3, resolution = $4
+		WHERE target_type = $1 AND target_id = $2 AND resolved_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, resolution.TargetType, resolution.TargetID, resolution.ModeratorID, resolution.Action)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(resolved), tx.Commit()
+}
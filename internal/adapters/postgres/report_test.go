@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+func TestResolve_BanDeleteAndCloseTogether(t *testing.T) {
+	conn := &fakeConn{rows: [][]driver.Value{{"b1", time.Now()}}}
+	repo := NewReportRepository(sql.OpenDB(conn))
+
+	resolution := &domain.ReportResolution{
+		TargetType:  domain.TargetComment,
+		TargetID:    "c1",
+		Action:      domain.ReportBan,
+		ModeratorID: "m1",
+		Reason:      "spam",
+		Bans:        []*domain.Ban{{Kind: domain.BanIP, Value: "203.0.113.7/32", Reason: "spam", CreatedBy: "m1"}},
+	}
+	if _, err := repo.Resolve(context.Background(), resolution); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !conn.ran("INSERT INTO bans") || !conn.ran("UPDATE comments") || !conn.ran("UPDATE reports") {
+		t.Fatalf("expected ban, deletion and resolution, got %q", conn.statements)
+	}
+	if !conn.committed || resolution.Bans[0].ID != "b1" {
+		t.Errorf("expected one committed transaction that fills in the ban ID, got %+v", resolution.Bans[0])
+	}
+}
+
+func TestResolve_DismissDeletesNothing(t *testing.T) {
+	conn := &fakeConn{}
+	repo := NewReportRepository(sql.OpenDB(conn))
+
+	resolution := &domain.ReportResolution{TargetType: domain.TargetPost, TargetID: "p1", Action: domain.ReportDismiss, ModeratorID: "m1"}
+	if _, err := repo.Resolve(context.Background(), resolution); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conn.ran("UPDATE posts") || !conn.ran("UPDATE reports") {
+		t.Errorf("expected only the reports to be closed, got %q", conn.statements)
+	}
+}
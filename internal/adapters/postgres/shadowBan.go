@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type ShadowBanRepository struct {
+	db *sql.DB
+}
+
+var _ domain.ShadowBanRepository = (*ShadowBanRepository)(nil)
+
+func NewShadowBanRepository(db *sql.DB) *ShadowBanRepository {
+	return &ShadowBanRepository{
+		db: db,
+	}
+}
+
+func (r *ShadowBanRepository) SetShadowBanned(ctx context.Context, sessionID string, banned bool) error {
+	query := `
+		UPDATE user_sessions
+		SET shadow_banned = $2,
+			shadow_banned_at = CASE WHEN $2 THEN NOW() ELSE NULL END
+		WHERE session_id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, sessionID, banned)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *ShadowBanRepository) ListSessions(ctx context.Context) ([]*domain.ShadowBannedSession, error) {
+	query := `
+		SELECT
+			u.session_id, u.username, u.avatar_url, u.shadow_banned_at,
+			(SELECT COUNT(*) FROM posts p WHERE p.session_id = u.session_id AND p.deleted_at IS NULL),
+			(SELECT COUNT(*) FROM comments c WHERE c.session_id = u.session_id AND c.deleted_at IS NULL)
+		FROM user_sessions u
+		WHERE u.shadow_banned
+		ORDER BY u.shadow_banned_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.ShadowBannedSession
+	for rows.Next() {
+		var session domain.ShadowBannedSession
+		err := rows.Scan(
+			&session.SessionID,
+			&session.Username,
+			&session.AvatarURL,
+			&session.ShadowBannedAt,
+			&session.Posts,
+			&session.Comments,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r *ShadowBanRepository) FindContent(ctx context.Context, sessionID string) (*domain.ShadowContent, error) {
+	var user domain.User
+	err := r.db.QueryRowContext(ctx, `
+		SELECT session_id, avatar_url, username
+		FROM user_sessions
+		WHERE session_id = $1 AND shadow_banned
+	`, sessionID).Scan(&user.SessionID, &user.AvatarURL, &user.Username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content := &domain.ShadowContent{}
+
+	postRows, err := r.db.QueryContext(ctx, `
+		SELECT post_id, board, title, content, image_urls, created_at, updated_at, is_archived
+		FROM posts
+		WHERE session_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer postRows.Close()
+
+	for postRows.Next() {
+		post := domain.Post{User: user}
+		var imageURLs pq.StringArray
+		err := postRows.Scan(&post.ID, &post.Board, &post.Title, &post.Content, &imageURLs, &post.CreatedAt, &post.UpdatedAt, &post.IsArchived)
+		if err != nil {
+			return nil, err
+		}
+		post.ImageURLs = []string(imageURLs)
+		content.Posts = append(content.Posts, &post)
+	}
+	if err := postRows.Err(); err != nil {
+		return nil, err
+	}
+
+	commentRows, err := r.db.QueryContext(ctx, `
+		SELECT comment_id, post_id, parent_id, content, image_urls, created_at
+		FROM comments
+		WHERE session_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer commentRows.Close()
+
+	for commentRows.Next() {
+		comment := domain.Comment{User: user}
+		var imageURLs pq.StringArray
+		err := commentRows.Scan(&comment.ID, &comment.PostID, &comment.ParentID, &comment.Content, &imageURLs, &comment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		comment.ImageURLs = []string(imageURLs)
+		content.Comments = append(content.Comments, &comment)
+	}
+
+	return content, commentRows.Err()
+}
+
+// Soft delete everything posted by the given shadow-banned sessions, or by
+// all of them when no session is given. Returns the number of deleted posts
+// and comments.
+
+func (r *ShadowBanRepository) Purge(ctx context.Context, sessionIDs []string, deletedBy string, reason string) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	purged := 0
+	for _, table := range []string{"posts", "comments"} {
+		query := `
+			UPDATE ` + table + ` t
+			SET deleted_at = NOW(), deleted_by = $2, delete_reason = $3
+			FROM user_sessions u
+			WHERE t.session_id = u.session_id
+			AND u.shadow_banned
+			AND t.deleted_at IS NULL
+			AND (COALESCE(cardinality($1::uuid[]), 0) = 0 OR u.session_id = ANY($1::uuid[]))
+		`
+
+		result, err := tx.ExecContext(ctx, query, pq.Array(sessionIDs), deletedBy, reason)
+		if err != nil {
+			return 0, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		purged += int(affected)
+	}
+
+	return purged, tx.Commit()
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"1337b04rd/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type SpamCorpusRepository struct {
+	db *sql.DB
+}
+
+var _ domain.SpamCorpusRepository = (*SpamCorpusRepository)(nil)
+
+func NewSpamCorpusRepository(db *sql.DB) *SpamCorpusRepository {
+	return &SpamCorpusRepository{
+		db: db,
+	}
+}
+
+func (r *SpamCorpusRepository) Counts(ctx context.Context, tokens []string) (map[string]domain.SpamTokenCount, domain.SpamTotals, error) {
+	var totals domain.SpamTotals
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(texts) FILTER (WHERE is_spam), 0),
+			COALESCE(SUM(texts) FILTER (WHERE NOT is_spam), 0)
+		FROM spam_totals
+	`).Scan(&totals.Spam, &totals.Ham)
+	if err != nil {
+		return nil, totals, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT token, spam_count, ham_count
+		FROM spam_tokens
+		WHERE token = ANY($1)
+	`, pq.Array(tokens))
+	if err != nil {
+		return nil, totals, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]domain.SpamTokenCount)
+	for rows.Next() {
+		var token string
+		var count domain.SpamTokenCount
+		if err := rows.Scan(&token, &count.Spam, &count.Ham); err != nil {
+			return nil, totals, err
+		}
+		counts[token] = count
+	}
+
+	return counts, totals, rows.Err()
+}
+
+func (r *SpamCorpusRepository) Learn(ctx context.Context, tokens []string, spam bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	spamCount, hamCount := 0, 1
+	if spam {
+		spamCount, hamCount = 1, 0
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO spam_tokens (token, spam_count, ham_count)
+		SELECT token, $2, $3 FROM unnest($1::text[]) AS token
+		ON CONFLICT (token) DO UPDATE
+		SET spam_count = spam_tokens.spam_count + EXCLUDED.spam_count,
+			ham_count = spam_tokens.ham_count + EXCLUDED.ham_count
+	`, pq.Array(tokens), spamCount, hamCount)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO spam_totals (is_spam, texts)
+		VALUES ($1, 1)
+		ON CONFLICT (is_spam) DO UPDATE
+		SET texts = spam_totals.texts + 1
+	`, spam)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+)
+
+type ThreadRepository struct {
+	db *sql.DB
+}
+
+var _ domain.ThreadRepository = (*ThreadRepository)(nil)
+
+func NewThreadRepository(db *sql.DB) *ThreadRepository {
+	return &ThreadRepository{
+		db: db,
+	}
+}
+
+// Comments have no board of their own, they follow their thread
+
+func (r *ThreadRepository) Move(ctx context.Context, postID string, board string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE posts SET board = $2
+		WHERE post_id = $1 AND deleted_at IS NULL
+	`, postID, board)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ThreadRepository) Merge(ctx context.Context, sourceID string, targetID string, deletedBy string) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Lock both threads so no reply lands in the source halfway through
+	var locked int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT post_id FROM posts
+			WHERE post_id IN ($1, $2) AND deleted_at IS NULL
+			FOR UPDATE
+		) t
+	`, sourceID, targetID).Scan(&locked)
+	if err != nil {
+		return 0, err
+	}
+	if locked != 2 {
+		return 0, domain.ErrNotFound
+	}
+
+	// The opening post keeps its author, images and time as a comment
+	var opCommentID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO comments (post_id, content, image_urls, session_id, ip_address, status, created_at)
+		SELECT $2::uuid, title || E'\n\n' || content, image_urls, session_id, ip_address, status, created_at
+		FROM posts
+		WHERE post_id = $1
+		RETURNING comment_id
+	`, sourceID, targetID).Scan(&opCommentID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Top level replies of the source answered its opening post
+	_, err = tx.ExecContext(ctx, `
+		UPDATE comments SET parent_id = $2
+		WHERE post_id = $1 AND parent_id IS NULL
+	`, sourceID, opCommentID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Comments are listed by creation time, so they interleave with the
+	// replies of the target in chronological order
+	result, err := tx.ExecContext(ctx, `UPDATE comments SET post_id = $2 WHERE post_id = $1`, sourceID, targetID)
+	if err != nil {
+		return 0, err
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE notifications SET post_id = $2 WHERE post_id = $1`, sourceID, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Watchers of the source watch the target instead, those watching both
+	// keep the older of their markers so no reply is skipped
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO thread_watches (session_id, post_id, created_at, last_viewed_at)
+		SELECT session_id, $2, created_at, last_viewed_at
+		FROM thread_watches
+		WHERE post_id = $1
+		ON CONFLICT (session_id, post_id) DO UPDATE
+		SET last_viewed_at = LEAST(thread_watches.last_viewed_at, EXCLUDED.last_viewed_at)
+	`, sourceID, targetID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM thread_watches WHERE post_id = $1`, sourceID); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE posts
+		SET deleted_at = NOW(), deleted_by = $2, delete_reason = 'merged into ' || $3
+		WHERE post_id = $1
+	`, sourceID, deletedBy, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(moved) + 1, tx.Commit()
+}
+
+func (r *ThreadRepository) Split(ctx context.Context, commentID string, title string, deletedBy string) (string, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	defer tx.Rollback()
+
+	// The new thread starts its archive clock now, not at the time of the comment
+	var postID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO posts (session_id, title, content, image_urls, board, ip_address, status, created_at, unarchived_at)
+		SELECT c.session_id, $2, c.content, c.image_urls, p.board, c.ip_address, c.status, c.created_at, NOW()
+		FROM comments c
+		JOIN posts p ON c.post_id = p.post_id
+		WHERE c.comment_id = $1 AND c.deleted_at IS NULL AND p.deleted_at IS NULL
+		RETURNING post_id
+	`, commentID, title).Scan(&postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, domain.ErrNotFound
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Direct replies become top level comments of the new thread
+	result, err := tx.ExecContext(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT comment_id FROM comments WHERE parent_id = $1
+			UNION ALL
+			SELECT c.comment_id FROM comments c JOIN subtree s ON c.parent_id = s.comment_id
+		)
+		UPDATE comments
+		SET post_id = $2, parent_id = CASE WHEN parent_id = $1 THEN NULL ELSE parent_id END
+		WHERE comment_id IN (SELECT comment_id FROM subtree)
+	`, commentID, postID)
+	if err != nil {
+		return "", 0, err
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return "", 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE notifications n SET post_id = c.post_id
+		FROM comments c
+		WHERE n.comment_id = c.comment_id AND c.post_id = $1
+	`, postID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE comments
+		SET deleted_at = NOW(), deleted_by = $2, delete_reason = 'split into ' || $3
+		WHERE comment_id = $1
+	`, commentID, deletedBy, postID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return postID, int(moved), tx.Commit()
+}
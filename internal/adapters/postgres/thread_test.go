@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestMerge_MovesNotificationsAndWatches(t *testing.T) {
+	// Both threads are found, the same row answers the opening post insert
+	conn := &fakeConn{rows: [][]driver.Value{{int64(2)}}}
+	repo := NewThreadRepository(sql.OpenDB(conn))
+
+	if _, err := repo.Merge(context.Background(), "p1", "p2", "m1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !conn.ran("UPDATE notifications") || !conn.ran("INSERT INTO thread_watches") || !conn.ran("DELETE FROM thread_watches") {
+		t.Fatalf("expected notifications and watches to follow the replies, got %q", conn.statements)
+	}
+	if !conn.committed {
+		t.Error("expected the merge to be committed")
+	}
+}
+
+func TestSplit_MovesNotifications(t *testing.T) {
+	conn := &fakeConn{rows: [][]driver.Value{{"p3"}}}
+	repo := NewThreadRepository(sql.OpenDB(conn))
+
+	if _, _, err := repo.Split(context.Background(), "c1", "split", "m1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !conn.ran("UPDATE notifications") || !conn.committed {
+		t.Fatalf("expected notifications to follow the replies, got %q", conn.statements)
+	}
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log/slog"
 
 	"1337b04rd/internal/domain"
@@ -30,8 +31,8 @@ func (r *UserRepository) Save(ctx context.Context, avatarURL string, name string
 
 	query := `
         INSERT INTO user_sessions (
-            avatar_url, username
-        ) VALUES ($1, $2)
+            avatar_url, username, username_key
+        ) VALUES ($1, $2, $3)
         RETURNING session_id
     `
 
@@ -42,6 +43,7 @@ func (r *UserRepository) Save(ctx context.Context, avatarURL string, name string
 	err = tx.QueryRowContext(ctx, query,
 		avatarURL,
 		name,
+		domain.UsernameKey(name),
 	).Scan(
 		&user.SessionID, // Populate the generated UUID
 	)
@@ -64,7 +66,7 @@ func (r *UserRepository) ChangeName(ctx context.Context, newName string, session
 
 	query := `
         UPDATE user_sessions
-		SET username = $1
+		SET username = $1, username_key = $3
 		WHERE session_id = $2
 		RETURNING 
 			session_id,
@@ -79,6 +81,7 @@ func (r *UserRepository) ChangeName(ctx context.Context, newName string, session
 	err = tx.QueryRowContext(ctx, query,
 		newName,
 		sessionID,
+		domain.UsernameKey(newName),
 	).Scan(
 		&user.SessionID,
 		&user.AvatarURL,
@@ -87,6 +90,9 @@ func (r *UserRepository) ChangeName(ctx context.Context, newName string, session
 		&user.ExpiresAt,
 	)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNotFound
+		}
 		slog.Error("Postgres, error when changing username:", "error", err)
 		return err
 	}
@@ -94,16 +100,6 @@ func (r *UserRepository) ChangeName(ctx context.Context, newName string, session
 	return tx.Commit()
 }
 
-func (r *UserRepository) GetNumberOfUsers(ctx context.Context) (int, error) {
-	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM user_sessions").Scan(&count)
-	if err != nil {
-		return -1, err
-	}
-
-	return count, nil
-}
-
 func (r *UserRepository) FindByID(ctx context.Context, session_id string) (*domain.User, error) {
 	query := `SELECT 
 	session_id,
@@ -125,8 +121,94 @@ func (r *UserRepository) FindByID(ctx context.Context, session_id string) (*doma
 		&user.ExpiresAt,
 	)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 
 	return &user, nil
 }
+
+func (r *UserRepository) UsernameTaken(ctx context.Context, usernameKey string, exceptSessionID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_sessions
+			WHERE username_key = $1
+			AND session_id <> $2
+			AND expires_at > NOW()
+		)
+	`
+
+	var taken bool
+	err := r.db.QueryRowContext(ctx, query, usernameKey, exceptSessionID).Scan(&taken)
+	if err != nil {
+		return false, err
+	}
+
+	return taken, nil
+}
+
+func (r *UserRepository) SaveRecoveryKey(ctx context.Context, sessionID string, keyHash string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Only the latest phrase of a session stays valid
+	_, err = tx.ExecContext(ctx, `
+		UPDATE session_recovery_keys
+		SET revoked_at = NOW()
+		WHERE session_id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`, sessionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO session_recovery_keys (session_id, key_hash)
+		VALUES ($1, $2)
+	`, sessionID, keyHash)
+	if err != nil {
+		slog.Error("Postgres, error when saving recovery key:", "error", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *UserRepository) UseRecoveryKey(ctx context.Context, keyHash string) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var sessionID string
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE session_recovery_keys
+		SET used_at = NOW()
+		WHERE key_hash = $1 AND used_at IS NULL AND revoked_at IS NULL
+		RETURNING session_id
+	`, keyHash).Scan(&sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrInvalidRecoveryPhrase
+		}
+		return "", err
+	}
+
+	// Restored session gets a fresh week of life
+	_, err = tx.ExecContext(ctx, `
+		UPDATE user_sessions
+		SET expires_at = NOW() + INTERVAL '7 days'
+		WHERE session_id = $1
+	`, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	return sessionID, tx.Commit()
+}
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+)
+
+type WatchRepository struct {
+	db *sql.DB
+}
+
+var _ domain.WatchRepository = (*WatchRepository)(nil)
+
+func NewWatchRepository(db *sql.DB) *WatchRepository {
+	return &WatchRepository{
+		db: db,
+	}
+}
+
+// The row of the session is locked first, so that two requests of one
+// session can not both take the last free place
+
+func (r *WatchRepository) Watch(ctx context.Context, sessionID string, postID string, limit int) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var locked int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM user_sessions WHERE session_id = $1 FOR UPDATE`, sessionID).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, domain.ErrNotFound
+		}
+		return false, err
+	}
+
+	query := `
+		SELECT COALESCE(bool_or(post_id = $2), FALSE), COUNT(*)
+		FROM thread_watches
+		WHERE session_id = $1
+	`
+
+	var watched bool
+	var count int
+	if err := tx.QueryRowContext(ctx, query, sessionID, postID).Scan(&watched, &count); err != nil {
+		return false, err
+	}
+	if watched {
+		return true, nil
+	}
+	if count >= limit {
+		return false, nil
+	}
+
+	query = `
+		INSERT INTO thread_watches (session_id, post_id)
+		VALUES ($1, $2)
+		ON CONFLICT (session_id, post_id) DO NOTHING
+	`
+
+	if _, err := tx.ExecContext(ctx, query, sessionID, postID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *WatchRepository) Unwatch(ctx context.Context, sessionID string, postID string) error {
+	query := `
+		DELETE FROM thread_watches
+		WHERE session_id = $1 AND post_id = $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, sessionID, postID)
+	return err
+}
+
+func (r *WatchRepository) MarkViewed(ctx context.Context, sessionID string, postID string) error {
+	query := `
+		UPDATE thread_watches
+		SET last_viewed_at = NOW()
+		WHERE session_id::text = $1 AND post_id = $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, sessionID, postID)
+	return err
+}
+
+// Replies are counted per watched thread in one pass over its comments
+// through idx_comments_post_created. Only replies the session could see are
+// counted: published ones, minus those of shadow-banned sessions other than
+// itself. Threads with new replies come first.
+
+func (r *WatchRepository) ListBySession(ctx context.Context, sessionID string) ([]*domain.WatchedThread, error) {
+	query := `
+		SELECT p.post_id, p.board, p.title, p.is_archived, p.locked,
+			w.created_at, w.last_viewed_at,
+			r.last_seen, r.replies, r.new_replies, r.last_reply_at
+		FROM thread_watches w
+		JOIN posts p ON p.post_id = w.post_id AND p.deleted_at IS NULL
+		CROSS JOIN LATERAL (
+			SELECT
+				(array_agg(c.comment_id::text ORDER BY c.created_at DESC)
+					FILTER (WHERE c.created_at <= w.last_viewed_at))[1] AS last_seen,
+				COUNT(c.comment_id) AS replies,
+				COUNT(c.comment_id) FILTER (
+					WHERE c.created_at > w.last_viewed_at
+					AND c.session_id IS DISTINCT FROM w.session_id
+				) AS new_replies,
+				MAX(c.created_at) AS last_reply_at
+			FROM comments c
+			LEFT JOIN user_sessions u ON u.session_id = c.session_id
+			WHERE c.post_id = w.post_id
+			AND c.deleted_at IS NULL AND c.status = 'published'
+			AND (u.shadow_banned IS NOT TRUE OR c.session_id = w.session_id)
+		) r
+		WHERE w.session_id = $1
+		ORDER BY r.new_replies > 0 DESC, COALESCE(r.last_reply_at, p.created_at) DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []*domain.WatchedThread
+	for rows.Next() {
+		var thread domain.WatchedThread
+		var lastSeen sql.NullString
+		var lastReplyAt sql.NullTime
+		if err := rows.Scan(
+			&thread.PostID,
+			&thread.Board,
+			&thread.Title,
+			&thread.Archived,
+			&thread.Locked,
+			&thread.WatchedAt,
+			&thread.LastViewedAt,
+			&lastSeen,
+			&thread.Replies,
+			&thread.NewReplies,
+			&lastReplyAt,
+		); err != nil {
+			return nil, err
+		}
+		if lastSeen.Valid {
+			thread.LastSeenCommentID = &lastSeen.String
+		}
+		if lastReplyAt.Valid {
+			thread.LastReplyAt = &lastReplyAt.Time
+		}
+		threads = append(threads, &thread)
+	}
+
+	return threads, rows.Err()
+}
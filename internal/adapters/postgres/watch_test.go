@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+func TestWatch_UnknownSession(t *testing.T) {
+	conn := &fakeConn{}
+	repo := NewWatchRepository(sql.OpenDB(conn))
+
+	_, err := repo.Watch(context.Background(), "s1", "p1", 10)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if conn.ran("INSERT INTO thread_watches") || conn.committed {
+		t.Error("expected nothing to be written")
+	}
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"1337b04rd/internal/domain"
+)
+
+type WordFilterRepository struct {
+	db *sql.DB
+}
+
+var _ domain.WordFilterRepository = (*WordFilterRepository)(nil)
+
+func NewWordFilterRepository(db *sql.DB) *WordFilterRepository {
+	return &WordFilterRepository{
+		db: db,
+	}
+}
+
+const wordFilterColumns = `
+	filter_id, board, pattern, is_regex, action,
+	replacement, message, COALESCE(created_by::text, ''), created_at
+`
+
+func (r *WordFilterRepository) Create(ctx context.Context, filter *domain.WordFilter) (string, error) {
+	query := `
+		INSERT INTO word_filters (board, pattern, is_regex, action, replacement, message, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING filter_id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		filter.Board,
+		filter.Pattern,
+		filter.IsRegex,
+		filter.Action,
+		filter.Replacement,
+		filter.Message,
+		filter.CreatedBy,
+	).Scan(
+		&filter.ID,
+		&filter.CreatedAt,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return filter.ID, nil
+}
+
+func (r *WordFilterRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM word_filters WHERE filter_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *WordFilterRepository) FindByID(ctx context.Context, id string) (*domain.WordFilter, error) {
+	query := `SELECT ` + wordFilterColumns + ` FROM word_filters WHERE filter_id = $1`
+
+	filter, err := scanWordFilter(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return filter, err
+}
+
+// Rules are applied in the order they were created
+
+func (r *WordFilterRepository) List(ctx context.Context) ([]*domain.WordFilter, error) {
+	query := `SELECT ` + wordFilterColumns + ` FROM word_filters ORDER BY created_at, filter_id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var filters []*domain.WordFilter
+	for rows.Next() {
+		filter, err := scanWordFilter(rows)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, rows.Err()
+}
+
+func scanWordFilter(row rowScanner) (*domain.WordFilter, error) {
+	var filter domain.WordFilter
+
+	err := row.Scan(
+		&filter.ID,
+		&filter.Board,
+		&filter.Pattern,
+		&filter.IsRegex,
+		&filter.Action,
+		&filter.Replacement,
+		&filter.Message,
+		&filter.CreatedBy,
+		&filter.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
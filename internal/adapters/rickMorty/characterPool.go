@@ -0,0 +1,142 @@
+package rickMorty
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// CharacterPool caches characters of the Rick & Morty API. The pool is loaded
+// from the database on start and filled in the background, so a new visitor
+// only hits the API when their character is not cached yet.
+type CharacterPool struct {
+	api             *RickMortyAPI
+	repo            domain.CharacterPoolRepository
+	refreshInterval time.Duration
+	pageDelay       time.Duration
+
+	mu         sync.RWMutex
+	characters map[int]*domain.Character
+}
+
+var _ domain.UserOutlookAPI = (*CharacterPool)(nil)
+
+func NewCharacterPool(api *RickMortyAPI, repo domain.CharacterPoolRepository, refreshInterval time.Duration) *CharacterPool {
+	return &CharacterPool{
+		api:             api,
+		repo:            repo,
+		refreshInterval: refreshInterval,
+		pageDelay:       500 * time.Millisecond,
+		characters:      make(map[int]*domain.Character),
+	}
+}
+
+// Start loads the persisted pool and runs the background refresh until the
+// context is cancelled.
+
+func (p *CharacterPool) Start(ctx context.Context) {
+	characters, err := p.repo.LoadCharacters(ctx)
+	if err != nil {
+		slog.Error("Failed to load character pool", "error", err)
+	}
+	p.add(characters)
+	slog.Info("Loaded character pool", "size", p.size())
+
+	go p.refreshLoop(ctx)
+}
+
+func (p *CharacterPool) GenerateAvatarAndName(id int) (*domain.UserOutlook, error) {
+	id = characterID(id)
+
+	p.mu.RLock()
+	character, ok := p.characters[id]
+	p.mu.RUnlock()
+	if ok {
+		userOutlook := character.UserOutlook
+		return &userOutlook, nil
+	}
+
+	userOutlook, err := p.api.GenerateAvatarAndName(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fetched := []*domain.Character{{ID: id, UserOutlook: *userOutlook}}
+	p.add(fetched)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.repo.SaveCharacters(ctx, fetched); err != nil {
+		slog.Error("Failed to persist character", "id", id, "error", err)
+	}
+
+	return userOutlook, nil
+}
+
+// Fill the pool right away when it is incomplete, then refresh it every interval
+
+func (p *CharacterPool) refreshLoop(ctx context.Context) {
+	if p.size() < CharacterCount {
+		p.refresh(ctx)
+	}
+
+	ticker := time.NewTicker(p.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.refresh(ctx)
+		}
+	}
+}
+
+func (p *CharacterPool) refresh(ctx context.Context) {
+	slog.Info("Refreshing character pool")
+
+	for page, pages := 1, 1; page <= pages; page++ {
+		characters, total, err := p.api.FetchPage(page)
+		if err != nil {
+			slog.Warn("Failed to fetch characters page, will retry later", "page", page, "error", err)
+			return
+		}
+		pages = total
+
+		p.add(characters)
+		if err := p.repo.SaveCharacters(ctx, characters); err != nil {
+			slog.Error("Failed to persist characters", "page", page, "error", err)
+		}
+
+		// Be gentle with the public API
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.pageDelay):
+		}
+	}
+
+	slog.Info("Refreshed character pool", "size", p.size())
+}
+
+func (p *CharacterPool) add(characters []*domain.Character) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, character := range characters {
+		if character == nil || character.Name == "" || character.AvatarURL == "" {
+			continue
+		}
+		p.characters[character.ID] = character
+	}
+}
+
+func (p *CharacterPool) size() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.characters)
+}
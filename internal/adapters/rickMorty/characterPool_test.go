@@ -0,0 +1,155 @@
+package rickMorty
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// countingTransport answers like the API and counts the requests
+type countingTransport struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, req.URL.String())
+	c.mu.Unlock()
+
+	body := `{"id": 7, "name": "Abradolf Lincler", "image": "7.jpeg"}`
+	if strings.Contains(req.URL.RawQuery, "page=") {
+		body = `{"info": {"pages": 1}, "results": [
+			{"id": 1, "name": "Rick Sanchez", "image": "1.jpeg"},
+			{"id": 2, "name": "Morty Smith", "image": "2.jpeg"}
+		]}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func (c *countingTransport) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.requests)
+}
+
+type fakeCharacterRepo struct {
+	mu     sync.Mutex
+	stored map[int]*domain.Character
+}
+
+func (f *fakeCharacterRepo) LoadCharacters(ctx context.Context) ([]*domain.Character, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var characters []*domain.Character
+	for _, c := range f.stored {
+		characters = append(characters, c)
+	}
+	return characters, nil
+}
+
+func (f *fakeCharacterRepo) SaveCharacters(ctx context.Context, characters []*domain.Character) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range characters {
+		f.stored[c.ID] = c
+	}
+	return nil
+}
+
+func TestCharacterPool_ServesPersistedCharacters(t *testing.T) {
+	transport := &countingTransport{}
+	repo := &fakeCharacterRepo{stored: map[int]*domain.Character{
+		3: {ID: 3, UserOutlook: domain.UserOutlook{Name: "Summer Smith", AvatarURL: "3.jpeg"}},
+	}}
+
+	pool := NewCharacterPool(NewRickMortyAPIWithClient(&http.Client{Transport: transport}), repo, time.Hour)
+
+	// Load the persisted pool without starting the background refresh
+	pool.add(mustLoad(t, repo))
+
+	outlook, err := pool.GenerateAvatarAndName(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outlook.Name != "Summer Smith" {
+		t.Errorf("expected Summer Smith, got %s", outlook.Name)
+	}
+	if transport.count() != 0 {
+		t.Errorf("expected no API calls for a cached character, got %d", transport.count())
+	}
+}
+
+func TestCharacterPool_MissFetchesAndPersists(t *testing.T) {
+	transport := &countingTransport{}
+	repo := &fakeCharacterRepo{stored: map[int]*domain.Character{}}
+
+	pool := NewCharacterPool(NewRickMortyAPIWithClient(&http.Client{Transport: transport}), repo, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		outlook, err := pool.GenerateAvatarAndName(7)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outlook.Name != "Abradolf Lincler" {
+			t.Errorf("expected Abradolf Lincler, got %s", outlook.Name)
+		}
+	}
+
+	if transport.count() != 1 {
+		t.Errorf("expected a single API call, got %d", transport.count())
+	}
+	if repo.stored[7] == nil {
+		t.Error("expected fetched character to be persisted")
+	}
+}
+
+func TestCharacterPool_PrefetchesInBackground(t *testing.T) {
+	transport := &countingTransport{}
+	repo := &fakeCharacterRepo{stored: map[int]*domain.Character{}}
+
+	pool := NewCharacterPool(NewRickMortyAPIWithClient(&http.Client{Transport: transport}), repo, time.Hour)
+	pool.pageDelay = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.size() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if pool.size() != 2 {
+		t.Fatalf("expected 2 prefetched characters, got %d", pool.size())
+	}
+
+	before := transport.count()
+	if _, err := pool.GenerateAvatarAndName(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transport.count() != before {
+		t.Error("expected prefetched character to be served from the pool")
+	}
+}
+
+func mustLoad(t *testing.T, repo domain.CharacterPoolRepository) []*domain.Character {
+	t.Helper()
+	characters, err := repo.LoadCharacters(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return characters
+}
@@ -1,15 +1,24 @@
 package rickMorty
 
 import (
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"time"
 
 	"1337b04rd/internal/domain"
 )
 
+const (
+	apiURL = "https://rickandmortyapi.com/api/character"
+
+	// Number of characters available in the API, MAXVALUE of
+	// avatar_character_seq in init.sql has to match it
+	CharacterCount = 826
+)
+
 type RickMortyAPI struct {
 	client *http.Client
 }
@@ -17,7 +26,25 @@ type RickMortyAPI struct {
 var _ domain.UserOutlookAPI = (*RickMortyAPI)(nil)
 
 func NewRickMortyAPI() *RickMortyAPI {
-	return &RickMortyAPI{}
+	// One client for all requests so connections are reused
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   3 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   3 * time.Second,
+		ResponseHeaderTimeout: 5 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConnsPerHost:   4,
+	}
+
+	return &RickMortyAPI{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   10 * time.Second,
+		},
+	}
 }
 
 func NewRickMortyAPIWithClient(client *http.Client) *RickMortyAPI {
@@ -27,39 +54,65 @@ func NewRickMortyAPIWithClient(client *http.Client) *RickMortyAPI {
 }
 
 func (r *RickMortyAPI) GenerateAvatarAndName(id int) (*domain.UserOutlook, error) {
-	if id > 826 {
-		id %= 826
-	}
+	apiReq := apiURL + "/" + fmt.Sprint(characterID(id))
 
-	if id == 0 {
-		id = 1
+	var userOutlook domain.UserOutlook
+	if err := r.get(apiReq, &userOutlook); err != nil {
+		return nil, err
 	}
 
-	apiReq := "https://rickandmortyapi.com/api/character/" + fmt.Sprint(id)
+	return &userOutlook, nil
+}
+
+// Page of the character list, the API returns 20 characters per page
+
+type characterPage struct {
+	Info struct {
+		Pages int `json:"pages"`
+	} `json:"info"`
+	Results []*domain.Character `json:"results"`
+}
+
+// FetchPage returns the characters of the page and the total number of pages
 
-	// Create a custom transport with TLS config that skips verification
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+func (r *RickMortyAPI) FetchPage(page int) ([]*domain.Character, int, error) {
+	var result characterPage
+	if err := r.get(apiURL+"?page="+fmt.Sprint(page), &result); err != nil {
+		return nil, 0, err
 	}
 
-	// Create an HTTP client with the custom transport
-	client := &http.Client{Transport: tr}
-	response, err := client.Get(apiReq)
+	return result.Results, result.Info.Pages, nil
+}
+
+func (r *RickMortyAPI) get(url string, dest interface{}) error {
+	response, err := r.client.Get(url)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer response.Body.Close()
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var userOutlook domain.UserOutlook
-	err = json.Unmarshal(body, &userOutlook)
-	if err != nil {
-		return nil, err
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("rick and morty api responded with status %d", response.StatusCode)
 	}
 
-	return &userOutlook, nil
+	return json.Unmarshal(body, dest)
+}
+
+// Map any positive number onto the range of existing characters
+
+func characterID(id int) int {
+	if id > CharacterCount {
+		id %= CharacterCount
+	}
+
+	if id == 0 {
+		id = 1
+	}
+
+	return id
 }
@@ -0,0 +1,247 @@
+package scrypt
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"1337b04rd/internal/domain"
+)
+
+// Scrypt hashes passwords with scrypt (RFC 7914). Hashes are stored together
+// with their parameters, so the cost can be raised without breaking old ones:
+//
+//	$scrypt$ln=15,r=8,p=1$<salt>$<key>
+type Scrypt struct {
+	logN   int
+	r      int
+	p      int
+	keyLen int
+}
+
+var _ domain.PasswordHasher = (*Scrypt)(nil)
+
+// Parameters recommended for interactive logins: 32 MB of memory per hash
+func NewScrypt() *Scrypt {
+	return &Scrypt{
+		logN:   15,
+		r:      8,
+		p:      1,
+		keyLen: 32,
+	}
+}
+
+func (s *Scrypt) Hash(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := Key([]byte(password), salt, 1<<s.logN, s.r, s.p, s.keyLen)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+		s.logN, s.r, s.p,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (s *Scrypt) Compare(hash string, password string) error {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 || parts[1] != "scrypt" {
+		return errors.New("unknown password hash format")
+	}
+
+	var logN, r, p int
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &logN, &r, &p); err != nil {
+		return fmt.Errorf("invalid scrypt parameters: %w", err)
+	}
+	if logN < 1 || logN > 30 {
+		return errors.New("invalid scrypt parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return err
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return err
+	}
+
+	key, err := Key([]byte(password), salt, 1<<logN, r, p, len(expected))
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare(key, expected) != 1 {
+		return domain.ErrInvalidCredentials
+	}
+	return nil
+}
+
+// Key derives a key of keyLen bytes. N is the CPU/memory cost and must be a
+// power of two, r is the block size and p the parallelization.
+func Key(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
+	if N <= 1 || N&(N-1) != 0 {
+		return nil, errors.New("scrypt: N must be a power of two greater than 1")
+	}
+	if r <= 0 || p <= 0 || uint64(r)*uint64(p) >= 1<<30 || r > (1<<31-1)/128/p || N > (1<<31-1)/128/r {
+		return nil, errors.New("scrypt: parameters are too large")
+	}
+
+	b := pbkdf2SHA256(password, salt, 1, p*128*r)
+
+	v := make([]uint32, 32*r*N)
+	x := make([]uint32, 32*r)
+	y := make([]uint32, 32*r)
+	for i := 0; i < p; i++ {
+		roMix(b[i*128*r:(i+1)*128*r], r, N, v, x, y)
+	}
+
+	return pbkdf2SHA256(password, b, 1, keyLen), nil
+}
+
+// ROMix of RFC 7914 section 5, works on one 128*r byte block in place
+
+func roMix(b []byte, r, N int, v, x, y []uint32) {
+	words := 32 * r
+
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+
+	for i := 0; i < N; i++ {
+		copy(v[i*words:], x)
+		blockMix(x, y, r)
+		x, y = y, x
+	}
+
+	for i := 0; i < N; i++ {
+		// Integerify: first word of the last 64 byte block
+		j := int(x[(2*r-1)*16] & uint32(N-1))
+		for k := range x {
+			x[k] ^= v[j*words+k]
+		}
+		blockMix(x, y, r)
+		x, y = y, x
+	}
+
+	for i, w := range x {
+		binary.LittleEndian.PutUint32(b[i*4:], w)
+	}
+}
+
+// BlockMix of RFC 7914 section 4, the even blocks go to the first half of the
+// output and the odd ones to the second half
+
+func blockMix(in, out []uint32, r int) {
+	var block [16]uint32
+	copy(block[:], in[(2*r-1)*16:])
+
+	for i := 0; i < 2*r; i++ {
+		for k := range block {
+			block[k] ^= in[i*16+k]
+		}
+		salsa208(&block)
+
+		dst := (i/2)*16 + (i%2)*r*16
+		copy(out[dst:], block[:])
+	}
+}
+
+// Salsa20/8 core
+
+func salsa208(b *[16]uint32) {
+	x := *b
+
+	qr := func(a, b, c int, shift int) {
+		x[a] ^= bits.RotateLeft32(x[b]+x[c], shift)
+	}
+
+	for i := 0; i < 8; i += 2 {
+		// Columns
+		qr(4, 0, 12, 7)
+		qr(8, 4, 0, 9)
+		qr(12, 8, 4, 13)
+		qr(0, 12, 8, 18)
+		qr(9, 5, 1, 7)
+		qr(13, 9, 5, 9)
+		qr(1, 13, 9, 13)
+		qr(5, 1, 13, 18)
+		qr(14, 10, 6, 7)
+		qr(2, 14, 10, 9)
+		qr(6, 2, 14, 13)
+		qr(10, 6, 2, 18)
+		qr(3, 15, 11, 7)
+		qr(7, 3, 15, 9)
+		qr(11, 7, 3, 13)
+		qr(15, 11, 7, 18)
+
+		// Rows
+		qr(1, 0, 3, 7)
+		qr(2, 1, 0, 9)
+		qr(3, 2, 1, 13)
+		qr(0, 3, 2, 18)
+		qr(6, 5, 4, 7)
+		qr(7, 6, 5, 9)
+		qr(4, 7, 6, 13)
+		qr(5, 4, 7, 18)
+		qr(11, 10, 9, 7)
+		qr(8, 11, 10, 9)
+		qr(9, 8, 11, 13)
+		qr(10, 9, 8, 18)
+		qr(12, 15, 14, 7)
+		qr(13, 12, 15, 9)
+		qr(14, 13, 12, 13)
+		qr(15, 14, 13, 18)
+	}
+
+	for i := range b {
+		b[i] += x[i]
+	}
+}
+
+// PBKDF2 with HMAC-SHA256 (RFC 8018)
+
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	key := make([]byte, 0, blocks*hashLen)
+	u := make([]byte, hashLen)
+	var counter [4]byte
+
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		key = prf.Sum(key)
+
+		t := key[len(key)-hashLen:]
+		copy(u, t)
+
+		for n := 2; n <= iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range u {
+				t[i] ^= u[i]
+			}
+		}
+	}
+
+	return key[:keyLen]
+}
@@ -0,0 +1,97 @@
+package scrypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// Test vectors from RFC 7914 section 12
+func TestKey_RFCVectors(t *testing.T) {
+	tests := []struct {
+		password string
+		salt     string
+		N, r, p  int
+		expected string
+	}{
+		{
+			password: "",
+			salt:     "",
+			N:        16, r: 1, p: 1,
+			expected: "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442" +
+				"fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906",
+		},
+		{
+			password: "password",
+			salt:     "NaCl",
+			N:        1024, r: 8, p: 16,
+			expected: "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+				"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640",
+		},
+	}
+
+	for _, tt := range tests {
+		key, err := Key([]byte(tt.password), []byte(tt.salt), tt.N, tt.r, tt.p, 64)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if hex.EncodeToString(key) != tt.expected {
+			t.Errorf("scrypt(%q, %q) = %x, expected %s", tt.password, tt.salt, key, tt.expected)
+		}
+	}
+}
+
+// Test vector from RFC 7914 section 11
+func TestPBKDF2SHA256(t *testing.T) {
+	key := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if hex.EncodeToString(key) != expected {
+		t.Errorf("got %x, expected %s", key, expected)
+	}
+}
+
+func TestKey_InvalidParameters(t *testing.T) {
+	if _, err := Key([]byte("p"), []byte("s"), 1000, 8, 1, 32); err == nil {
+		t.Error("expected error for N that is not a power of two")
+	}
+	if _, err := Key([]byte("p"), []byte("s"), 16, 0, 1, 32); err == nil {
+		t.Error("expected error for r = 0")
+	}
+}
+
+func TestScrypt_HashAndCompare(t *testing.T) {
+	// Cheap parameters to keep the test fast
+	hasher := &Scrypt{logN: 10, r: 8, p: 1, keyLen: 32}
+
+	hash, err := hasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$scrypt$ln=10,r=8,p=1$") {
+		t.Errorf("unexpected hash format: %s", hash)
+	}
+
+	if err := hasher.Compare(hash, "correct horse battery staple"); err != nil {
+		t.Errorf("expected password to match, got %v", err)
+	}
+
+	if err := hasher.Compare(hash, "wrong password"); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	other, err := hasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other == hash {
+		t.Error("expected different salts for two hashes")
+	}
+
+	if err := hasher.Compare("$bcrypt$whatever", "x"); err == nil {
+		t.Error("expected error for unknown hash format")
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Targets that only show up in the audit log
+const (
+	TargetBan     TargetType = "ban"
+	TargetSession TargetType = "session"
+	TargetFilter  TargetType = "filter"
+	TargetBoard   TargetType = "board"
+	TargetLink    TargetType = "link_rule"
+)
+
+// Record of a moderator action, entries are never changed once written
+type AuditEntry struct {
+	ID          int64           `json:"id"`
+	ModeratorID string          `json:"moderator_id"`
+	Moderator   string          `json:"moderator"` // Username at the time of the action
+	Action      string          `json:"action"`
+	TargetType  TargetType      `json:"target_type"`
+	TargetID    string          `json:"target_id"`
+	Reason      string          `json:"reason"`
+	Before      json.RawMessage `json:"before,omitempty"` // Snapshot of the target before the action
+	After       json.RawMessage `json:"after,omitempty"`  // Snapshot of the target after the action
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// Default and maximal number of entries returned by one audit query
+const (
+	AuditPageSize    = 50
+	AuditMaxPageSize = 500
+)
+
+// Zero fields do not filter
+type AuditFilter struct {
+	ModeratorID string
+	Action      string
+	TargetType  TargetType
+	TargetID    string
+	Since       *time.Time
+	Until       *time.Time
+	Limit       int // Ignored by exports, they return every match
+	Offset      int
+}
+
+type AuditRepository interface {
+	Append(ctx context.Context, entry *AuditEntry) error
+	Find(ctx context.Context, filter *AuditFilter) ([]*AuditEntry, error)
+	Each(ctx context.Context, filter *AuditFilter, fn func(*AuditEntry) error) error
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type BanKind string
+
+const (
+	BanSession BanKind = "session" // Value is the session ID
+	BanIP      BanKind = "ip"      // Value is an IPv4 or IPv6 address or CIDR range
+	BanImage   BanKind = "image"   // Value is the SHA-256 of the image file
+	BanPHash   BanKind = "phash"   // Value is the perceptual hash of the image, see FormatPHash
+)
+
+func (k BanKind) Valid() bool {
+	return k == BanSession || k == BanIP || k == BanImage || k == BanPHash
+}
+
+// Maximal length of the reason shown to the banned user
+const BanReasonMaxLength = 200
+
+type Ban struct {
+	ID        string     `json:"id"`
+	Kind      BanKind    `json:"kind"`
+	Value     string     `json:"value"`
+	Reason    string     `json:"reason"`               // Public, shown to the banned user
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Nil for permanent bans
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// What a banned user gets to know about their ban, returned as an error when
+// they try to post
+
+type BanNotice struct {
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (b *Ban) Notice() *BanNotice {
+	return &BanNotice{Reason: b.Reason, ExpiresAt: b.ExpiresAt}
+}
+
+func (n *BanNotice) Error() string {
+	if n.ExpiresAt == nil {
+		return fmt.Sprintf("you are permanently banned: %s", n.Reason)
+	}
+	return fmt.Sprintf("you are banned until %s: %s", n.ExpiresAt.Format(time.RFC3339), n.Reason)
+}
+
+type BanRepository interface {
+	Create(ctx context.Context, ban *Ban) (string, error)
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*Ban, error)
+	ListActive(ctx context.Context) ([]*Ban, error)
+	FindActive(ctx context.Context, sessionID string, ip string) (*Ban, error)
+	FindImageBan(ctx context.Context, sha256 string) (*Ban, error)
+	// FindPHashBans returns the active perceptual hash bans, uploads are
+	// compared against all of them
+	FindPHashBans(ctx context.Context) ([]*Ban, error)
+}
+
+type CreateBanReq struct {
+	Kind          BanKind `json:"kind"`
+	Value         string  `json:"value"`
+	Reason        string  `json:"reason"`
+	DurationHours int     `json:"duration_hours"` // 0 for a permanent ban
+}
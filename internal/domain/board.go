@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Board every thread is posted to unless another one is chosen, and the
+// wildcard used for settings and roles that apply to every board
+const (
+	DefaultBoard = "b"
+	AllBoards    = "*"
+)
+
+// ValidBoard reports whether the name can be used as a board: 1 to 16
+// lowercase latin letters or digits.
+func ValidBoard(board string) bool {
+	if len(board) == 0 || len(board) > 16 {
+		return false
+	}
+	for _, ch := range board {
+		if (ch < 'a' || ch > 'z') && (ch < '0' || ch > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Longest a board can keep new sessions in the approval queue, sessions
+// live a week
+const PremodMaxMinutes = 7 * 24 * 60
+
+// Longest window for repeated content, and the window of boards that never
+// set one
+const (
+	DuplicateMaxMinutes     = 7 * 24 * 60
+	DefaultDuplicateMinutes = 60
+)
+
+// Settings of one board, or defaults of every board when Board is AllBoards
+type BoardSettings struct {
+	Board string `json:"board"`
+	// Posts and comments of sessions younger than this wait for approval,
+	// 0 turns pre-moderation off
+	PremodMinutes int `json:"premod_minutes"`
+	// Text posted anywhere within this many minutes, or nearly the same
+	// text, is rejected. 0 turns the check off
+	DuplicateMinutes int `json:"duplicate_minutes"`
+	// Robot9000 rejects any text ever posted on the board before
+	Robot9000 bool `json:"robot9000"`
+	// Most links in one post or comment, 0 allows none
+	MaxLinks int `json:"max_links"`
+	// Sessions younger than this can not post links
+	LinkMinSessionMinutes int       `json:"link_min_session_minutes"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// Settings of a board nobody has configured
+func DefaultBoardSettings(board string) *BoardSettings {
+	return &BoardSettings{
+		Board:                 board,
+		DuplicateMinutes:      DefaultDuplicateMinutes,
+		MaxLinks:              DefaultMaxLinks,
+		LinkMinSessionMinutes: DefaultLinkMinSessionMinutes,
+	}
+}
+
+type BoardSettingsRepository interface {
+	// Find returns the settings of the board, falling back to the AllBoards
+	// row and then to DefaultBoardSettings
+	Find(ctx context.Context, board string) (*BoardSettings, error)
+	Save(ctx context.Context, settings *BoardSettings) error
+}
+
+type UpdateBoardSettingsReq struct {
+	PremodMinutes         int  `json:"premod_minutes"`
+	DuplicateMinutes      int  `json:"duplicate_minutes"`
+	Robot9000             bool `json:"robot9000"`
+	MaxLinks              int  `json:"max_links"`
+	LinkMinSessionMinutes int  `json:"link_min_session_minutes"`
+}
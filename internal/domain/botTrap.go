@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// Why a submission was taken for a bot
+type BotSignal string
+
+const (
+	BotHoneypot  BotSignal = "honeypot"   // A hidden field was filled in
+	BotFormToken BotSignal = "form_token" // The signed form timestamp is missing, forged or of another session
+	BotTooFast   BotSignal = "too_fast"   // The form was sent sooner than a person can type
+	BotHeaders   BotSignal = "headers"    // The request does not look like it came from a browser
+)
+
+// What happens to submissions of bots. Dropped ones get a normal looking
+// response and are not saved, shadow-banned ones are saved but only shown to
+// the bot itself.
+type BotAction string
+
+const (
+	BotActionOff       BotAction = "off"
+	BotActionDrop      BotAction = "drop"
+	BotActionShadowBan BotAction = "shadowban"
+)
+
+func (a BotAction) Valid() bool {
+	return a == BotActionOff || a == BotActionDrop || a == BotActionShadowBan
+}
+
+// Form fields hidden from people by the pages, bots fill them in
+var HoneypotFields = []string{"email", "website"}
+
+// Submissions faster than DefaultMinFormFillTime after the form token was
+// issued are taken for bots, tokens older than FormTokenTTL are refused
+const (
+	DefaultMinFormFillTime = 3 * time.Second
+	FormTokenTTL           = 24 * time.Hour
+)
+
+// Timestamp signed by the server for one session when the posting page is
+// loaded, every token can be sent with one post only
+type FormToken struct {
+	Token       string `json:"token"`
+	MinFillTime int    `json:"min_fill_seconds"`
+}
+
+// The parts of a posting request the bot heuristics look at
+type FormSubmission struct {
+	Honeypots      []string // Values of HoneypotFields
+	FormToken      string
+	UserAgent      string
+	AcceptLanguage string
+}
+
+// Bots caught by this instance since it started
+type BotStats struct {
+	Action  BotAction           `json:"action"`
+	Since   time.Time           `json:"since"`
+	Total   int64               `json:"total"`
+	Signals map[BotSignal]int64 `json:"signals"`
+}
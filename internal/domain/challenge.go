@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Proof-of-work challenge: the poster has to find a solution such that the
+// SHA-256 of "token:solution" starts with Difficulty zero bits
+type Challenge struct {
+	Token      string    `json:"token"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// How long a challenge can be solved and used
+const ChallengeTTL = 5 * time.Minute
+
+// Longest accepted solution, solvers only need a counter
+const ChallengeSolutionMaxLength = 64
+
+// Remembers used challenges until they expire, so every challenge pays for
+// one post only
+type ChallengeStore interface {
+	// Use marks the nonce as used and reports false when it already was
+	Use(ctx context.Context, nonce string, expiresAt time.Time) (bool, error)
+}
@@ -2,6 +2,9 @@ package domain
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"mime/multipart"
 	"time"
 )
@@ -14,10 +17,37 @@ type Comment struct {
 	Content   string
 	ImageURLs []string
 	CreatedAt time.Time
+	IsDeleted bool   // Tombstone: content and author are hidden, nesting stays
+	IP        string `json:"-"` // Address the comment was sent from, only for bans
+	Status    ContentStatus
+	// Why the comment waits for approval, empty when published
+	PendingReason string `json:"-"`
+
+	// Relative to the session loading the thread, the session ID itself is
+	// never sent with loaded comments
+	PosterID  string // Same for all posts of a session in the thread, see PosterID
+	IsYou     bool   // Posted by the requesting session
+	QuotesYou bool   // Replies to or quotes a post or comment of the requesting session
+}
+
+// Comments quote posts and comments of their thread by writing >> and the ID
+const QuotePrefix = ">>"
+
+// Hex digits of the thread poster ID
+const PosterIDLength = 8
+
+// PosterID returns the ID a session posts under in a thread. It is keyed by
+// a server secret, so it can not be matched against guessed session IDs or
+// followed from one thread to another.
+func PosterID(secret []byte, postID string, sessionID string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("poster:" + postID + ":" + sessionID))
+	return hex.EncodeToString(mac.Sum(nil))[:PosterIDLength]
 }
 
 type CreateCommentReq struct {
 	SessionID string
+	ClientIP  string
 	PostID    string
 	Content   string
 	ParentID  *string
@@ -26,6 +56,9 @@ type CreateCommentReq struct {
 
 type CommentRepository interface {
 	Save(ctx context.Context, comment *Comment) (string, error)
-	FindByPostID(ctx context.Context, postid string) ([]*Comment, error)
+	FindByID(ctx context.Context, id string) (*Comment, error)
+	FindByPostID(ctx context.Context, postid string, viewer string) ([]*Comment, error)
 	ExistByID(ctx context.Context, id string) bool
+	SoftDelete(ctx context.Context, id string, deletedBy string, reason string) error
+	PruneOldest(ctx context.Context, postID string, keep int) (int, error)
 }
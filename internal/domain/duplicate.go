@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Near-duplicate detection: texts of at least SimHashMinWords words whose
+// SimHashes differ in at most SimHashMaxDistance bits are the same text
+// with small edits
+const (
+	SimHashMinWords    = 8
+	SimHashMaxDistance = 6
+)
+
+// Texts of fewer words, like "lol" or "+1", can be repeated freely outside
+// of robot9000 boards
+const DuplicateMinWords = SimHashMinWords
+
+// Characters per SimHash feature
+const simHashShingle = 4
+
+// Most recent texts compared by SimHash, bounds the work of every post
+const SimHashScanLimit = 5000
+
+// Fingerprint of the text of a post or comment
+type Fingerprint struct {
+	Board     string
+	Hash      string // SHA-256 of the normalized text
+	SimHash   uint64 // 0 when the text is too short to compare
+	Words     int    // Words of the normalized text, not stored
+	CreatedAt time.Time
+}
+
+type FingerprintRepository interface {
+	Save(ctx context.Context, fingerprint *Fingerprint) error
+	// ExistsSince reports whether the hash was posted on any board after since
+	ExistsSince(ctx context.Context, hash string, since time.Time) (bool, error)
+	// ExistsOnBoard reports whether the hash was ever posted on the board
+	ExistsOnBoard(ctx context.Context, hash string, board string) (bool, error)
+	// SimHashesSince returns up to limit SimHashes posted after since, newest first
+	SimHashesSince(ctx context.Context, since time.Time, limit int) ([]uint64, error)
+}
+
+// NormalizeContent reduces text to what makes it the same text to a reader:
+// words of letters and digits folded like word filter input, separated by
+// single spaces. Spacing, punctuation and homoglyph tricks do not make a
+// repost new. Symbols are dropped before folding, so "lol!" stays "lol".
+func NormalizeContent(text string) string {
+	words := strings.Map(func(ch rune) rune {
+		if unicode.Is(unicode.Cf, ch) || unicode.Is(unicode.Mn, ch) || unicode.IsLetter(ch) || unicode.IsDigit(ch) {
+			return ch
+		}
+		return ' '
+	}, text)
+
+	folded, _ := FoldText(words)
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// NewFingerprint fingerprints text posted to the board, nil when nothing is
+// left after normalization
+func NewFingerprint(board string, text string) *Fingerprint {
+	normalized := NormalizeContent(text)
+	if normalized == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(normalized))
+	return &Fingerprint{
+		Board:   board,
+		Hash:    hex.EncodeToString(sum[:]),
+		SimHash: SimHash(normalized),
+		Words:   len(strings.Fields(normalized)),
+	}
+}
+
+// SimHash of normalized text over its character shingles, 0 for texts shorter than
+// SimHashMinWords
+func SimHash(normalized string) uint64 {
+	words := strings.Fields(normalized)
+	if len(words) < SimHashMinWords {
+		return 0
+	}
+
+	var weights [64]int
+	runes := []rune(normalized)
+	for i := 0; i+simHashShingle <= len(runes); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i : i+simHashShingle])))
+		feature := h.Sum64()
+		for bit := range 64 {
+			if feature&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var simHash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			simHash |= 1 << bit
+		}
+	}
+	return simHash
+}
+
+// NearDuplicate reports whether two SimHashes belong to nearly the same text
+func NearDuplicate(a, b uint64) bool {
+	if a == 0 || b == 0 {
+		return false
+	}
+	return bits.OnesCount64(a^b) <= SimHashMaxDistance
+}
@@ -5,4 +5,128 @@ import "errors"
 var (
 	ErrNotFound  = errors.New("invalid order ID")
 	ErrNotFound1 = errors.New("invalid order ID1")
+
+	ErrInvalidRecoveryPhrase = errors.New("invalid or already used recovery phrase")
+	ErrTooManyAttempts       = errors.New("too many attempts, try again later")
+
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrUnauthorized       = errors.New("moderator login required")
+	ErrForbidden          = errors.New("not enough permissions")
+)
+
+// PolicyError is returned when user input breaks one of the board rules.
+// Code is a stable identifier for clients, Message is shown to the user.
+type PolicyError struct {
+	Code    string
+	Message string
+}
+
+func (e *PolicyError) Error() string {
+	return e.Message
+}
+
+// Username policy
+var (
+	ErrUsernameTooShort     = &PolicyError{Code: "username_too_short", Message: "username is too short (min 2 characters)"}
+	ErrUsernameTooLong      = &PolicyError{Code: "username_too_long", Message: "username is too long (max 32 characters)"}
+	ErrUsernameInvalidChars = &PolicyError{Code: "username_invalid_chars", Message: "username may contain only letters, digits, spaces and - _ . '"}
+	ErrUsernameReserved     = &PolicyError{Code: "username_reserved", Message: "this username is reserved"}
+	ErrUsernameTaken        = &PolicyError{Code: "username_taken", Message: "this username is already used by another active session"}
+)
+
+// Moderator accounts
+var (
+	ErrModeratorExists   = &PolicyError{Code: "moderator_exists", Message: "moderator with this username already exists"}
+	ErrPasswordTooShort  = &PolicyError{Code: "password_too_short", Message: "password is too short (min 12 characters)"}
+	ErrInvalidRole       = &PolicyError{Code: "invalid_role", Message: "role must be one of janitor, moderator or admin"}
+	ErrInvalidBoard      = &PolicyError{Code: "invalid_board", Message: "board must be 1-16 lowercase letters or digits"}
+	ErrModeratorNameSize = &PolicyError{Code: "moderator_name_size", Message: "moderator username must be 3-32 characters"}
+	ErrInvalidPremod     = &PolicyError{Code: "invalid_premod", Message: "pre-moderation must be between 0 and 10080 minutes"}
+	ErrInvalidDuplicate  = &PolicyError{Code: "invalid_duplicate", Message: "duplicate window must be between 0 and 10080 minutes"}
+	ErrInvalidMaxLinks   = &PolicyError{Code: "invalid_max_links", Message: "max links must be between 0 and 100"}
+	ErrInvalidLinkAge    = &PolicyError{Code: "invalid_link_age", Message: "link session age must be between 0 and 10080 minutes"}
+)
+
+// Deletion
+var (
+	ErrDeleteWindowExpired = &PolicyError{Code: "delete_window_expired", Message: "posts can be deleted by their author only within 15 minutes"}
+)
+
+// Reports
+var (
+	ErrAlreadyReported       = &PolicyError{Code: "already_reported", Message: "you have already reported this"}
+	ErrInvalidReportTarget   = &PolicyError{Code: "invalid_report_target", Message: "target type must be post or comment"}
+	ErrInvalidReportCategory = &PolicyError{Code: "invalid_report_category", Message: "category must be one of spam, illegal, harassment, off_topic or other"}
+	ErrReportNoteTooLong     = &PolicyError{Code: "report_note_too_long", Message: "note is too long (max 500 characters)"}
+	ErrInvalidReportAction   = &PolicyError{Code: "invalid_report_action", Message: "action must be one of dismiss, delete or ban"}
+)
+
+// Bans
+var (
+	ErrInvalidBanKind     = &PolicyError{Code: "invalid_ban_kind", Message: "ban kind must be one of session, ip, image or phash"}
+	ErrInvalidBanValue    = &PolicyError{Code: "invalid_ban_value", Message: "ban value does not match its kind"}
+	ErrBanReasonRequired  = &PolicyError{Code: "ban_reason_required", Message: "bans need a public reason"}
+	ErrBanReasonTooLong   = &PolicyError{Code: "ban_reason_too_long", Message: "ban reason is too long (max 200 characters)"}
+	ErrInvalidBanDuration = &PolicyError{Code: "invalid_ban_duration", Message: "ban duration can not be negative"}
+)
+
+// Posts
+var (
+	ErrTitleTooShort   = &PolicyError{Code: "title_too_short", Message: "title too short"}
+	ErrContentTooShort = &PolicyError{Code: "content_too_short", Message: "content too short"}
+
+	ErrThreadLocked      = &PolicyError{Code: "thread_locked", Message: "thread is locked, new replies are not accepted"}
+	ErrInvalidThreadFlag = &PolicyError{Code: "invalid_thread_flag", Message: "thread flag must be one of sticky, locked or cyclical"}
+	ErrNotArchived       = &PolicyError{Code: "not_archived", Message: "thread is not archived"}
+	ErrNotPending        = &PolicyError{Code: "not_pending", Message: "this is not waiting for approval"}
+	ErrSameBoard         = &PolicyError{Code: "same_board", Message: "thread is already on this board"}
+	ErrMergeIntoItself   = &PolicyError{Code: "merge_into_itself", Message: "a thread can not be merged into itself"}
+	ErrTooManyWatched    = &PolicyError{Code: "too_many_watched", Message: "you can watch at most 100 threads, unwatch some first"}
+	ErrTooManyImages     = &PolicyError{Code: "too_many_images", Message: "too many images attached to one post"}
+)
+
+// Word filters
+var (
+	ErrInvalidFilterAction  = &PolicyError{Code: "invalid_filter_action", Message: "filter action must be one of replace, reject or hold"}
+	ErrInvalidFilterPattern = &PolicyError{Code: "invalid_filter_pattern", Message: "filter pattern must be a non-empty literal or regular expression that does not match empty text (max 200 characters)"}
+	ErrFilterMessageTooLong = &PolicyError{Code: "filter_message_too_long", Message: "filter message and replacement are limited to 200 characters"}
+
+	// Default message of reject rules without their own
+	ErrFilteredContent = &PolicyError{Code: "filtered_content", Message: "your post contains a filtered word"}
+)
+
+// Repeated content and spam
+var (
+	ErrDuplicateContent = &PolicyError{Code: "duplicate_content", Message: "this was already posted recently"}
+	ErrRobot9000        = &PolicyError{Code: "robot9000", Message: "this board only accepts original content, this was posted before"}
+	ErrSpamContent      = &PolicyError{Code: "spam_content", Message: "your post looks like spam"}
+)
+
+// Link rules
+var (
+	ErrInvalidLinkKind   = &PolicyError{Code: "invalid_link_kind", Message: "link rule kind must be allow or deny"}
+	ErrInvalidLinkDomain = &PolicyError{Code: "invalid_link_domain", Message: "link rule domain must be a domain name like example.com"}
+	ErrLinkRuleExists    = &PolicyError{Code: "link_rule_exists", Message: "this board already has a rule for this domain"}
+
+	// Posting, the messages of link violations name the offending domain or limit
+	ErrTooManyLinks    = &PolicyError{Code: "too_many_links", Message: "your post contains too many links"}
+	ErrLinkNotAllowed  = &PolicyError{Code: "link_not_allowed", Message: "links to this site are not allowed"}
+	ErrLinksNewSession = &PolicyError{Code: "links_new_session", Message: "new sessions can not post links yet"}
+)
+
+// Similar image search
+var (
+	ErrInvalidImageQuery    = &PolicyError{Code: "invalid_image_query", Message: "give the URL of an uploaded image or a perceptual hash of 16 hex digits"}
+	ErrInvalidImageDistance = &PolicyError{Code: "invalid_image_distance", Message: "distance must be between 0 and 20 bits"}
+	ErrImageNotHashed       = &PolicyError{Code: "image_not_hashed", Message: "this image has no perceptual hash"}
+)
+
+// Proof of work
+var (
+	ErrChallengeRequired = &PolicyError{Code: "challenge_required", Message: "solve the posting challenge first"}
+	ErrChallengeInvalid  = &PolicyError{Code: "challenge_invalid", Message: "posting challenge is invalid or expired, get a new one"}
+	ErrChallengeUsed     = &PolicyError{Code: "challenge_used", Message: "posting challenge was already used, get a new one"}
+	ErrChallengeUnsolved = &PolicyError{Code: "challenge_unsolved", Message: "posting challenge solution is wrong"}
+	ErrFormExpired       = &PolicyError{Code: "form_expired", Message: "this form is too old, reload the page"}
+	ErrFormUsed          = &PolicyError{Code: "form_used", Message: "this form was already sent, reload the page"}
 )
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"time"
+)
+
+// Perceptual hashing of images: visually similar images get hashes that
+// differ in few bits, even after resizing or re-encoding
+type PerceptualHasher interface {
+	// Hash fails for files that can not be decoded as an image
+	Hash(data []byte) (uint64, error)
+}
+
+// Uploads within PHashBanDistance bits of a banned perceptual hash are
+// banned as well. Similar image searches go up to PHashMaxDistance.
+const (
+	PHashBanDistance     = 8
+	PHashDefaultDistance = 10
+	PHashMaxDistance     = 20
+	SimilarImagesLimit   = 50
+)
+
+// Hashes of an uploaded image, kept with its URL
+type ImageHash struct {
+	URL    string
+	SHA256 string
+	PHash  *uint64 // Nil for formats the hasher can not decode
+}
+
+// Image found by a similar image search and where it was posted
+type SimilarImage struct {
+	URL        string     `json:"url"`
+	PHash      string     `json:"phash"`
+	Distance   int        `json:"distance"`
+	TargetType TargetType `json:"target_type,omitempty"` // Empty for uploads of posts that failed
+	TargetID   string     `json:"target_id,omitempty"`
+	Board      string     `json:"board,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ImageRepository interface {
+	Save(ctx context.Context, image *ImageHash) error
+	FindByURL(ctx context.Context, url string) (*ImageHash, error)
+	// FindSimilar returns up to limit images within maxDistance bits of
+	// phash, closest first
+	FindSimilar(ctx context.Context, phash uint64, maxDistance int, limit int) ([]*SimilarImage, error)
+}
+
+// Perceptual hashes are written as 16 hex digits
+func FormatPHash(phash uint64) string {
+	return fmt.Sprintf("%016x", phash)
+}
+
+func ParsePHash(value string) (uint64, bool) {
+	if len(value) != 16 {
+		return 0, false
+	}
+	phash, err := strconv.ParseUint(value, 16, 64)
+	return phash, err == nil
+}
+
+// Number of bits two perceptual hashes differ in
+func PHashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
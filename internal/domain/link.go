@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// What a link rule does with links to its domain and the subdomains of it
+type LinkRuleKind string
+
+const (
+	LinkAllow LinkRuleKind = "allow" // Once a board has allow rules, only their domains can be linked
+	LinkDeny  LinkRuleKind = "deny"  // Links to the domain are rejected, even when allowed
+)
+
+func (k LinkRuleKind) Valid() bool {
+	return k == LinkAllow || k == LinkDeny
+}
+
+// Defaults of boards that never set their link limits
+const (
+	DefaultMaxLinks              = 3
+	DefaultLinkMinSessionMinutes = 60
+	LinkMaxSessionMinutes        = 7 * 24 * 60
+	MaxLinksLimit                = 100
+)
+
+// Domain rule of one board, or of every board when Board is AllBoards
+type LinkRule struct {
+	ID        string       `json:"id"`
+	Board     string       `json:"board"`
+	Domain    string       `json:"domain"`
+	Kind      LinkRuleKind `json:"kind"`
+	CreatedBy string       `json:"created_by"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// Covers reports whether the rule applies to links to host
+func (r *LinkRule) Covers(host string) bool {
+	return host == r.Domain || strings.HasSuffix(host, "."+r.Domain)
+}
+
+type LinkRuleRepository interface {
+	Create(ctx context.Context, rule *LinkRule) (string, error)
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*LinkRule, error)
+	List(ctx context.Context) ([]*LinkRule, error)
+}
+
+type CreateLinkRuleReq struct {
+	Board  string       `json:"board"`
+	Domain string       `json:"domain"`
+	Kind   LinkRuleKind `json:"kind"`
+}
+
+// Links with a scheme, www. links and bare domains under common top level
+// domains, the way posters paste them. Bare names are limited to known TLDs
+// so dotted words like node.js are not counted as links.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'` + "`" + `]+` +
+	`|\b(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+(?:` + bareLinkTLDs + `)\b(?:[/:?#][^\s<>"'` + "`" + `]*)?`)
+
+const bareLinkTLDs = "com|net|org|info|biz|io|co|me|tv|cc|ws|to|ly|gg|xyz|top|site|online|club|shop|store|live|link|app|dev|ru|su|uk|de|fr|nl|pl|eu|us|ca|au|jp|cn|br|in|it|es"
+
+// ExtractLinkHosts returns the host of every link in text, lowercase and
+// without port, in the order they appear. The text is folded first so
+// fullwidth letters and invisible characters do not hide a link. Links that
+// do not parse are returned as an empty host so they still count against
+// the link limit.
+func ExtractLinkHosts(text string) []string {
+	var hosts []string
+	for _, link := range linkPattern.FindAllString(foldLinkText(text), -1) {
+		// Punctuation of the sentence around the link
+		link = strings.TrimRight(link, ".,;:!?)]}")
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		host := ""
+		if parsed, err := url.Parse(link); err == nil {
+			host = strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// Fullwidth forms and the ideographic full stop become ASCII, invisible
+// format characters and combining marks are dropped. Unlike FoldText digits
+// are kept, they are part of many domain names.
+func foldLinkText(text string) string {
+	var b strings.Builder
+	for _, ch := range text {
+		if unicode.Is(unicode.Cf, ch) || unicode.Is(unicode.Mn, ch) {
+			continue
+		}
+		switch {
+		case ch >= 0xFF01 && ch <= 0xFF5E:
+			ch -= 0xFEE0
+		case ch == 0x3002 || ch == 0xFF61:
+			ch = '.'
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+// Domain names of link rules: lowercase labels of letters, digits and
+// hyphens, at least two of them
+var linkDomainPattern = regexp.MustCompile(`^(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// NormalizeLinkDomain turns what a moderator typed into the domain of a
+// rule, "https://WWW.Example.com/" is "www.example.com". ok is false when
+// no valid domain is left.
+func NormalizeLinkDomain(domain string) (string, bool) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+3:]
+	}
+	if i := strings.IndexAny(domain, "/?#:"); i >= 0 {
+		domain = domain[:i]
+	}
+	domain = strings.TrimSuffix(domain, ".")
+
+	if len(domain) > 253 || !linkDomainPattern.MatchString(domain) {
+		return "", false
+	}
+	return domain, true
+}
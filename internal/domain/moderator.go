@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// Role of a moderator on a board. Every role includes the permissions of the
+// roles below it: admin > moderator > janitor.
+type Role string
+
+const (
+	RoleJanitor   Role = "janitor"   // Deletes posts and comments
+	RoleModerator Role = "moderator" // Bans, reports and thread flags
+	RoleAdmin     Role = "admin"     // Manages moderators and board settings
+)
+
+var roleRank = map[Role]int{
+	RoleJanitor:   1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+func (r Role) Valid() bool {
+	return roleRank[r] > 0
+}
+
+// Includes reports whether the role grants the permissions of the other one
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && roleRank[r] >= roleRank[other]
+}
+
+// Admin sessions live shorter than anonymous ones
+const ModeratorSessionTTL = 12 * time.Hour
+
+// How often expired admin sessions are deleted
+const ModeratorSessionPruneInterval = time.Hour
+
+// Minimal length of a moderator password
+const ModeratorPasswordMinLength = 12
+
+type BoardRole struct {
+	Board string `json:"board"`
+	Role  Role   `json:"role"`
+}
+
+type Moderator struct {
+	ID           string // UUID
+	Username     string
+	PasswordHash string `json:"-"`
+	Roles        []BoardRole
+	CreatedAt    time.Time
+}
+
+// HasRole reports whether the moderator has at least the role on the board,
+// either through a role on that board or through a role on all boards.
+func (m *Moderator) HasRole(board string, role Role) bool {
+	for _, r := range m.Roles {
+		if (r.Board == board || r.Board == AllBoards) && r.Role.Includes(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Actions the server takes on its own, like shadow-banning bots, are written
+// to the audit log under this moderator. It has no account and no roles.
+var SystemModerator = &Moderator{ID: "00000000-0000-0000-0000-000000000000", Username: "system"}
+
+type ModeratorRepository interface {
+	Create(ctx context.Context, moderator *Moderator) (string, error)
+	FindByID(ctx context.Context, id string) (*Moderator, error)
+	FindByUsername(ctx context.Context, username string) (*Moderator, error)
+	List(ctx context.Context) ([]*Moderator, error)
+	SetRoles(ctx context.Context, moderatorID string, roles []BoardRole) error
+	CreateSession(ctx context.Context, moderatorID string, tokenHash string, expiresAt time.Time) error
+	FindBySession(ctx context.Context, tokenHash string) (*Moderator, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+	// PruneExpiredSessions deletes the admin sessions that expired
+	PruneExpiredSessions(ctx context.Context) (int, error)
+}
+
+// Hashing of moderator passwords. Compare returns ErrInvalidCredentials when
+// the password does not match.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Compare(hash string, password string) error
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type CreateModeratorReq struct {
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	Roles    []BoardRole `json:"roles"`
+}
+
+type SetRolesReq struct {
+	Roles []BoardRole `json:"roles"`
+}
+
+// NewSessionToken returns a random token for the admin session cookie
+func NewSessionToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashSessionToken returns the value stored in the database for a token, so a
+// leaked table does not give working cookies
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
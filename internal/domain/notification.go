@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Why a session was notified about a comment
+type NotificationKind string
+
+const (
+	NotifyReply NotificationKind = "reply" // The comment replies to a comment of the session
+	NotifyQuote NotificationKind = "quote" // The comment quotes a post or comment of the session
+)
+
+// Notifications are listed newest first, at most NotificationsLimit at a time
+const (
+	NotificationsLimit        = 50
+	NotificationExcerptLength = 140
+	NotificationPruneInterval = time.Hour
+)
+
+// Reply to something the session posted. Notifications are created together
+// with the comment and only for comments shown to everyone.
+type Notification struct {
+	ID        string           `json:"id"`
+	Kind      NotificationKind `json:"kind"`
+	PostID    string           `json:"post_id"`
+	CommentID string           `json:"comment_id"`
+	Username  string           `json:"username"`
+	Excerpt   string           `json:"excerpt"`
+	CreatedAt time.Time        `json:"created_at"`
+	Read      bool             `json:"read"`
+}
+
+type NotificationInbox struct {
+	Unread        int             `json:"unread"`
+	Notifications []*Notification `json:"notifications"`
+}
+
+// Notification IDs to mark read, every notification of the session when empty
+type MarkNotificationsReadReq struct {
+	IDs []string `json:"ids"`
+}
+
+type NotificationRepository interface {
+	ListBySession(ctx context.Context, sessionID string, limit int) ([]*Notification, error)
+	CountUnread(ctx context.Context, sessionID string) (int, error)
+	MarkRead(ctx context.Context, sessionID string, ids []string) (int, error)
+	// PruneExpired deletes the notifications of sessions that expired
+	PruneExpired(ctx context.Context) (int, error)
+}
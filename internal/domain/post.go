@@ -2,7 +2,6 @@ package domain
 
 import (
 	"context"
-	"errors"
 	"mime/multipart"
 	"time"
 )
@@ -10,6 +9,7 @@ import (
 type Post struct {
 	ID         string // UUID which generates in SQL itself
 	User       User   // Embedded or reference SessionID
+	Board      string // Short board name, e.g. "b"
 	Title      string
 	Content    string
 	ImageURLs  []string
@@ -17,12 +17,62 @@ type Post struct {
 	UpdatedAt  time.Time
 	IsArchived bool
 	ArchivedAt *time.Time
+	Sticky     bool   // Pinned to the top of the catalog, never archived
+	Locked     bool   // No new replies
+	Cyclical   bool   // Oldest replies are pruned past CyclicalReplyCap
+	IP         string `json:"-"` // Address the post was sent from, only for bans
+	Status     ContentStatus
+	// Why the post waits for approval, empty when published
+	PendingReason string `json:"-"`
+
+	// Relative to the session viewing the thread, like on comments
+	PosterID string
+	IsYou    bool
+}
+
+// Posters can delete their own threads and comments only for a short time
+
+const PosterDeleteWindow = 15 * time.Minute
+
+// Who deleted a post or comment: the poster, the system or a moderator (by ID)
+
+const (
+	DeletedByPoster = "poster"
+	DeletedBySystem = "system"
+)
+
+// Visible replies a cyclical thread keeps, older ones are pruned
+
+const CyclicalReplyCap = 100
+
+// Flags moderators can set on threads
+
+type ThreadFlag string
+
+const (
+	FlagSticky   ThreadFlag = "sticky"
+	FlagLocked   ThreadFlag = "locked"
+	FlagCyclical ThreadFlag = "cyclical"
+)
+
+func (p *Post) HasFlag(flag ThreadFlag) bool {
+	switch flag {
+	case FlagSticky:
+		return p.Sticky
+	case FlagLocked:
+		return p.Locked
+	case FlagCyclical:
+		return p.Cyclical
+	}
+	return false
 }
 
 // Structure for creating post request
 
 type CreatePostReq struct {
 	SessionID string
+	ClientIP  string
+	Board     string
 	Title     string
 	Content   string
 	ImageData []*multipart.FileHeader
@@ -32,20 +82,32 @@ type CreatePostReq struct {
 
 type PostRepository interface {
 	Save(ctx context.Context, post *Post) (*Post, error)
-	FindByID(ctx context.Context, id string) (*Post, error)
-	FindActive(ctx context.Context) ([]*Post, error)
-	FindArchived(ctx context.Context) ([]*Post, error)
+	// FindByID shows the viewer session its own hidden threads, includeHidden
+	// is only set by moderation code and shows every hidden thread
+	FindByID(ctx context.Context, id string, viewer string, includeHidden bool) (*Post, error)
+	FindActive(ctx context.Context, viewer string) ([]*Post, error)
+	FindArchived(ctx context.Context, viewer string) ([]*Post, error)
 	ArchiveOldPosts(ctx context.Context) error
+	SoftDelete(ctx context.Context, id string, deletedBy string, reason string) error
+	SetFlag(ctx context.Context, id string, flag ThreadFlag, on bool) error
+	Unarchive(ctx context.Context, id string) error
+}
+
+// Body of moderator actions on posts and comments
+
+type ModerationReq struct {
+	Reason string `json:"reason"`
+	Spam   bool   `json:"spam"` // Deleted as spam, teaches the spam scorer
 }
 
 // Validation of title length
 
 func (p *Post) Validate() error {
 	if len(p.Title) < 5 {
-		return errors.New("title too short")
+		return ErrTitleTooShort
 	}
 	if len(p.Content) < 5 {
-		return errors.New("Content too short")
+		return ErrContentTooShort
 	}
 	return nil
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Publication state of a post or comment
+type ContentStatus string
+
+const (
+	StatusPending   ContentStatus = "pending"   // Waits for a moderator, shown only to its poster
+	StatusPublished ContentStatus = "published" // Shown to everyone
+	StatusRejected  ContentStatus = "rejected"  // Turned down by a moderator, shown only to its poster
+)
+
+// Why a post or comment was sent to the approval queue
+const (
+	PendingNewSession = "new session"
+	PendingWordFilter = "word filter"
+	PendingSpam       = "spam score"
+)
+
+// Post or comment waiting in the approval queue
+type PendingItem struct {
+	TargetType TargetType `json:"target_type"`
+	TargetID   string     `json:"target_id"`
+	PostID     string     `json:"post_id"` // Thread of a comment, the thread itself for posts
+	Board      string     `json:"board"`
+	Title      string     `json:"title,omitempty"`
+	Content    string     `json:"content"`
+	ImageURLs  []string   `json:"image_urls"`
+	SessionID  string     `json:"session_id"`
+	Username   string     `json:"username"`
+	Reason     string     `json:"reason"` // One of the Pending* reasons
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type QueueRepository interface {
+	FindPending(ctx context.Context) ([]*PendingItem, error)
+	FindPendingItem(ctx context.Context, targetType TargetType, id string) (*PendingItem, error)
+	// SetStatus moves a pending post or comment out of the queue
+	SetStatus(ctx context.Context, targetType TargetType, id string, status ContentStatus) error
+}
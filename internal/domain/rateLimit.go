@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// What a rate limit is counted for
+type RateAction string
+
+const (
+	RateThread   RateAction = "thread"   // New threads
+	RateReply    RateAction = "reply"    // New comments
+	RateImage    RateAction = "image"    // Every attached image
+	RateCooldown RateAction = "cooldown" // Any post of a session, threads and comments alike
+)
+
+// Token bucket: up to Burst tokens, one token comes back every Every. A
+// cooldown is a bucket with a burst of one.
+type RateLimit struct {
+	Burst int
+	Every time.Duration
+}
+
+type RateLimits map[RateAction]RateLimit
+
+var DefaultRateLimits = RateLimits{
+	RateThread:   {Burst: 3, Every: 5 * time.Minute},
+	RateReply:    {Burst: 10, Every: 15 * time.Second},
+	RateImage:    {Burst: 20, Every: 30 * time.Second},
+	RateCooldown: {Burst: 1, Every: 10 * time.Second},
+}
+
+// State of one bucket, buckets that were never used are full
+type RateBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Take refills the bucket for the time passed since its last use and takes n
+// tokens out of it. When there are not enough tokens the bucket is returned
+// unchanged together with the time until there will be.
+func (l RateLimit) Take(bucket RateBucket, now time.Time, n int) (RateBucket, time.Duration) {
+	tokens := float64(l.Burst)
+	if !bucket.UpdatedAt.IsZero() {
+		elapsed := now.Sub(bucket.UpdatedAt)
+		tokens = math.Min(tokens, bucket.Tokens+float64(elapsed)/float64(l.Every))
+	}
+
+	if tokens < float64(n) {
+		missing := float64(n) - tokens
+		return bucket, time.Duration(math.Ceil(missing * float64(l.Every)))
+	}
+
+	return RateBucket{Tokens: tokens - float64(n), UpdatedAt: now}, 0
+}
+
+// Give puts back n tokens taken by Take, the bucket never holds more than
+// Burst
+func (l RateLimit) Give(bucket RateBucket, n int) RateBucket {
+	bucket.Tokens = math.Min(float64(l.Burst), bucket.Tokens+float64(n))
+	return bucket
+}
+
+// Buckets are stored by key, the store makes Take atomic per key
+type RateLimitStore interface {
+	// Take returns zero when the tokens were taken and how long to wait
+	// otherwise
+	Take(ctx context.Context, key string, limit RateLimit, n int) (time.Duration, error)
+	// Give returns tokens taken for a post that another bucket refused
+	Give(ctx context.Context, key string, limit RateLimit, n int) error
+}
+
+// RateLimitError is returned when a poster goes over a limit
+type RateLimitError struct {
+	Action     RateAction
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("you are posting too fast, try again in %d seconds", e.RetrySeconds())
+}
+
+// RetrySeconds rounds the wait up to whole seconds, as sent in Retry-After
+func (e *RateLimitError) RetrySeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Number of words in a recovery phrase. Every word is picked from a list of
+// 256, so each one carries 8 bits of entropy.
+const RecoveryPhraseWords = 12
+
+var recoveryWords = [256]string{
+	"acid", "acorn", "actor", "adobe", "agent", "alarm", "album", "alert",
+	"alien", "alley", "alpha", "amber", "angle", "ankle", "apple", "april",
+	"apron", "arena", "armor", "arrow", "aspen", "atlas", "attic", "audio",
+	"autumn", "award", "bacon", "badge", "bagel", "baker", "bamboo", "banjo",
+	"barn", "basil", "basin", "beach", "beacon", "beard", "beaver", "berry",
+	"bison", "blade", "blaze", "blimp", "bloom", "board", "bonus", "boot",
+	"brass", "bread", "brick", "bridge", "broom", "brush", "bucket", "buddy",
+	"bugle", "cabin", "cable", "cactus", "camel", "canal", "candy", "canoe",
+	"canyon", "cargo", "carpet", "castle", "cedar", "cello", "chalk", "charm",
+	"cherry", "chess", "chief", "chili", "cider", "circus", "citrus", "clam",
+	"cliff", "cloud", "clover", "coast", "cobra", "cocoa", "comet", "coral",
+	"cotton", "cougar", "crane", "crater", "crayon", "cream", "creek",
+	"cricket", "crown", "cubic", "curry", "cycle", "daisy", "dancer", "delta",
+	"denim", "desert", "diesel", "dingo", "disco", "dolphin", "donkey",
+	"dragon", "drum", "eagle", "easel", "echo", "elbow", "elder", "ember",
+	"emerald", "engine", "falcon", "fender", "ferry", "fiber", "fiddle",
+	"field", "finch", "flame", "flute", "focus", "forest", "fossil", "fox",
+	"frost", "galaxy", "garden", "garlic", "gecko", "geyser", "ginger",
+	"glacier", "globe", "goose", "gospel", "grape", "gravel", "guitar",
+	"hammer", "harbor", "hazel", "helmet", "heron", "hippo", "honey", "hotel",
+	"husky", "igloo", "india", "indigo", "island", "ivory", "jacket",
+	"jaguar", "jelly", "jewel", "jungle", "kayak", "kernel", "kettle", "kiwi",
+	"koala", "ladder", "lagoon", "lemon", "lentil", "lilac", "linen",
+	"lizard", "llama", "lobster", "locket", "lotus", "lunar", "magnet",
+	"mango", "maple", "marble", "meadow", "melon", "meteor", "mint", "mirror",
+	"mocha", "monkey", "mosaic", "muffin", "nectar", "needle", "nickel",
+	"noodle", "nutmeg", "oasis", "ocean", "olive", "onion", "opera", "orbit",
+	"orchid", "otter", "oyster", "paddle", "palace", "panda", "paper",
+	"parrot", "pasta", "peach", "pebble", "pepper", "piano", "pickle",
+	"pilot", "pixel", "planet", "plaza", "pocket", "polar", "poppy", "potato",
+	"prism", "pumpkin", "puzzle", "quartz", "quill", "rabbit", "radar",
+	"radio", "raven", "ribbon", "river", "robin", "rocket", "saddle",
+	"salmon", "satin", "scarf", "shadow", "shovel", "silver", "sketch",
+	"sonic", "spider", "spruce", "squid", "summit", "sunset",
+}
+
+// NewRecoveryPhrase generates a random phrase that can be exchanged for an
+// existing session on another device.
+func NewRecoveryPhrase() (string, error) {
+	buf := make([]byte, RecoveryPhraseWords)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	words := make([]string, len(buf))
+	for i, b := range buf {
+		words[i] = recoveryWords[b]
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// HashRecoveryPhrase returns the value stored in the database for a phrase.
+// Case and extra whitespace are ignored so that users can retype the phrase.
+func HashRecoveryPhrase(phrase string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Kind of content a report or moderator action points to
+type TargetType string
+
+const (
+	TargetPost    TargetType = "post"
+	TargetComment TargetType = "comment"
+)
+
+// Only posts and comments can be reported
+func (t TargetType) Reportable() bool {
+	return t == TargetPost || t == TargetComment
+}
+
+type ReportCategory string
+
+const (
+	CategorySpam       ReportCategory = "spam"
+	CategoryIllegal    ReportCategory = "illegal"
+	CategoryHarassment ReportCategory = "harassment"
+	CategoryOffTopic   ReportCategory = "off_topic"
+	CategoryOther      ReportCategory = "other"
+)
+
+func (c ReportCategory) Valid() bool {
+	switch c {
+	case CategorySpam, CategoryIllegal, CategoryHarassment, CategoryOffTopic, CategoryOther:
+		return true
+	}
+	return false
+}
+
+// What a moderator does with all reports of a target
+type ReportAction string
+
+const (
+	ReportDismiss ReportAction = "dismiss"
+	ReportDelete  ReportAction = "delete"
+	ReportBan     ReportAction = "ban" // Delete and ban the author
+)
+
+// Maximal length of the optional note of a report
+const ReportNoteMaxLength = 500
+
+type Report struct {
+	ID         string
+	TargetType TargetType
+	TargetID   string
+	SessionID  string
+	Category   ReportCategory
+	Note       string
+	CreatedAt  time.Time
+}
+
+// All open reports of one post or comment, as shown in the moderator queue
+type ReportSummary struct {
+	TargetType      TargetType
+	TargetID        string
+	Board           string
+	Preview         string // Beginning of the reported content
+	Count           int
+	Categories      map[ReportCategory]int
+	Notes           []string
+	FirstReportedAt time.Time
+	LastReportedAt  time.Time
+}
+
+// Where the reported content lives and who posted it
+type ReportTarget struct {
+	Board     string
+	SessionID string
+	IP        string
+	Text      string // Title and content, for the spam scorer
+}
+
+// Everything resolving the reports of one target changes, applied at once
+type ReportResolution struct {
+	TargetType  TargetType
+	TargetID    string
+	Action      ReportAction
+	ModeratorID string
+	Reason      string // Delete reason, the target is deleted unless the reports are dismissed
+	Bans        []*Ban // Bans of the author, get their IDs once created
+}
+
+type ReportRepository interface {
+	Save(ctx context.Context, report *Report) error
+	FindOpen(ctx context.Context) ([]*ReportSummary, error)
+	FindTarget(ctx context.Context, targetType TargetType, targetID string) (*ReportTarget, error)
+	Resolve(ctx context.Context, resolution *ReportResolution) (int, error)
+}
+
+type CreateReportReq struct {
+	TargetType TargetType     `json:"target_type"`
+	TargetID   string         `json:"target_id"`
+	Category   ReportCategory `json:"category"`
+	Note       string         `json:"note"`
+}
+
+type ResolveReportsReq struct {
+	Action        ReportAction `json:"action"`
+	Reason        string       `json:"reason"`
+	DurationHours int          `json:"duration_hours"` // Ban duration, 0 for a permanent ban
+	Spam          bool         `json:"spam"`           // Deleted as spam, teaches the spam scorer
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ShadowBannedSession struct {
+	SessionID      string    `json:"session_id"`
+	Username       string    `json:"username"`
+	AvatarURL      string    `json:"avatar_url"`
+	ShadowBannedAt time.Time `json:"shadow_banned_at"`
+	Posts          int       `json:"posts"`    // Not deleted posts
+	Comments       int       `json:"comments"` // Not deleted comments
+}
+
+// Everything a shadow-banned session posted that is not deleted yet
+type ShadowContent struct {
+	Posts    []*Post    `json:"posts"`
+	Comments []*Comment `json:"comments"`
+}
+
+type ShadowBanRepository interface {
+	SetShadowBanned(ctx context.Context, sessionID string, banned bool) error
+	ListSessions(ctx context.Context) ([]*ShadowBannedSession, error)
+	FindContent(ctx context.Context, sessionID string) (*ShadowContent, error)
+	Purge(ctx context.Context, sessionIDs []string, deletedBy string, reason string) (int, error)
+}
+
+type ShadowBanReq struct {
+	Reason string `json:"reason"`
+}
+
+// Session IDs to purge, all shadow-banned sessions when empty
+type PurgeShadowBannedReq struct {
+	SessionIDs []string `json:"session_ids"`
+	Reason     string   `json:"reason"`
+}
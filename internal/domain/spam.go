@@ -0,0 +1,39 @@
+package domain
+
+import "context"
+
+// SpamScorer rates the text of new posts and comments and learns from what
+// moderators decide about them
+type SpamScorer interface {
+	// Score returns the probability that text is spam, from 0 to 1
+	Score(ctx context.Context, text string) (float64, error)
+	// Train tells the scorer that text turned out to be spam or not
+	Train(ctx context.Context, text string, spam bool) error
+}
+
+// Scores from SpamHoldScore wait for approval, scores from SpamRejectScore
+// are rejected outright
+const (
+	SpamHoldScore   = 0.9
+	SpamRejectScore = 0.99
+)
+
+// How often a token was seen in spam and in ham
+type SpamTokenCount struct {
+	Spam int
+	Ham  int
+}
+
+// Number of texts the corpus was trained with
+type SpamTotals struct {
+	Spam int
+	Ham  int
+}
+
+// Training data of the built-in naive Bayes scorer
+type SpamCorpusRepository interface {
+	// Counts returns the counts of the known tokens among tokens and the totals
+	Counts(ctx context.Context, tokens []string) (map[string]SpamTokenCount, SpamTotals, error)
+	// Learn adds one spam or ham text made of the tokens
+	Learn(ctx context.Context, tokens []string, spam bool) error
+}
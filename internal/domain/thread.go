@@ -0,0 +1,33 @@
+package domain
+
+import "context"
+
+// Moves of whole threads and comment subtrees, every method runs in a single
+// transaction
+type ThreadRepository interface {
+	// Move puts a thread and its comments on another board
+	Move(ctx context.Context, postID string, board string) error
+	// Merge turns the opening post of source into a comment of target, moves
+	// every comment of source over and deletes source. Returns the number of
+	// comments moved, the opening post included.
+	Merge(ctx context.Context, sourceID string, targetID string, deletedBy string) (int, error)
+	// Split opens a new thread from a comment and its replies on the board of
+	// its thread, the comment is left behind as a tombstone. Returns the ID
+	// of the new thread and the number of replies moved.
+	Split(ctx context.Context, commentID string, title string, deletedBy string) (string, int, error)
+}
+
+type MoveThreadReq struct {
+	Board  string `json:"board"`
+	Reason string `json:"reason"`
+}
+
+type MergeThreadReq struct {
+	Into   string `json:"into"` // Thread that stays
+	Reason string `json:"reason"`
+}
+
+type SplitThreadReq struct {
+	Title  string `json:"title"` // Title of the new thread
+	Reason string `json:"reason"`
+}
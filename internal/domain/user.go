@@ -2,11 +2,12 @@ package domain
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
 type User struct {
-	SessionID string // UUID
+	SessionID string `json:"-"` // UUID, it is a credential and never sent back
 	AvatarURL string // From Rick & Morty API
 	Username  string // From API, yet user can override
 	CreatedAt time.Time
@@ -16,10 +17,41 @@ type User struct {
 type UserRepository interface {
 	ChangeName(ctx context.Context, newName string, sessionID string) error
 	Save(ctx context.Context, avatarURL string, name string) (string, error)
-	GetNumberOfUsers(ctx context.Context) (int, error)
 	FindByID(ctx context.Context, session_id string) (*User, error)
+	UsernameTaken(ctx context.Context, usernameKey string, exceptSessionID string) (bool, error)
+	SaveRecoveryKey(ctx context.Context, sessionID string, keyHash string) error
+	UseRecoveryKey(ctx context.Context, keyHash string) (string, error)
 }
 
 type NameRequest struct {
 	DisplayName string `json:"display_name"`
 }
+
+type RenameSessionReq struct {
+	Username string `json:"username"`
+	Reason   string `json:"reason"`
+}
+
+type RestoreRequest struct {
+	RecoveryPhrase string `json:"recovery_phrase"`
+}
+
+// Session and other IDs are UUIDs in the canonical 8-4-4-4-12 form
+func IsUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
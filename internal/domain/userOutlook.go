@@ -1,5 +1,7 @@
 package domain
 
+import "context"
+
 type UserOutlook struct {
 	AvatarURL string `json:"image"`
 	Name      string `json:"name"`
@@ -8,3 +10,23 @@ type UserOutlook struct {
 type UserOutlookAPI interface {
 	GenerateAvatarAndName(id int) (*UserOutlook, error)
 }
+
+// Strategy that picks the character of a new session. It must be safe for
+// concurrent use: sessions created at the same time get different characters.
+
+type AvatarAssigner interface {
+	NextCharacterID(ctx context.Context) (int, error)
+}
+
+// Character of the avatar provider, kept in a local pool so that new sessions
+// do not wait for the external API
+
+type Character struct {
+	ID int `json:"id"`
+	UserOutlook
+}
+
+type CharacterPoolRepository interface {
+	LoadCharacters(ctx context.Context) ([]*Character, error)
+	SaveCharacters(ctx context.Context, characters []*Character) error
+}
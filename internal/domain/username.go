@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Length limits of a display name, counted in characters and not in bytes
+const (
+	UsernameMinLength = 2
+	UsernameMaxLength = 32
+)
+
+// Names that could make a user look like a member of the staff. They are
+// compared against the skeleton of the name, so "Аdmin" with a Cyrillic A,
+// "M0derator" or "adm1n" are rejected as well.
+var reservedUsernames = usernameKeys(
+	"admin", "administrator", "moderator", "mod", "janitor",
+	"staff", "system", "root", "sysop", "owner",
+)
+
+func usernameKeys(names ...string) map[string]bool {
+	keys := make(map[string]bool, len(names))
+	for _, name := range names {
+		keys[UsernameKey(name)] = true
+	}
+	return keys
+}
+
+// Characters that render (almost) the same as a latin letter or digit. The
+// list is not complete, it covers the lookalikes that are easy to type.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's',
+	'і': 'i', 'ї': 'i', 'ј': 'j', 'ԁ': 'd', 'һ': 'h', 'ӏ': 'l', 'ԛ': 'q',
+	'ԝ': 'w', 'ɡ': 'g',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	// Latin letters with diacritics
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ì': 'i', 'í': 'i',
+	'î': 'i', 'ï': 'i', 'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ñ': 'n', 'ç': 'c', 'ý': 'y',
+	'ÿ': 'y',
+	// Latin lookalikes
+	'ı': 'i', 'ł': 'l', 'ø': 'o', 'ß': 's', 'ſ': 's', 'ƅ': 'b', 'ɑ': 'a',
+	'ℓ': 'l',
+	// Digits and symbols used as letters
+	'0': 'o', '1': 'l', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b',
+	'@': 'a', '$': 's', '|': 'l', '!': 'i',
+}
+
+// Punctuation allowed inside a name in addition to letters, digits and spaces
+const usernamePunctuation = "-_.'"
+
+// NormalizeUsername trims the name and collapses runs of whitespace into a
+// single space.
+func NormalizeUsername(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// ValidateUsername checks the name against the board naming policy and
+// returns the normalized name that should be stored.
+func ValidateUsername(name string) (string, error) {
+	name = NormalizeUsername(name)
+
+	length := len([]rune(name))
+	if length < UsernameMinLength {
+		return "", ErrUsernameTooShort
+	}
+	if length > UsernameMaxLength {
+		return "", ErrUsernameTooLong
+	}
+
+	hasLetter := false
+	for _, ch := range name {
+		switch {
+		case unicode.IsLetter(ch):
+			hasLetter = true
+		case unicode.IsDigit(ch), unicode.Is(unicode.Mn, ch), unicode.Is(unicode.Mc, ch):
+		case ch == ' ', strings.ContainsRune(usernamePunctuation, ch):
+		default:
+			return "", ErrUsernameInvalidChars
+		}
+	}
+	if !hasLetter {
+		return "", ErrUsernameInvalidChars
+	}
+
+	if IsReservedUsername(name) {
+		return "", ErrUsernameReserved
+	}
+
+	return name, nil
+}
+
+// IsReservedUsername reports whether the name, or any word of it, looks like
+// one of the reserved staff names.
+func IsReservedUsername(name string) bool {
+	if reservedUsernames[UsernameKey(name)] {
+		return true
+	}
+
+	words := strings.FieldsFunc(name, func(ch rune) bool {
+		return ch == ' ' || strings.ContainsRune(usernamePunctuation, ch)
+	})
+	for _, word := range words {
+		if reservedUsernames[UsernameKey(word)] {
+			return true
+		}
+	}
+	return false
+}
+
+// UsernameKey returns the skeleton of a name: lower case, confusable
+// characters replaced with their latin counterpart, combining marks and
+// separators dropped. Two names with the same key look alike to a reader.
+// Vertical strokes are one letter in the skeleton, "1", "l", "|", "i" and
+// "!" are told apart by shape, not by what they stand for.
+func UsernameKey(name string) string {
+	var b strings.Builder
+	for _, ch := range strings.ToLower(name) {
+		if mapped, ok := confusables[ch]; ok {
+			ch = mapped
+		}
+		if ch == 'i' {
+			ch = 'l'
+		}
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) {
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
+}
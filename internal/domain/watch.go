@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Most threads one session can watch
+const MaxWatchedThreads = 100
+
+// Thread watched by a session with its replies counted from the last time
+// the session loaded it
+type WatchedThread struct {
+	PostID       string    `json:"post_id"`
+	Board        string    `json:"board"`
+	Title        string    `json:"title"`
+	Archived     bool      `json:"archived"`
+	Locked       bool      `json:"locked"`
+	WatchedAt    time.Time `json:"watched_at"`
+	LastViewedAt time.Time `json:"last_viewed_at"`
+	// Newest reply the session has seen, new replies come after it. Nil when
+	// none of the replies were seen yet.
+	LastSeenCommentID *string    `json:"last_seen_comment_id"`
+	Replies           int        `json:"replies"`
+	NewReplies        int        `json:"new_replies"` // Replies by others since LastViewedAt
+	LastReplyAt       *time.Time `json:"last_reply_at"`
+}
+
+type WatchRepository interface {
+	// Watch starts watching the thread unless the session already watches
+	// limit threads, and reports false then. Watching a thread again changes
+	// nothing and is always fine.
+	Watch(ctx context.Context, sessionID string, postID string, limit int) (bool, error)
+	Unwatch(ctx context.Context, sessionID string, postID string) error
+	// MarkViewed moves the last viewed marker of a watched thread to now,
+	// threads that are not watched are left alone
+	MarkViewed(ctx context.Context, sessionID string, postID string) error
+	ListBySession(ctx context.Context, sessionID string) ([]*WatchedThread, error)
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// What happens to a post or comment that matches a word filter
+type FilterAction string
+
+const (
+	FilterReplace FilterAction = "replace" // Matches are replaced with Replacement
+	FilterReject  FilterAction = "reject"  // Posting fails with Message
+	FilterHold    FilterAction = "hold"    // Waits for moderator approval
+)
+
+func (a FilterAction) Valid() bool {
+	return a == FilterReplace || a == FilterReject || a == FilterHold
+}
+
+// Limits of the rule fields
+const (
+	FilterPatternMaxLength = 200
+	FilterMessageMaxLength = 200
+)
+
+// Word filter rule of one board, or of every board when Board is AllBoards.
+// Patterns are matched against text folded by FoldText, so literal
+// patterns also catch homoglyphs, and regex patterns should be written in
+// lowercase latin. Digits and symbols used as letters are folded as well, so a
+// regex sees "n00b!" as "noobi".
+type WordFilter struct {
+	ID          string       `json:"id"`
+	Board       string       `json:"board"`
+	Pattern     string       `json:"pattern"`
+	IsRegex     bool         `json:"is_regex"`
+	Action      FilterAction `json:"action"`
+	Replacement string       `json:"replacement,omitempty"`
+	Message     string       `json:"message,omitempty"` // Shown to the poster on reject
+	CreatedBy   string       `json:"created_by"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// Result of running text through the filters of a board
+type FilterVerdict struct {
+	Held    bool   // Some hold rule matched
+	HeldBy  string // Pattern of the first hold rule that matched
+	Changed bool   // Some replace rule matched
+}
+
+type WordFilterRepository interface {
+	Create(ctx context.Context, filter *WordFilter) (string, error)
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*WordFilter, error)
+	List(ctx context.Context) ([]*WordFilter, error)
+}
+
+type CreateWordFilterReq struct {
+	Board       string       `json:"board"`
+	Pattern     string       `json:"pattern"`
+	IsRegex     bool         `json:"is_regex"`
+	Action      FilterAction `json:"action"`
+	Replacement string       `json:"replacement"`
+	Message     string       `json:"message"`
+}
+
+// FoldText prepares text for word filter matching: lower case, confusable
+// and fullwidth characters replaced with their latin counterpart, invisible
+// format characters and combining marks dropped. offsets[i] is the byte
+// offset in text of the character that produced byte i of the folded text,
+// with one extra entry for the end of text.
+func FoldText(text string) (folded string, offsets []int) {
+	var b strings.Builder
+	offsets = make([]int, 0, len(text)+1)
+
+	for i, ch := range text {
+		if unicode.Is(unicode.Cf, ch) || unicode.Is(unicode.Mn, ch) {
+			continue
+		}
+		// Fullwidth forms of ASCII, "ｆｏｏ" is "foo"
+		if ch >= 0xFF01 && ch <= 0xFF5E {
+			ch -= 0xFEE0
+		}
+		ch = unicode.ToLower(ch)
+		if mapped, ok := confusables[ch]; ok {
+			ch = mapped
+		}
+
+		before := b.Len()
+		b.WriteRune(ch)
+		for range b.Len() - before {
+			offsets = append(offsets, i)
+		}
+	}
+
+	offsets = append(offsets, len(text))
+	return b.String(), offsets
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// attemptLimiter counts attempts per key inside a fixed window. It is used to
+// slow down guessing of secrets such as recovery phrases.
+type attemptLimiter struct {
+	mu       sync.Mutex
+	max      int
+	window   time.Duration
+	attempts map[string]*attemptWindow
+	now      func() time.Time
+}
+
+type attemptWindow struct {
+	count   int
+	startAt time.Time
+}
+
+func newAttemptLimiter(max int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{
+		max:      max,
+		window:   window,
+		attempts: make(map[string]*attemptWindow),
+		now:      time.Now,
+	}
+}
+
+// Allow registers an attempt for the key and reports whether it is still
+// within the limit.
+func (l *attemptLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	// Drop expired windows so the map does not grow forever
+	for k, w := range l.attempts {
+		if now.Sub(w.startAt) >= l.window {
+			delete(l.attempts, k)
+		}
+	}
+
+	w, ok := l.attempts[key]
+	if !ok {
+		w = &attemptWindow{startAt: now}
+		l.attempts[key] = w
+	}
+
+	w.count++
+	return w.count <= l.max
+}
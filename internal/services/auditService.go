@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"1337b04rd/internal/domain"
+)
+
+type AuditService struct {
+	auditRepo domain.AuditRepository
+}
+
+func NewAuditService(auditRepo domain.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Append a moderator action to the audit log. Before and after are snapshots
+// of the target, either can be nil when there is nothing to show.
+
+func (s *AuditService) Record(ctx context.Context, moderator *domain.Moderator, action string, targetType domain.TargetType, targetID string, reason string, before any, after any) error {
+	entry := &domain.AuditEntry{
+		ModeratorID: moderator.ID,
+		Moderator:   moderator.Username,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Reason:      reason,
+	}
+
+	var err error
+	if entry.Before, err = snapshot(before); err != nil {
+		return err
+	}
+	if entry.After, err = snapshot(after); err != nil {
+		return err
+	}
+
+	if err := s.auditRepo.Append(ctx, entry); err != nil {
+		slog.Error("Failed to write audit log:", "action", action, "target", targetID, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *AuditService) FindEntries(ctx context.Context, filter *domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = domain.AuditPageSize
+	}
+	if filter.Limit > domain.AuditMaxPageSize {
+		filter.Limit = domain.AuditMaxPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.auditRepo.Find(ctx, filter)
+}
+
+// Walk every matching entry, oldest first, used for exports
+
+func (s *AuditService) EachEntry(ctx context.Context, filter *domain.AuditFilter, fn func(*domain.AuditEntry) error) error {
+	return s.auditRepo.Each(ctx, filter, fn)
+}
+
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"1337b04rd/internal/domain"
+)
+
+// ShuffleAssigner hands out character IDs from 1 to size in random order
+// without repeating one until the whole range was used, then starts over with
+// a new order. State is kept in memory, so use the sequence assigner of the
+// postgres adapter when running several instances.
+type ShuffleAssigner struct {
+	mu    sync.Mutex
+	size  int
+	order []int
+	rng   *rand.Rand
+}
+
+var _ domain.AvatarAssigner = (*ShuffleAssigner)(nil)
+
+func NewShuffleAssigner(size int, seed int64) *ShuffleAssigner {
+	return &ShuffleAssigner{
+		size: size,
+		rng:  rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (a *ShuffleAssigner) NextCharacterID(ctx context.Context) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.order) == 0 {
+		a.order = a.rng.Perm(a.size)
+	}
+
+	id := a.order[len(a.order)-1] + 1
+	a.order = a.order[:len(a.order)-1]
+
+	return id, nil
+}
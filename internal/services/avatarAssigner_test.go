@@ -0,0 +1,113 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"1337b04rd/internal/domain"
+	"1337b04rd/internal/services"
+)
+
+// Outlook API and repository that are safe to call from many goroutines
+
+type recordingOutlookAPI struct {
+	mu  sync.Mutex
+	ids []int
+}
+
+func (r *recordingOutlookAPI) GenerateAvatarAndName(id int) (*domain.UserOutlook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, id)
+	return &domain.UserOutlook{
+		AvatarURL: fmt.Sprintf("%d.jpeg", id),
+		Name:      fmt.Sprintf("Character %d", id),
+	}, nil
+}
+
+type concurrentUserRepo struct {
+	fakeUserRepo
+	mu      sync.Mutex
+	avatars map[string]string // session id -> avatar
+}
+
+func (c *concurrentUserRepo) Save(ctx context.Context, avatarURL string, name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := fmt.Sprintf("session-%d", len(c.avatars))
+	c.avatars[id] = avatarURL
+	return id, nil
+}
+
+func TestShuffleAssigner_DistinctWithinCycle(t *testing.T) {
+	const size = 200
+	assigner := services.NewShuffleAssigner(size, 1)
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+
+	var wg sync.WaitGroup
+	for i := 0; i < size; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := assigner.NextCharacterID(context.Background())
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if id < 1 || id > size {
+				t.Errorf("id %d out of range", id)
+			}
+			if seen[id] {
+				t.Errorf("id %d assigned twice within a cycle", id)
+			}
+			seen[id] = true
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != size {
+		t.Fatalf("expected %d distinct ids, got %d", size, len(seen))
+	}
+
+	// The next cycle starts over
+	id, err := assigner.NextCharacterID(context.Background())
+	if err != nil || id < 1 || id > size {
+		t.Fatalf("expected id of the next cycle, got %d, %v", id, err)
+	}
+}
+
+func TestCreateUserAndGetID_ConcurrentSessionsGetDistinctCharacters(t *testing.T) {
+	const sessions = 100
+	repo := &concurrentUserRepo{avatars: make(map[string]string)}
+	api := &recordingOutlookAPI{}
+	svc := services.NewUserService(repo, api, services.NewShuffleAssigner(826, 7), false, services.AuditService{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < sessions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.CreateUserAndGetID(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	avatars := make(map[string]bool)
+	for _, avatar := range repo.avatars {
+		if avatars[avatar] {
+			t.Errorf("avatar %s assigned to two sessions", avatar)
+		}
+		avatars[avatar] = true
+	}
+	if len(avatars) != sessions {
+		t.Errorf("expected %d distinct avatars, got %d", sessions, len(avatars))
+	}
+}
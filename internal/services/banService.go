@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"1337b04rd/internal/domain"
+)
+
+type BanService struct {
+	banRepo      domain.BanRepository
+	auditService AuditService
+}
+
+func NewBanService(banRepo domain.BanRepository, auditService AuditService) *BanService {
+	return &BanService{
+		banRepo:      banRepo,
+		auditService: auditService,
+	}
+}
+
+// Bans apply to every board, so only global moderators can issue them
+
+func (s *BanService) CreateBan(ctx context.Context, moderator *domain.Moderator, req *domain.CreateBanReq) (*domain.Ban, error) {
+	ban, err := s.newBan(moderator, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.banRepo.Create(ctx, ban); err != nil {
+		return nil, err
+	}
+
+	return ban, s.recordCreated(ctx, moderator, ban)
+}
+
+// Check a ban request and turn it into a ban that is not stored yet
+
+func (s *BanService) newBan(moderator *domain.Moderator, req *domain.CreateBanReq) (*domain.Ban, error) {
+	if !moderator.HasRole(domain.AllBoards, domain.RoleModerator) {
+		return nil, domain.ErrForbidden
+	}
+
+	value, err := normalizeBanValue(req.Kind, req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, domain.ErrBanReasonRequired
+	}
+	if utf8.RuneCountInString(reason) > domain.BanReasonMaxLength {
+		return nil, domain.ErrBanReasonTooLong
+	}
+	if req.DurationHours < 0 {
+		return nil, domain.ErrInvalidBanDuration
+	}
+
+	ban := &domain.Ban{
+		Kind:      req.Kind,
+		Value:     value,
+		Reason:    reason,
+		CreatedBy: moderator.ID,
+	}
+	if req.DurationHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+		ban.ExpiresAt = &expiresAt
+	}
+	return ban, nil
+}
+
+func (s *BanService) recordCreated(ctx context.Context, moderator *domain.Moderator, ban *domain.Ban) error {
+	slog.Info("Moderator created ban", "kind", ban.Kind, "ban", ban.ID, "moderator", moderator.Username)
+	return s.auditService.Record(ctx, moderator, "ban.create", domain.TargetBan, ban.ID, ban.Reason, nil, ban)
+}
+
+func (s *BanService) LiftBan(ctx context.Context, moderator *domain.Moderator, id string) error {
+	if !moderator.HasRole(domain.AllBoards, domain.RoleModerator) {
+		return domain.ErrForbidden
+	}
+
+	ban, err := s.banRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.banRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	slog.Info("Moderator lifted ban", "ban", id, "moderator", moderator.Username)
+	return s.auditService.Record(ctx, moderator, "ban.lift", domain.TargetBan, id, "", ban, nil)
+}
+
+func (s *BanService) ListBans(ctx context.Context) ([]*domain.Ban, error) {
+	return s.banRepo.ListActive(ctx)
+}
+
+// The ban that currently keeps the session or address from posting, nil if
+// there is none
+
+func (s *BanService) ActiveBan(ctx context.Context, sessionID string, ip string) (*domain.Ban, error) {
+	ban, err := s.banRepo.FindActive(ctx, sessionID, ip)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return ban, err
+}
+
+// Returns the ban notice as an error when the session or address is banned
+
+func (s *BanService) CheckPoster(ctx context.Context, sessionID string, ip string) error {
+	ban, err := s.ActiveBan(ctx, sessionID, ip)
+	if err != nil {
+		return err
+	}
+	if ban != nil {
+		return ban.Notice()
+	}
+	return nil
+}
+
+// Returns the ban notice as an error when the image file is banned
+
+func (s *BanService) CheckImage(ctx context.Context, data []byte) error {
+	sum := sha256.Sum256(data)
+
+	ban, err := s.banRepo.FindImageBan(ctx, hex.EncodeToString(sum[:]))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return ban.Notice()
+}
+
+// Returns the ban notice as an error when the image looks like a banned one,
+// resized and re-encoded copies differ from the original in a few bits
+
+func (s *BanService) CheckImagePHash(ctx context.Context, phash uint64) error {
+	bans, err := s.banRepo.FindPHashBans(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, ban := range bans {
+		banned, ok := domain.ParsePHash(ban.Value)
+		if !ok {
+			slog.Warn("Skipping malformed perceptual hash ban", "ban", ban.ID)
+			continue
+		}
+		if domain.PHashDistance(phash, banned) <= domain.PHashBanDistance {
+			return ban.Notice()
+		}
+	}
+	return nil
+}
+
+// Check the ban value against its kind, single addresses become /32 or /128
+// ranges and hashes are lowercased
+
+func normalizeBanValue(kind domain.BanKind, value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	switch kind {
+	case domain.BanSession:
+		if !domain.IsUUID(value) {
+			return "", domain.ErrInvalidBanValue
+		}
+		return strings.ToLower(value), nil
+	case domain.BanIP:
+		if _, ipNet, err := net.ParseCIDR(value); err == nil {
+			return ipNet.String(), nil
+		}
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", domain.ErrInvalidBanValue
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return (&net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}).String(), nil
+		}
+		return (&net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}).String(), nil
+	case domain.BanImage:
+		value = strings.ToLower(value)
+		if decoded, err := hex.DecodeString(value); err != nil || len(decoded) != sha256.Size {
+			return "", domain.ErrInvalidBanValue
+		}
+		return value, nil
+	case domain.BanPHash:
+		value = strings.ToLower(value)
+		if _, ok := domain.ParsePHash(value); !ok {
+			return "", domain.ErrInvalidBanValue
+		}
+		return value, nil
+	}
+	return "", domain.ErrInvalidBanKind
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for BanService dependencies
+// --------------------
+
+type MockBanRepo struct {
+	bans     []*domain.Ban
+	active   *domain.Ban
+	imageBan map[string]*domain.Ban
+	phash    []*domain.Ban
+}
+
+func (m *MockBanRepo) Create(ctx context.Context, ban *domain.Ban) (string, error) {
+	m.bans = append(m.bans, ban)
+	ban.ID = "ban1"
+	return ban.ID, nil
+}
+
+func (m *MockBanRepo) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *MockBanRepo) FindByID(ctx context.Context, id string) (*domain.Ban, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockBanRepo) ListActive(ctx context.Context) ([]*domain.Ban, error) {
+	return m.bans, nil
+}
+
+func (m *MockBanRepo) FindActive(ctx context.Context, sessionID string, ip string) (*domain.Ban, error) {
+	if m.active == nil {
+		return nil, domain.ErrNotFound
+	}
+	return m.active, nil
+}
+
+func (m *MockBanRepo) FindImageBan(ctx context.Context, sha256 string) (*domain.Ban, error) {
+	if ban, ok := m.imageBan[sha256]; ok {
+		return ban, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockBanRepo) FindPHashBans(ctx context.Context) ([]*domain.Ban, error) {
+	return m.phash, nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestCreateBan_Validation(t *testing.T) {
+	svc := NewBanService(&MockBanRepo{}, *NewAuditService(&MockAuditRepo{}))
+	moderator := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleModerator}}}
+
+	tests := []struct {
+		name     string
+		req      domain.CreateBanReq
+		expected string
+		err      error
+	}{
+		{name: "ipv4 address", req: domain.CreateBanReq{Kind: domain.BanIP, Value: "203.0.113.7", Reason: "spam"}, expected: "203.0.113.7/32"},
+		{name: "ipv4 range", req: domain.CreateBanReq{Kind: domain.BanIP, Value: "203.0.113.77/24", Reason: "spam"}, expected: "203.0.113.0/24"},
+		{name: "ipv6 address", req: domain.CreateBanReq{Kind: domain.BanIP, Value: "2001:db8::1", Reason: "spam"}, expected: "2001:db8::1/128"},
+		{name: "ipv6 range", req: domain.CreateBanReq{Kind: domain.BanIP, Value: "2001:db8:abcd::/48", Reason: "spam"}, expected: "2001:db8:abcd::/48"},
+		{name: "session", req: domain.CreateBanReq{Kind: domain.BanSession, Value: "0B6C3C4E-8F0A-4D6E-9A55-3A1F0C2B7D10", Reason: "spam"}, expected: "0b6c3c4e-8f0a-4d6e-9a55-3a1f0c2b7d10"},
+		{name: "bad ip", req: domain.CreateBanReq{Kind: domain.BanIP, Value: "300.1.1.1", Reason: "spam"}, err: domain.ErrInvalidBanValue},
+		{name: "bad session", req: domain.CreateBanReq{Kind: domain.BanSession, Value: "not-a-session", Reason: "spam"}, err: domain.ErrInvalidBanValue},
+		{name: "bad hash", req: domain.CreateBanReq{Kind: domain.BanImage, Value: "abc", Reason: "spam"}, err: domain.ErrInvalidBanValue},
+		{name: "phash", req: domain.CreateBanReq{Kind: domain.BanPHash, Value: "00FF00FF00FF00FF", Reason: "spam"}, expected: "00ff00ff00ff00ff"},
+		{name: "bad phash", req: domain.CreateBanReq{Kind: domain.BanPHash, Value: "00ff", Reason: "spam"}, err: domain.ErrInvalidBanValue},
+		{name: "bad kind", req: domain.CreateBanReq{Kind: "user", Value: "x", Reason: "spam"}, err: domain.ErrInvalidBanKind},
+		{name: "no reason", req: domain.CreateBanReq{Kind: domain.BanIP, Value: "203.0.113.7", Reason: " "}, err: domain.ErrBanReasonRequired},
+		{name: "negative duration", req: domain.CreateBanReq{Kind: domain.BanIP, Value: "203.0.113.7", Reason: "spam", DurationHours: -1}, err: domain.ErrInvalidBanDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ban, err := svc.CreateBan(context.Background(), moderator, &tt.req)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if err == nil && ban.Value != tt.expected {
+				t.Errorf("expected value %q, got %q", tt.expected, ban.Value)
+			}
+		})
+	}
+}
+
+func TestCreateBan_RequiresGlobalModerator(t *testing.T) {
+	svc := NewBanService(&MockBanRepo{}, *NewAuditService(&MockAuditRepo{}))
+	moderatorOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleAdmin}}}
+
+	_, err := svc.CreateBan(context.Background(), moderatorOfB, &domain.CreateBanReq{Kind: domain.BanIP, Value: "203.0.113.7", Reason: "spam"})
+	if !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestCheckPosterAndImage(t *testing.T) {
+	image := []byte("banned image")
+	sum := sha256.Sum256(image)
+	banRepo := &MockBanRepo{imageBan: map[string]*domain.Ban{
+		hex.EncodeToString(sum[:]): {Kind: domain.BanImage, Reason: "illegal content"},
+	}}
+	svc := NewBanService(banRepo, AuditService{})
+
+	if err := svc.CheckPoster(context.Background(), "s1", "203.0.113.7"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.CheckImage(context.Background(), []byte("fine image")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var notice *domain.BanNotice
+	if err := svc.CheckImage(context.Background(), image); !errors.As(err, &notice) || notice.Reason != "illegal content" {
+		t.Fatalf("expected ban notice, got %v", err)
+	}
+
+	banRepo.active = &domain.Ban{Kind: domain.BanIP, Reason: "flooding"}
+	if err := svc.CheckPoster(context.Background(), "s1", "203.0.113.7"); !errors.As(err, &notice) || notice.ExpiresAt != nil {
+		t.Fatalf("expected permanent ban notice, got %v", err)
+	}
+}
+
+func TestCheckImagePHash(t *testing.T) {
+	banRepo := &MockBanRepo{phash: []*domain.Ban{
+		{ID: "ban1", Kind: domain.BanPHash, Value: "not a hash", Reason: "broken"},
+		{ID: "ban2", Kind: domain.BanPHash, Value: "f0f0f0f0f0f0f0f0", Reason: "illegal content"},
+	}}
+	svc := NewBanService(banRepo, AuditService{})
+
+	var notice *domain.BanNotice
+	// Re-encoded copy, a few bits away from the banned hash
+	if err := svc.CheckImagePHash(context.Background(), 0xf0f0f0f0f0f0f0f3); !errors.As(err, &notice) || notice.Reason != "illegal content" {
+		t.Fatalf("expected ban notice, got %v", err)
+	}
+	if err := svc.CheckImagePHash(context.Background(), 0x0f0f0f0f0f0f0f0f); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
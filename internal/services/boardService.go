@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+type BoardService struct {
+	settingsRepo domain.BoardSettingsRepository
+	auditService AuditService
+}
+
+func NewBoardService(settingsRepo domain.BoardSettingsRepository, auditService AuditService) *BoardService {
+	return &BoardService{
+		settingsRepo: settingsRepo,
+		auditService: auditService,
+	}
+}
+
+func (s *BoardService) GetSettings(ctx context.Context, board string) (*domain.BoardSettings, error) {
+	if board != domain.AllBoards && !domain.ValidBoard(board) {
+		return nil, domain.ErrInvalidBoard
+	}
+	return s.settingsRepo.Find(ctx, board)
+}
+
+// Board settings are changed by admins of the board, defaults of every board
+// by global admins
+
+func (s *BoardService) UpdateSettings(ctx context.Context, moderator *domain.Moderator, board string, req *domain.UpdateBoardSettingsReq) (*domain.BoardSettings, error) {
+	if board != domain.AllBoards && !domain.ValidBoard(board) {
+		return nil, domain.ErrInvalidBoard
+	}
+	if !moderator.HasRole(board, domain.RoleAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	if req.PremodMinutes < 0 || req.PremodMinutes > domain.PremodMaxMinutes {
+		return nil, domain.ErrInvalidPremod
+	}
+	if req.DuplicateMinutes < 0 || req.DuplicateMinutes > domain.DuplicateMaxMinutes {
+		return nil, domain.ErrInvalidDuplicate
+	}
+	if req.MaxLinks < 0 || req.MaxLinks > domain.MaxLinksLimit {
+		return nil, domain.ErrInvalidMaxLinks
+	}
+	if req.LinkMinSessionMinutes < 0 || req.LinkMinSessionMinutes > domain.LinkMaxSessionMinutes {
+		return nil, domain.ErrInvalidLinkAge
+	}
+
+	before, err := s.settingsRepo.Find(ctx, board)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &domain.BoardSettings{
+		Board:                 board,
+		PremodMinutes:         req.PremodMinutes,
+		DuplicateMinutes:      req.DuplicateMinutes,
+		Robot9000:             req.Robot9000,
+		MaxLinks:              req.MaxLinks,
+		LinkMinSessionMinutes: req.LinkMinSessionMinutes,
+	}
+	if err := s.settingsRepo.Save(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Admin changed board settings", "board", board, "moderator", moderator.Username)
+
+	if err := s.auditService.Record(ctx, moderator, "board.settings", domain.TargetBoard, board, "", before, settings); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
+
+// Status a new post or comment starts with: held by a word filter, scored as
+// likely spam or posted by a session younger than the pre-moderation period
+// of the board means it waits for approval. Certain spam is not accepted.
+
+func (s *BoardService) InitialStatus(ctx context.Context, board string, user *domain.User, verdict *domain.FilterVerdict, spamScore float64) (domain.ContentStatus, string, error) {
+	if spamScore >= domain.SpamRejectScore {
+		return "", "", domain.ErrSpamContent
+	}
+	if verdict != nil && verdict.Held {
+		return domain.StatusPending, domain.PendingWordFilter, nil
+	}
+	if spamScore >= domain.SpamHoldScore {
+		return domain.StatusPending, domain.PendingSpam, nil
+	}
+
+	settings, err := s.settingsRepo.Find(ctx, board)
+	if err != nil {
+		return "", "", err
+	}
+
+	premod := time.Duration(settings.PremodMinutes) * time.Minute
+	if premod > 0 && time.Since(user.CreatedAt) < premod {
+		return domain.StatusPending, domain.PendingNewSession, nil
+	}
+
+	return domain.StatusPublished, "", nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for BoardService dependencies
+// --------------------
+
+type MockBoardSettingsRepo struct {
+	settings map[string]*domain.BoardSettings
+}
+
+func (m *MockBoardSettingsRepo) Find(ctx context.Context, board string) (*domain.BoardSettings, error) {
+	if settings, ok := m.settings[board]; ok {
+		return settings, nil
+	}
+	if settings, ok := m.settings[domain.AllBoards]; ok {
+		defaults := *settings
+		defaults.Board = board
+		return &defaults, nil
+	}
+	return domain.DefaultBoardSettings(board), nil
+}
+
+func (m *MockBoardSettingsRepo) Save(ctx context.Context, settings *domain.BoardSettings) error {
+	if m.settings == nil {
+		m.settings = map[string]*domain.BoardSettings{}
+	}
+	m.settings[settings.Board] = settings
+	return nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestInitialStatus(t *testing.T) {
+	repo := &MockBoardSettingsRepo{settings: map[string]*domain.BoardSettings{
+		"b":              {Board: "b", PremodMinutes: 30},
+		domain.AllBoards: {Board: domain.AllBoards, PremodMinutes: 5},
+	}}
+	svc := NewBoardService(repo, AuditService{})
+
+	young := &domain.User{CreatedAt: time.Now().Add(-10 * time.Minute)}
+	old := &domain.User{CreatedAt: time.Now().Add(-time.Hour)}
+
+	tests := []struct {
+		name      string
+		board     string
+		user      *domain.User
+		verdict   *domain.FilterVerdict
+		spamScore float64
+		status    domain.ContentStatus
+		reason    string
+		err       error
+	}{
+		{name: "old session", board: "b", user: old, verdict: &domain.FilterVerdict{}, status: domain.StatusPublished},
+		{name: "young session", board: "b", user: young, verdict: &domain.FilterVerdict{}, status: domain.StatusPending, reason: domain.PendingNewSession},
+		{name: "default of every board", board: "g", user: young, verdict: &domain.FilterVerdict{}, status: domain.StatusPublished},
+		{name: "held by filter", board: "g", user: old, verdict: &domain.FilterVerdict{Held: true}, status: domain.StatusPending, reason: domain.PendingWordFilter},
+		{name: "likely spam", board: "g", user: old, verdict: &domain.FilterVerdict{}, spamScore: 0.95, status: domain.StatusPending, reason: domain.PendingSpam},
+		{name: "certain spam", board: "g", user: old, verdict: &domain.FilterVerdict{Held: true}, spamScore: 0.999, err: domain.ErrSpamContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reason, err := svc.InitialStatus(context.Background(), tt.board, tt.user, tt.verdict, tt.spamScore)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if status != tt.status || reason != tt.reason {
+				t.Errorf("expected %s (%q), got %s (%q)", tt.status, tt.reason, status, reason)
+			}
+		})
+	}
+}
+
+func TestUpdateBoardSettings(t *testing.T) {
+	adminOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleAdmin}}}
+	moderatorOfB := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleModerator}}}
+
+	tests := []struct {
+		name      string
+		moderator *domain.Moderator
+		board     string
+		minutes   int
+		err       error
+	}{
+		{name: "admin of the board", moderator: adminOfB, board: "b", minutes: 30},
+		{name: "turn off", moderator: adminOfB, board: "b", minutes: 0},
+		{name: "moderator", moderator: moderatorOfB, board: "b", minutes: 30, err: domain.ErrForbidden},
+		{name: "every board", moderator: adminOfB, board: domain.AllBoards, minutes: 30, err: domain.ErrForbidden},
+		{name: "negative", moderator: adminOfB, board: "b", minutes: -1, err: domain.ErrInvalidPremod},
+		{name: "longer than a session", moderator: adminOfB, board: "b", minutes: domain.PremodMaxMinutes + 1, err: domain.ErrInvalidPremod},
+		{name: "bad board", moderator: adminOfB, board: "B!", minutes: 30, err: domain.ErrInvalidBoard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockBoardSettingsRepo{}
+			auditRepo := &MockAuditRepo{}
+			svc := NewBoardService(repo, *NewAuditService(auditRepo))
+
+			_, err := svc.UpdateSettings(context.Background(), tt.moderator, tt.board, &domain.UpdateBoardSettingsReq{PremodMinutes: tt.minutes})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if repo.settings[tt.board].PremodMinutes != tt.minutes {
+				t.Errorf("expected %d minutes saved, got %+v", tt.minutes, repo.settings[tt.board])
+			}
+			if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != "board.settings" {
+				t.Errorf("expected audited board.settings, got %+v", auditRepo.entries)
+			}
+		})
+	}
+}
+
+func TestUpdateBoardSettings_Duplicate(t *testing.T) {
+	admin := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleAdmin}}}
+	repo := &MockBoardSettingsRepo{}
+	svc := NewBoardService(repo, *NewAuditService(&MockAuditRepo{}))
+
+	_, err := svc.UpdateSettings(context.Background(), admin, "b", &domain.UpdateBoardSettingsReq{DuplicateMinutes: domain.DuplicateMaxMinutes + 1})
+	if !errors.Is(err, domain.ErrInvalidDuplicate) {
+		t.Fatalf("expected ErrInvalidDuplicate, got %v", err)
+	}
+
+	if _, err := svc.UpdateSettings(context.Background(), admin, "b", &domain.UpdateBoardSettingsReq{DuplicateMinutes: 15, Robot9000: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved := repo.settings["b"]; saved.DuplicateMinutes != 15 || !saved.Robot9000 {
+		t.Errorf("expected 15 minute window and robot9000 saved, got %+v", saved)
+	}
+
+	// Boards nobody configured still reject reposts
+	if settings, _ := svc.GetSettings(context.Background(), "g"); settings.DuplicateMinutes != domain.DefaultDuplicateMinutes {
+		t.Errorf("expected default window for unconfigured board, got %+v", settings)
+	}
+}
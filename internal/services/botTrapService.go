@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// User agents of HTTP libraries and command line tools, browsers never send these
+var botUserAgents = []string{
+	"curl/", "wget/", "python-requests", "python-urllib", "aiohttp", "go-http-client",
+	"libwww-perl", "java/", "okhttp", "scrapy", "httpclient", "node-fetch", "axios/",
+}
+
+// Random part of form tokens, remembered once the token is used
+const formNonceBytes = 16
+
+type BotTrapService struct {
+	shadowBanRepo domain.ShadowBanRepository
+	store         domain.ChallengeStore
+	auditService  AuditService
+	secret        []byte
+	minFillTime   time.Duration
+	action        domain.BotAction
+	caught        *botCounts
+	now           func() time.Time
+}
+
+func NewBotTrapService(shadowBanRepo domain.ShadowBanRepository, store domain.ChallengeStore, auditService AuditService, secret []byte, minFillTime time.Duration, action domain.BotAction) *BotTrapService {
+	return &BotTrapService{
+		shadowBanRepo: shadowBanRepo,
+		store:         store,
+		auditService:  auditService,
+		secret:        secret,
+		minFillTime:   minFillTime,
+		action:        action,
+		caught:        &botCounts{since: time.Now(), signals: map[domain.BotSignal]int64{}},
+		now:           time.Now,
+	}
+}
+
+// Caught bots of this instance, shared by all copies of the service
+type botCounts struct {
+	mu      sync.Mutex
+	since   time.Time
+	signals map[domain.BotSignal]int64
+}
+
+func (c *botCounts) record(signal domain.BotSignal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.signals[signal]++
+}
+
+func (s *BotTrapService) Enabled() bool {
+	return s.action != domain.BotActionOff
+}
+
+// Signed timestamp handed out with the posting pages, it comes back with the
+// form and tells how long the form was open. The token only works for the
+// session it was issued to, and only once.
+
+func (s *BotTrapService) IssueFormToken(sessionID string) (*domain.FormToken, error) {
+	nonce := make([]byte, formNonceBytes)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	payload := strconv.FormatInt(s.now().Unix(), 10) + "." + hex.EncodeToString(nonce)
+	return &domain.FormToken{
+		Token:       payload + "." + s.sign(sessionID, payload),
+		MinFillTime: int(s.minFillTime / time.Second),
+	}, nil
+}
+
+// Check a thread or comment submission for bots. Drop is true when the
+// submission has to be answered as if it was saved and thrown away, bots of
+// sessions that got shadow-banned instead are posted as usual.
+
+func (s *BotTrapService) Check(ctx context.Context, sessionID string, submission *domain.FormSubmission) (drop bool, err error) {
+	if !s.Enabled() {
+		return false, nil
+	}
+
+	signal, err := s.detect(ctx, sessionID, submission)
+	if err != nil || signal == "" {
+		return false, err
+	}
+
+	s.caught.record(signal)
+	slog.Warn("Caught posting bot", "signal", signal, "session", sessionID, "user_agent", submission.UserAgent, "action", s.action)
+
+	if s.action != domain.BotActionShadowBan || sessionID == "" {
+		return true, nil
+	}
+	if err := s.shadowBanRepo.SetShadowBanned(ctx, sessionID, true); err != nil {
+		// Unknown sessions can not be shadow-banned, their posts fail later anyway
+		slog.Error("Failed to shadow-ban bot session", "session", sessionID, "error", err)
+		return true, nil
+	}
+
+	// The ban stays without its audit entry rather than letting the bot post
+	reason := "bot trap: " + string(signal)
+	if err := s.auditService.Record(ctx, domain.SystemModerator, "shadowban.create", domain.TargetSession, sessionID, reason, nil, map[string]bool{"shadow_banned": true}); err != nil {
+		slog.Error("Failed to audit bot shadow ban", "session", sessionID, "error", err)
+	}
+	return false, nil
+}
+
+func (s *BotTrapService) Stats() *domain.BotStats {
+	s.caught.mu.Lock()
+	defer s.caught.mu.Unlock()
+
+	stats := &domain.BotStats{
+		Action:  s.action,
+		Since:   s.caught.since,
+		Signals: make(map[domain.BotSignal]int64, len(s.caught.signals)),
+	}
+	for signal, count := range s.caught.signals {
+		stats.Signals[signal] = count
+		stats.Total += count
+	}
+	return stats
+}
+
+// The first heuristic the submission trips, empty for people. Expired and
+// reused forms are not a bot signal, the page was just left open for too
+// long or sent twice.
+func (s *BotTrapService) detect(ctx context.Context, sessionID string, submission *domain.FormSubmission) (domain.BotSignal, error) {
+	for _, value := range submission.Honeypots {
+		if value != "" {
+			return domain.BotHoneypot, nil
+		}
+	}
+
+	issued, nonce, ok := s.verifyFormToken(sessionID, submission.FormToken)
+	if !ok {
+		return domain.BotFormToken, nil
+	}
+	elapsed := s.now().Sub(issued)
+	if elapsed > domain.FormTokenTTL {
+		return "", domain.ErrFormExpired
+	}
+	if elapsed < s.minFillTime {
+		return domain.BotTooFast, nil
+	}
+
+	if !browserHeaders(submission) {
+		return domain.BotHeaders, nil
+	}
+
+	// Used tokens share the store of posting challenges, the prefix keeps
+	// their nonces apart
+	fresh, err := s.store.Use(ctx, "form:"+nonce, issued.Add(domain.FormTokenTTL))
+	if err != nil {
+		return "", err
+	}
+	if !fresh {
+		slog.Warn("Replayed form token", "session", sessionID, "nonce", nonce)
+		return "", domain.ErrFormUsed
+	}
+	return "", nil
+}
+
+func (s *BotTrapService) verifyFormToken(sessionID string, token string) (issued time.Time, nonce string, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, "", false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(sessionID, payload))) {
+		return time.Time{}, "", false
+	}
+	unix, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return time.Unix(unix, 0), parts[1], true
+}
+
+// Form tokens share the secret of posting challenges, the prefix keeps the
+// two kinds of signatures apart
+func (s *BotTrapService) sign(sessionID string, payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("form:" + sessionID + ":" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Browsers always send a user agent and the languages of the user
+func browserHeaders(submission *domain.FormSubmission) bool {
+	if submission.UserAgent == "" || submission.AcceptLanguage == "" {
+		return false
+	}
+	userAgent := strings.ToLower(submission.UserAgent)
+	for _, bot := range botUserAgents {
+		if strings.Contains(userAgent, bot) {
+			return false
+		}
+	}
+	return true
+}
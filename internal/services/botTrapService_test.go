@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+const browserUserAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+
+func newTestBotTrap(shadowBanRepo domain.ShadowBanRepository, auditRepo domain.AuditRepository, action domain.BotAction) (*BotTrapService, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	svc := NewBotTrapService(shadowBanRepo, &MockChallengeStore{}, *NewAuditService(auditRepo), []byte("secret"), domain.DefaultMinFormFillTime, action)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func issueFormToken(t *testing.T, svc *BotTrapService, sessionID string) string {
+	t.Helper()
+	token, err := svc.IssueFormToken(sessionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return token.Token
+}
+
+func TestBotTrap_Signals(t *testing.T) {
+	svc, now := newTestBotTrap(&MockShadowBanRepo{}, &MockAuditRepo{}, domain.BotActionDrop)
+	token := issueFormToken(t, svc, "s1")
+	*now = now.Add(10 * time.Second)
+
+	human := func() *domain.FormSubmission {
+		return &domain.FormSubmission{
+			Honeypots:      []string{"", ""},
+			FormToken:      token,
+			UserAgent:      browserUserAgent,
+			AcceptLanguage: "en-US,en;q=0.5",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		edit   func(s *domain.FormSubmission)
+		signal domain.BotSignal
+	}{
+		{name: "person", edit: func(s *domain.FormSubmission) {}},
+		{name: "honeypot", edit: func(s *domain.FormSubmission) { s.Honeypots[1] = "http://cheap-pills.example" }, signal: domain.BotHoneypot},
+		{name: "no token", edit: func(s *domain.FormSubmission) { s.FormToken = "" }, signal: domain.BotFormToken},
+		{name: "forged token", edit: func(s *domain.FormSubmission) { s.FormToken = "1699999000.00ff.00ff" }, signal: domain.BotFormToken},
+		{name: "no user agent", edit: func(s *domain.FormSubmission) { s.UserAgent = "" }, signal: domain.BotHeaders},
+		{name: "script", edit: func(s *domain.FormSubmission) { s.UserAgent = "python-requests/2.31.0" }, signal: domain.BotHeaders},
+		{name: "no language", edit: func(s *domain.FormSubmission) { s.AcceptLanguage = "" }, signal: domain.BotHeaders},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submission := human()
+			tt.edit(submission)
+			signal, err := svc.detect(context.Background(), "s1", submission)
+			if err != nil || signal != tt.signal {
+				t.Fatalf("expected signal %q, got %q, %v", tt.signal, signal, err)
+			}
+		})
+	}
+
+	tooFast := human()
+	tooFast.FormToken = issueFormToken(t, svc, "s1")
+	if signal, _ := svc.detect(context.Background(), "s1", tooFast); signal != domain.BotTooFast {
+		t.Fatalf("expected too fast, got %q", signal)
+	}
+
+	*now = now.Add(domain.FormTokenTTL)
+	if _, err := svc.detect(context.Background(), "s1", human()); !errors.Is(err, domain.ErrFormExpired) {
+		t.Fatalf("expected ErrFormExpired, got %v", err)
+	}
+}
+
+func TestBotTrap_FormTokenBinding(t *testing.T) {
+	svc, now := newTestBotTrap(&MockShadowBanRepo{}, &MockAuditRepo{}, domain.BotActionDrop)
+	submission := &domain.FormSubmission{
+		Honeypots:      []string{"", ""},
+		FormToken:      issueFormToken(t, svc, "s1"),
+		UserAgent:      browserUserAgent,
+		AcceptLanguage: "en-US,en;q=0.5",
+	}
+	*now = now.Add(10 * time.Second)
+
+	// A token fetched by one session does not work for another
+	if signal, err := svc.detect(context.Background(), "s2", submission); err != nil || signal != domain.BotFormToken {
+		t.Fatalf("expected token of another session to be refused, got %q, %v", signal, err)
+	}
+
+	if signal, err := svc.detect(context.Background(), "s1", submission); err != nil || signal != "" {
+		t.Fatalf("expected token to be accepted, got %q, %v", signal, err)
+	}
+	if _, err := svc.detect(context.Background(), "s1", submission); !errors.Is(err, domain.ErrFormUsed) {
+		t.Fatalf("expected ErrFormUsed for a reused token, got %v", err)
+	}
+}
+
+func TestBotTrap_Actions(t *testing.T) {
+	bot := &domain.FormSubmission{Honeypots: []string{"bot@example.com"}}
+
+	svc, _ := newTestBotTrap(&MockShadowBanRepo{}, &MockAuditRepo{}, domain.BotActionDrop)
+	if drop, err := svc.Check(context.Background(), "s1", bot); err != nil || !drop {
+		t.Fatalf("expected dropped submission, got %v, %v", drop, err)
+	}
+	svc.Check(context.Background(), "s1", &domain.FormSubmission{UserAgent: browserUserAgent})
+
+	stats := svc.Stats()
+	if stats.Total != 2 || stats.Signals[domain.BotHoneypot] != 1 || stats.Signals[domain.BotFormToken] != 1 {
+		t.Fatalf("expected two caught bots, got %+v", stats)
+	}
+
+	shadowBanRepo := &MockShadowBanRepo{}
+	auditRepo := &MockAuditRepo{}
+	svc, _ = newTestBotTrap(shadowBanRepo, auditRepo, domain.BotActionShadowBan)
+	if drop, err := svc.Check(context.Background(), "s1", bot); err != nil || drop {
+		t.Fatalf("expected shadow-banned bot to post, got %v, %v", drop, err)
+	}
+	if !shadowBanRepo.banned["s1"] {
+		t.Fatal("expected bot session to be shadow-banned")
+	}
+	if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != "shadowban.create" || auditRepo.entries[0].Moderator != domain.SystemModerator.Username || auditRepo.entries[0].TargetID != "s1" {
+		t.Fatalf("expected the shadow ban to be audited, got %+v", auditRepo.entries)
+	}
+
+	svc, _ = newTestBotTrap(&MockShadowBanRepo{}, &MockAuditRepo{}, domain.BotActionOff)
+	if drop, err := svc.Check(context.Background(), "s1", bot); err != nil || drop {
+		t.Fatalf("expected no checks when turned off, got %v, %v", drop, err)
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// Difficulty grows by one bit, doubling the expected work, every time the
+// posting rate doubles past busyPostsPerMinute
+const (
+	busyPostsPerMinute    = 30
+	maxExtraDifficulty    = 8
+	postRateWindow        = time.Minute
+	postRateMaxRecorded   = 10000
+	challengeNonceBytes   = 16
+	challengeTokenSegment = 4
+)
+
+type ChallengeService struct {
+	store      domain.ChallengeStore
+	secret     []byte
+	difficulty int // Base difficulty in bits, 0 turns challenges off
+	rate       *postRate
+	now        func() time.Time
+}
+
+func NewChallengeService(store domain.ChallengeStore, secret []byte, difficulty int) *ChallengeService {
+	return &ChallengeService{
+		store:      store,
+		secret:     secret,
+		difficulty: difficulty,
+		rate:       &postRate{},
+		now:        time.Now,
+	}
+}
+
+// Recent posts of this instance, shared by all copies of the service
+type postRate struct {
+	mu     sync.Mutex
+	recent []time.Time
+}
+
+func (p *postRate) record(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.trim(now)
+	if len(p.recent) < postRateMaxRecorded {
+		p.recent = append(p.recent, now)
+	}
+}
+
+func (p *postRate) perMinute(now time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.trim(now)
+	return len(p.recent)
+}
+
+func (p *postRate) trim(now time.Time) {
+	i := 0
+	for i < len(p.recent) && now.Sub(p.recent[i]) > postRateWindow {
+		i++
+	}
+	p.recent = p.recent[i:]
+}
+
+func (s *ChallengeService) Enabled() bool {
+	return s.difficulty > 0
+}
+
+// Current difficulty: the base one while the board is quiet, more under a flood
+
+func (s *ChallengeService) Difficulty() int {
+	difficulty := s.difficulty
+	for rate := s.rate.perMinute(s.now()); rate >= busyPostsPerMinute && difficulty < s.difficulty+maxExtraDifficulty; rate /= 2 {
+		difficulty++
+	}
+	return difficulty
+}
+
+// Issue a signed challenge, nothing is stored until it is used
+
+func (s *ChallengeService) Issue() (*domain.Challenge, error) {
+	nonce := make([]byte, challengeNonceBytes)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	challenge := &domain.Challenge{
+		Difficulty: s.Difficulty(),
+		ExpiresAt:  s.now().Add(domain.ChallengeTTL).Truncate(time.Second),
+	}
+
+	payload := fmt.Sprintf("%s.%d.%d", hex.EncodeToString(nonce), challenge.Difficulty, challenge.ExpiresAt.Unix())
+	challenge.Token = payload + "." + s.sign(payload)
+	return challenge, nil
+}
+
+// Check a solved challenge sent with a post, every challenge is accepted once
+
+func (s *ChallengeService) Verify(ctx context.Context, token string, solution string) error {
+	if !s.Enabled() {
+		return nil
+	}
+	if token == "" {
+		return domain.ErrChallengeRequired
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != challengeTokenSegment {
+		return domain.ErrChallengeInvalid
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.sign(payload))) {
+		return domain.ErrChallengeInvalid
+	}
+
+	nonce := parts[0]
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return domain.ErrChallengeInvalid
+	}
+	expiresUnix, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return domain.ErrChallengeInvalid
+	}
+	expiresAt := time.Unix(expiresUnix, 0)
+	if !s.now().Before(expiresAt) {
+		return domain.ErrChallengeInvalid
+	}
+
+	if solution == "" || len(solution) > domain.ChallengeSolutionMaxLength {
+		return domain.ErrChallengeUnsolved
+	}
+	sum := sha256.Sum256([]byte(token + ":" + solution))
+	if leadingZeroBits(sum[:]) < difficulty {
+		return domain.ErrChallengeUnsolved
+	}
+
+	fresh, err := s.store.Use(ctx, nonce, expiresAt)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		slog.Warn("Replayed posting challenge", "nonce", nonce)
+		return domain.ErrChallengeUsed
+	}
+
+	s.rate.record(s.now())
+	return nil
+}
+
+func (s *ChallengeService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func leadingZeroBits(sum []byte) int {
+	zeros := 0
+	for _, b := range sum {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+	return zeros
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for ChallengeService dependencies
+// --------------------
+
+type MockChallengeStore struct {
+	used map[string]bool
+}
+
+func (m *MockChallengeStore) Use(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	if m.used == nil {
+		m.used = map[string]bool{}
+	}
+	if m.used[nonce] {
+		return false, nil
+	}
+	m.used[nonce] = true
+	return true, nil
+}
+
+// Brute force like the browser does
+func solveChallenge(t *testing.T, challenge *domain.Challenge) string {
+	t.Helper()
+	for counter := 0; counter < 1<<20; counter++ {
+		solution := strconv.Itoa(counter)
+		sum := sha256.Sum256([]byte(challenge.Token + ":" + solution))
+		if leadingZeroBits(sum[:]) >= challenge.Difficulty {
+			return solution
+		}
+	}
+	t.Fatalf("no solution found for difficulty %d", challenge.Difficulty)
+	return ""
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestVerifyChallenge_SolvedOnce(t *testing.T) {
+	svc := NewChallengeService(&MockChallengeStore{}, []byte("secret"), 8)
+
+	challenge, err := svc.Issue()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if challenge.Difficulty != 8 {
+		t.Errorf("expected difficulty 8, got %d", challenge.Difficulty)
+	}
+	solution := solveChallenge(t, challenge)
+
+	if err := svc.Verify(context.Background(), challenge.Token, solution); err != nil {
+		t.Fatalf("expected solved challenge to pass, got %v", err)
+	}
+	if err := svc.Verify(context.Background(), challenge.Token, solution); !errors.Is(err, domain.ErrChallengeUsed) {
+		t.Errorf("expected ErrChallengeUsed on replay, got %v", err)
+	}
+}
+
+func TestVerifyChallenge_Rejected(t *testing.T) {
+	svc := NewChallengeService(&MockChallengeStore{}, []byte("secret"), 8)
+	challenge, _ := svc.Issue()
+	solution := solveChallenge(t, challenge)
+
+	// Lowering the difficulty breaks the signature
+	parts := strings.Split(challenge.Token, ".")
+	parts[1] = "0"
+	tampered := strings.Join(parts, ".")
+
+	other := NewChallengeService(&MockChallengeStore{}, []byte("other secret"), 8)
+
+	tests := []struct {
+		name     string
+		svc      *ChallengeService
+		token    string
+		solution string
+		want     error
+	}{
+		{"missing", svc, "", "", domain.ErrChallengeRequired},
+		{"garbage", svc, "not a token", solution, domain.ErrChallengeInvalid},
+		{"tampered", svc, tampered, solution, domain.ErrChallengeInvalid},
+		{"other secret", other, challenge.Token, solution, domain.ErrChallengeInvalid},
+		{"no solution", svc, challenge.Token, "", domain.ErrChallengeUnsolved},
+		{"long solution", svc, challenge.Token, strings.Repeat("1", domain.ChallengeSolutionMaxLength+1), domain.ErrChallengeUnsolved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.svc.Verify(context.Background(), tt.token, tt.solution); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestVerifyChallenge_WrongSolution(t *testing.T) {
+	svc := NewChallengeService(&MockChallengeStore{}, []byte("secret"), 16)
+	challenge, _ := svc.Issue()
+
+	// Find a counter that does not solve it, most of them do not
+	for counter := 0; ; counter++ {
+		solution := strconv.Itoa(counter)
+		sum := sha256.Sum256([]byte(challenge.Token + ":" + solution))
+		if leadingZeroBits(sum[:]) < challenge.Difficulty {
+			if err := svc.Verify(context.Background(), challenge.Token, solution); !errors.Is(err, domain.ErrChallengeUnsolved) {
+				t.Errorf("expected ErrChallengeUnsolved, got %v", err)
+			}
+			return
+		}
+	}
+}
+
+func TestVerifyChallenge_Expired(t *testing.T) {
+	svc := NewChallengeService(&MockChallengeStore{}, []byte("secret"), 4)
+	challenge, _ := svc.Issue()
+	solution := solveChallenge(t, challenge)
+
+	svc.now = func() time.Time { return time.Now().Add(domain.ChallengeTTL + time.Second) }
+	if err := svc.Verify(context.Background(), challenge.Token, solution); !errors.Is(err, domain.ErrChallengeInvalid) {
+		t.Errorf("expected ErrChallengeInvalid for expired challenge, got %v", err)
+	}
+}
+
+func TestVerifyChallenge_Disabled(t *testing.T) {
+	svc := NewChallengeService(&MockChallengeStore{}, []byte("secret"), 0)
+	if err := svc.Verify(context.Background(), "", ""); err != nil {
+		t.Errorf("expected no check with difficulty 0, got %v", err)
+	}
+}
+
+func TestChallengeDifficulty_Adaptive(t *testing.T) {
+	svc := NewChallengeService(&MockChallengeStore{}, []byte("secret"), 4)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	for range busyPostsPerMinute - 1 {
+		svc.rate.record(now)
+	}
+	if got := svc.Difficulty(); got != 4 {
+		t.Errorf("expected base difficulty below the busy rate, got %d", got)
+	}
+
+	// Twice the busy rate costs two more bits, one for reaching it and one for doubling it
+	for range busyPostsPerMinute + 1 {
+		svc.rate.record(now)
+	}
+	if got := svc.Difficulty(); got != 6 {
+		t.Errorf("expected difficulty 6 at 60 posts a minute, got %d", got)
+	}
+
+	// A minute later the board is quiet again
+	now = now.Add(postRateWindow + time.Second)
+	if got := svc.Difficulty(); got != 4 {
+		t.Errorf("expected base difficulty after the window, got %d", got)
+	}
+
+	for range 1 << 16 {
+		svc.rate.record(now)
+	}
+	if got := svc.Difficulty(); got != 4+maxExtraDifficulty {
+		t.Errorf("expected difficulty capped at %d, got %d", 4+maxExtraDifficulty, got)
+	}
+}
@@ -4,31 +4,59 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"1337b04rd/internal/domain"
 )
 
 type CommentService struct {
-	commentRepo   domain.CommentRepository
-	userService   UserService
-	imageStorage  domain.ImageStorageAPI
-	fileUtils     domain.FileUtils
-	defaultBucket string
+	commentRepo      domain.CommentRepository
+	postRepo         domain.PostRepository
+	userService      UserService
+	imageService     ImageService
+	auditService     AuditService
+	filterService    WordFilterService
+	boardService     BoardService
+	duplicateService DuplicateService
+	linkService      LinkPolicyService
+	spamScorer       domain.SpamScorer
+	imageStorage     domain.ImageStorageAPI
+	fileUtils        domain.FileUtils
+	defaultBucket    string
 }
 
-func NewCommentService(commentRepo domain.CommentRepository, userService UserService, imageStorage domain.ImageStorageAPI, fileUtils domain.FileUtils, defaultBucket string) *CommentService {
+func NewCommentService(commentRepo domain.CommentRepository, postRepo domain.PostRepository, userService UserService, imageService ImageService, auditService AuditService, filterService WordFilterService, boardService BoardService, duplicateService DuplicateService, linkService LinkPolicyService, spamScorer domain.SpamScorer, imageStorage domain.ImageStorageAPI, fileUtils domain.FileUtils, defaultBucket string) *CommentService {
 	return &CommentService{
-		commentRepo:   commentRepo,
-		userService:   userService,
-		imageStorage:  imageStorage,
-		fileUtils:     fileUtils,
-		defaultBucket: defaultBucket,
+		commentRepo:      commentRepo,
+		postRepo:         postRepo,
+		userService:      userService,
+		imageService:     imageService,
+		auditService:     auditService,
+		filterService:    filterService,
+		boardService:     boardService,
+		duplicateService: duplicateService,
+		linkService:      linkService,
+		spamScorer:       spamScorer,
+		imageStorage:     imageStorage,
+		fileUtils:        fileUtils,
+		defaultBucket:    defaultBucket,
 	}
 }
 
 func (s *CommentService) CreateComment(ctx context.Context, createCommentReq *domain.CreateCommentReq) (string, error) {
 	slog.Info("Service create comment:")
 
+	// Deleted threads can not be replied to
+	post, err := s.postRepo.FindByID(ctx, createCommentReq.PostID, createCommentReq.SessionID, false)
+	if err != nil {
+		slog.Error("Error when finding thread of the comment", "error", err)
+		return "", err
+	}
+
+	if post.Locked {
+		return "", domain.ErrThreadLocked
+	}
+
 	var comment domain.Comment
 
 	for _, fileheader := range createCommentReq.ImageData {
@@ -46,12 +74,19 @@ func (s *CommentService) CreateComment(ctx context.Context, createCommentReq *do
 			return "", err
 		}
 
+		imageHash, err := s.imageService.Inspect(ctx, fileBytes)
+		if err != nil {
+			slog.Warn("Rejected banned image", "error", err)
+			return "", err
+		}
+
 		imageURL, err := s.imageStorage.Store(fileBytes, s.defaultBucket)
 		if err != nil {
 			slog.Error("Failed to store the image", "error", err)
 			return "", err
 		}
 		comment.ImageURLs = append(comment.ImageURLs, imageURL)
+		s.imageService.Remember(ctx, imageURL, imageHash)
 	}
 
 	slog.Info("Preccessed and stored images from comment")
@@ -74,12 +109,100 @@ func (s *CommentService) CreateComment(ctx context.Context, createCommentReq *do
 	}
 
 	comment.User = *user
+	comment.IP = createCommentReq.ClientIP
 
 	slog.Info("Found user by ID and assigned it to comment")
 
-	return s.commentRepo.Save(ctx, &comment)
+	verdict, err := s.filterService.Apply(ctx, post.Board, &comment.Content)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.linkService.Check(ctx, post.Board, user, comment.Content); err != nil {
+		return "", err
+	}
+
+	fingerprint, err := s.duplicateService.Check(ctx, post.Board, comment.Content)
+	if err != nil {
+		return "", err
+	}
+
+	spamScore := scoreSpam(ctx, s.spamScorer, comment.Content)
+
+	comment.Status, comment.PendingReason, err = s.boardService.InitialStatus(ctx, post.Board, user, verdict, spamScore)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := s.commentRepo.Save(ctx, &comment)
+	if err != nil {
+		return "", err
+	}
+	s.duplicateService.Remember(ctx, fingerprint)
+
+	// Cyclical threads lose their oldest replies past the cap
+	if post.Cyclical && comment.Status == domain.StatusPublished {
+		if pruned, err := s.commentRepo.PruneOldest(ctx, post.ID, domain.CyclicalReplyCap); err != nil {
+			slog.Error("Failed to prune cyclical thread", "post", post.ID, "error", err)
+		} else if pruned > 0 {
+			slog.Info("Pruned cyclical thread", "post", post.ID, "pruned", pruned)
+		}
+	}
+
+	return id, nil
+}
+
+// Comments of a thread as seen by the viewer. The repository fills in the
+// poster IDs and marks the viewer's own comments.
+
+func (s *CommentService) LoadComments(ctx context.Context, postid string, viewer string) ([]*domain.Comment, error) {
+	return s.commentRepo.FindByPostID(ctx, postid, viewer)
+}
+
+// Delete a comment on behalf of its author, allowed only shortly after posting
+
+func (s *CommentService) DeleteCommentByPoster(ctx context.Context, id string, sessionID string) error {
+	comment, err := s.commentRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if comment.User.SessionID != sessionID {
+		return domain.ErrForbidden
+	}
+
+	if time.Since(comment.CreatedAt) > domain.PosterDeleteWindow {
+		return domain.ErrDeleteWindowExpired
+	}
+
+	slog.Info("Poster deleted comment", "comment", id)
+	return s.commentRepo.SoftDelete(ctx, id, domain.DeletedByPoster, "")
 }
 
-func (s *CommentService) LoadComments(ctx context.Context, postid string) ([]*domain.Comment, error) {
-	return s.commentRepo.FindByPostID(ctx, postid)
+// Comments deleted as spam teach the spam scorer
+
+func (s *CommentService) DeleteCommentByModerator(ctx context.Context, id string, moderator *domain.Moderator, reason string, spam bool) error {
+	comment, err := s.commentRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	post, err := s.postRepo.FindByID(ctx, comment.PostID, "", true)
+	if err != nil {
+		return err
+	}
+
+	if !moderator.HasRole(post.Board, domain.RoleJanitor) {
+		return domain.ErrForbidden
+	}
+
+	if err := s.commentRepo.SoftDelete(ctx, id, moderator.ID, reason); err != nil {
+		return err
+	}
+	if spam {
+		trainSpam(ctx, s.spamScorer, comment.Content, true)
+	}
+
+	slog.Info("Moderator deleted comment", "comment", id, "spam", spam, "moderator", moderator.Username)
+	return s.auditService.Record(ctx, moderator, "comment.delete", domain.TargetComment, id, reason, comment, nil)
 }
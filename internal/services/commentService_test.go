@@ -6,6 +6,7 @@ import (
 	"mime/multipart"
 	"reflect"
 	"testing"
+	"time"
 
 	"1337b04rd/internal/domain"
 )
@@ -20,6 +21,9 @@ type MockCommentRepo struct {
 	saveErr      error
 	comments     []*domain.Comment
 	findErr      error
+	findComment  *domain.Comment
+	deletedID    string
+	prunedKeep   int
 }
 
 func (m *MockCommentRepo) Save(ctx context.Context, comment *domain.Comment) (string, error) {
@@ -27,14 +31,31 @@ func (m *MockCommentRepo) Save(ctx context.Context, comment *domain.Comment) (st
 	return m.saveID, m.saveErr
 }
 
-func (m *MockCommentRepo) FindByPostID(ctx context.Context, postid string) ([]*domain.Comment, error) {
+func (m *MockCommentRepo) FindByPostID(ctx context.Context, postid string, viewer string) ([]*domain.Comment, error) {
 	return m.comments, m.findErr
 }
 
+func (m *MockCommentRepo) FindByID(ctx context.Context, id string) (*domain.Comment, error) {
+	if m.findComment == nil {
+		return nil, domain.ErrNotFound
+	}
+	return m.findComment, nil
+}
+
 func (m *MockCommentRepo) ExistByID(ctx context.Context, postid string) bool {
 	return true
 }
 
+func (m *MockCommentRepo) SoftDelete(ctx context.Context, id string, deletedBy string, reason string) error {
+	m.deletedID = id
+	return nil
+}
+
+func (m *MockCommentRepo) PruneOldest(ctx context.Context, postID string, keep int) (int, error) {
+	m.prunedKeep = keep
+	return 0, nil
+}
+
 type MockImageStorage struct {
 	storeURL string
 	storeErr error
@@ -67,7 +88,6 @@ type MockUserRepo struct {
 	findErr  error
 }
 
-func (m *MockUserRepo) GetNumberOfUsers(ctx context.Context) (int, error) { return 0, nil }
 func (m *MockUserRepo) Save(ctx context.Context, avatarURL, name string) (string, error) {
 	return "", nil
 }
@@ -75,6 +95,15 @@ func (m *MockUserRepo) ChangeName(ctx context.Context, newName, sessionID string
 func (m *MockUserRepo) FindByID(ctx context.Context, sessionID string) (*domain.User, error) {
 	return m.findUser, m.findErr
 }
+func (m *MockUserRepo) UsernameTaken(ctx context.Context, usernameKey, exceptSessionID string) (bool, error) {
+	return false, nil
+}
+func (m *MockUserRepo) SaveRecoveryKey(ctx context.Context, sessionID, keyHash string) error {
+	return nil
+}
+func (m *MockUserRepo) UseRecoveryKey(ctx context.Context, keyHash string) (string, error) {
+	return "", nil
+}
 
 type MockUserOutlookAPI struct{}
 
@@ -94,9 +123,9 @@ func TestCreateComment_Success(t *testing.T) {
 		findUser: &domain.User{SessionID: "u1", Username: "Test User"},
 	}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewCommentService(mockRepo, realUserService, mockImageStorage, mockFileUtils, "bucket123")
+	svc := NewCommentService(mockRepo, &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "b"}}, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, mockImageStorage, mockFileUtils, "bucket123")
 
 	req := &domain.CreateCommentReq{
 		Content:   "Hello World",
@@ -129,9 +158,9 @@ func TestCreateComment_ValidateImageFails(t *testing.T) {
 	mockFileUtils := &MockFileUtils{validateErr: errors.New("invalid image")}
 	mockUserRepo := &MockUserRepo{}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewCommentService(mockRepo, realUserService, mockImageStorage, mockFileUtils, "bucket123")
+	svc := NewCommentService(mockRepo, &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "b"}}, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, mockImageStorage, mockFileUtils, "bucket123")
 
 	req := &domain.CreateCommentReq{
 		ImageData: []*multipart.FileHeader{{Filename: "file1.png"}},
@@ -149,9 +178,9 @@ func TestCreateComment_FileHeaderToBytesFails(t *testing.T) {
 	mockFileUtils := &MockFileUtils{bytesErr: errors.New("cannot convert to bytes")}
 	mockUserRepo := &MockUserRepo{}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewCommentService(mockRepo, realUserService, mockImageStorage, mockFileUtils, "bucket123")
+	svc := NewCommentService(mockRepo, &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "b"}}, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, mockImageStorage, mockFileUtils, "bucket123")
 
 	req := &domain.CreateCommentReq{
 		ImageData: []*multipart.FileHeader{{Filename: "file1.png"}},
@@ -169,9 +198,9 @@ func TestCreateComment_StoreFails(t *testing.T) {
 	mockFileUtils := &MockFileUtils{bytes: []byte("image data")}
 	mockUserRepo := &MockUserRepo{}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewCommentService(mockRepo, realUserService, mockImageStorage, mockFileUtils, "bucket123")
+	svc := NewCommentService(mockRepo, &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "b"}}, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, mockImageStorage, mockFileUtils, "bucket123")
 
 	req := &domain.CreateCommentReq{
 		ImageData: []*multipart.FileHeader{{Filename: "file1.png"}},
@@ -189,9 +218,9 @@ func TestCreateComment_FindUserFails(t *testing.T) {
 	mockFileUtils := &MockFileUtils{bytes: []byte("image data")}
 	mockUserRepo := &MockUserRepo{findErr: errors.New("user not found")}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewCommentService(mockRepo, realUserService, mockImageStorage, mockFileUtils, "bucket123")
+	svc := NewCommentService(mockRepo, &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "b"}}, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, mockImageStorage, mockFileUtils, "bucket123")
 
 	req := &domain.CreateCommentReq{
 		SessionID: "u1",
@@ -211,9 +240,9 @@ func TestLoadComments_Success(t *testing.T) {
 	}
 	mockRepo := &MockCommentRepo{comments: expected}
 
-	svc := NewCommentService(mockRepo, UserService{}, nil, nil, "")
+	svc := NewCommentService(mockRepo, nil, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, nil, nil, "")
 
-	got, err := svc.LoadComments(context.Background(), "p1")
+	got, err := svc.LoadComments(context.Background(), "p1", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -221,3 +250,85 @@ func TestLoadComments_Success(t *testing.T) {
 		t.Errorf("expected %+v, got %+v", expected, got)
 	}
 }
+
+func TestCreateComment_ThreadDeleted(t *testing.T) {
+	mockRepo := &MockCommentRepo{}
+	mockPostRepo := &MockPostRepo{findErr: domain.ErrNotFound}
+
+	svc := NewCommentService(mockRepo, mockPostRepo, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, nil, nil, "")
+
+	_, err := svc.CreateComment(context.Background(), &domain.CreateCommentReq{PostID: "deleted", Content: "hello"})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if mockRepo.savedComment != nil {
+		t.Error("expected comment not to be saved")
+	}
+}
+
+func TestDeleteCommentByPoster(t *testing.T) {
+	mockRepo := &MockCommentRepo{findComment: &domain.Comment{
+		ID:        "c1",
+		PostID:    "p1",
+		User:      domain.User{SessionID: "u1"},
+		CreatedAt: time.Now(),
+	}}
+	svc := NewCommentService(mockRepo, nil, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, nil, nil, "")
+
+	if err := svc.DeleteCommentByPoster(context.Background(), "c1", "u2"); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+
+	if err := svc.DeleteCommentByPoster(context.Background(), "c1", "u1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockRepo.deletedID != "c1" {
+		t.Errorf("expected comment c1 to be deleted, got %q", mockRepo.deletedID)
+	}
+}
+
+func TestDeleteCommentByModerator_BoardScope(t *testing.T) {
+	mockRepo := &MockCommentRepo{findComment: &domain.Comment{ID: "c1", PostID: "p1"}}
+	mockPostRepo := &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "b"}}
+	auditRepo := &MockAuditRepo{}
+	svc := NewCommentService(mockRepo, mockPostRepo, UserService{}, ImageService{}, *NewAuditService(auditRepo), WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, nil, nil, "")
+
+	other := &domain.Moderator{Roles: []domain.BoardRole{{Board: "g", Role: domain.RoleAdmin}}}
+	if err := svc.DeleteCommentByModerator(context.Background(), "c1", other, "", false); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+
+	janitor := &domain.Moderator{Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleJanitor}}}
+	if err := svc.DeleteCommentByModerator(context.Background(), "c1", janitor, "off-topic", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockRepo.deletedID != "c1" {
+		t.Errorf("expected comment c1 to be deleted, got %q", mockRepo.deletedID)
+	}
+	if len(auditRepo.entries) != 1 || len(auditRepo.entries[0].Before) == 0 {
+		t.Errorf("expected the deletion to be audited with a snapshot, got %+v", auditRepo.entries)
+	}
+}
+
+func TestCreateComment_LockedAndCyclical(t *testing.T) {
+	mockRepo := &MockCommentRepo{saveID: "c1"}
+	mockPostRepo := &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "b", Locked: true}}
+	realUserService := *NewUserService(&MockUserRepo{findUser: &domain.User{SessionID: "s1"}}, &MockUserOutlookAPI{}, nil, false, AuditService{})
+	svc := NewCommentService(mockRepo, mockPostRepo, realUserService, ImageService{}, AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, nil, nil, "")
+	req := &domain.CreateCommentReq{PostID: "p1", SessionID: "s1", Content: "hello"}
+
+	if _, err := svc.CreateComment(context.Background(), req); !errors.Is(err, domain.ErrThreadLocked) {
+		t.Fatalf("expected ErrThreadLocked, got %v", err)
+	}
+	if mockRepo.savedComment != nil {
+		t.Fatalf("comment in locked thread should not be saved")
+	}
+
+	mockPostRepo.findPost = &domain.Post{ID: "p1", Board: "b", Cyclical: true}
+	if _, err := svc.CreateComment(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockRepo.prunedKeep != domain.CyclicalReplyCap {
+		t.Errorf("expected cyclical thread to be pruned to %d replies, got %d", domain.CyclicalReplyCap, mockRepo.prunedKeep)
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+type DuplicateService struct {
+	fingerprintRepo domain.FingerprintRepository
+	boardService    BoardService
+}
+
+func NewDuplicateService(fingerprintRepo domain.FingerprintRepository, boardService BoardService) *DuplicateService {
+	return &DuplicateService{
+		fingerprintRepo: fingerprintRepo,
+		boardService:    boardService,
+	}
+}
+
+// Check text about to be posted on the board against what was posted before:
+// the same text or nearly the same text anywhere within the duplicate window
+// of the board, or the same text ever on a robot9000 board. Short replies are
+// only checked on robot9000 boards. The returned
+// fingerprint is nil for text that is empty once normalized, it is stored
+// with Remember after the post is saved.
+
+func (s *DuplicateService) Check(ctx context.Context, board string, text string) (*domain.Fingerprint, error) {
+	fingerprint := domain.NewFingerprint(board, text)
+	if fingerprint == nil {
+		return nil, nil
+	}
+
+	settings, err := s.boardService.GetSettings(ctx, board)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Robot9000 {
+		exists, err := s.fingerprintRepo.ExistsOnBoard(ctx, fingerprint.Hash, board)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			slog.Info("Rejected repeated content on robot9000 board", "board", board)
+			return nil, domain.ErrRobot9000
+		}
+	}
+
+	if settings.DuplicateMinutes == 0 || fingerprint.Words < domain.DuplicateMinWords {
+		return fingerprint, nil
+	}
+	since := time.Now().Add(-time.Duration(settings.DuplicateMinutes) * time.Minute)
+
+	exists, err := s.fingerprintRepo.ExistsSince(ctx, fingerprint.Hash, since)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		slog.Info("Rejected duplicate content", "board", board)
+		return nil, domain.ErrDuplicateContent
+	}
+
+	if fingerprint.SimHash == 0 {
+		return fingerprint, nil
+	}
+
+	recent, err := s.fingerprintRepo.SimHashesSince(ctx, since, domain.SimHashScanLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, simHash := range recent {
+		if domain.NearDuplicate(fingerprint.SimHash, simHash) {
+			slog.Info("Rejected near-duplicate content", "board", board)
+			return nil, domain.ErrDuplicateContent
+		}
+	}
+
+	return fingerprint, nil
+}
+
+// Store the fingerprint returned by Check once the post is saved
+
+func (s *DuplicateService) Remember(ctx context.Context, fingerprint *domain.Fingerprint) {
+	if fingerprint == nil {
+		return
+	}
+	if err := s.fingerprintRepo.Save(ctx, fingerprint); err != nil {
+		slog.Error("Failed to save content fingerprint", "board", fingerprint.Board, "error", err)
+	}
+}
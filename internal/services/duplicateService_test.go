@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for DuplicateService dependencies
+// --------------------
+
+type MockFingerprintRepo struct {
+	fingerprints []*domain.Fingerprint
+}
+
+func (m *MockFingerprintRepo) Save(ctx context.Context, fingerprint *domain.Fingerprint) error {
+	if fingerprint.CreatedAt.IsZero() {
+		fingerprint.CreatedAt = time.Now()
+	}
+	m.fingerprints = append(m.fingerprints, fingerprint)
+	return nil
+}
+
+func (m *MockFingerprintRepo) ExistsSince(ctx context.Context, hash string, since time.Time) (bool, error) {
+	for _, f := range m.fingerprints {
+		if f.Hash == hash && f.CreatedAt.After(since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockFingerprintRepo) ExistsOnBoard(ctx context.Context, hash string, board string) (bool, error) {
+	for _, f := range m.fingerprints {
+		if f.Hash == hash && f.Board == board {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockFingerprintRepo) SimHashesSince(ctx context.Context, since time.Time, limit int) ([]uint64, error) {
+	var simHashes []uint64
+	for i := len(m.fingerprints) - 1; i >= 0 && len(simHashes) < limit; i-- {
+		if f := m.fingerprints[i]; f.SimHash != 0 && f.CreatedAt.After(since) {
+			simHashes = append(simHashes, f.SimHash)
+		}
+	}
+	return simHashes, nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+const spamText = "Buy cheap watches at our amazing store today, best prices on the whole internet guaranteed for everyone"
+
+func TestCheckDuplicate(t *testing.T) {
+	tests := []struct {
+		name   string
+		posted string
+		age    time.Duration
+		board  string
+		text   string
+		err    error
+	}{
+		{name: "same text", posted: spamText, board: "b", text: spamText, err: domain.ErrDuplicateContent},
+		{name: "same text on another board", posted: spamText, board: "g", text: spamText, err: domain.ErrDuplicateContent},
+		{name: "spacing and case", posted: "hello there, is anybody out there on this board", board: "b", text: "  HELLO,   there!! Is anybody out there on THIS board", err: domain.ErrDuplicateContent},
+		{name: "homoglyphs", posted: "hello there, is anybody out there on this board", board: "b", text: "hеllo thеre, is anybody out thеre on this board", err: domain.ErrDuplicateContent},
+		{name: "short replies can repeat", posted: "lol", board: "b", text: "LOL!"},
+		{name: "one word changed", posted: spamText, board: "b", text: "Buy cheap watches at our amazing shop today, best prices on the whole internet guaranteed for everyone", err: domain.ErrDuplicateContent},
+		{name: "short texts are not compared by similarity", posted: "hello there", board: "b", text: "hello where"},
+		{name: "unrelated text", posted: spamText, board: "b", text: "I think the new season of the show was much worse than the first one, the writing fell apart"},
+		{name: "outside the window", posted: spamText, age: 2 * time.Hour, board: "b", text: spamText},
+		{name: "window turned off", posted: spamText, board: "off", text: spamText},
+		{name: "only images", posted: "", board: "b", text: " >> "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boards := *NewBoardService(&MockBoardSettingsRepo{settings: map[string]*domain.BoardSettings{
+				"off": {Board: "off"},
+			}}, AuditService{})
+			repo := &MockFingerprintRepo{}
+			svc := NewDuplicateService(repo, boards)
+
+			if before := domain.NewFingerprint("b", tt.posted); before != nil {
+				before.CreatedAt = time.Now().Add(-tt.age)
+				repo.Save(context.Background(), before)
+			}
+
+			fingerprint, err := svc.Check(context.Background(), tt.board, tt.text)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && tt.name != "only images" && fingerprint == nil {
+				t.Errorf("expected fingerprint to remember")
+			}
+		})
+	}
+}
+
+func TestCheckDuplicate_Robot9000(t *testing.T) {
+	boards := *NewBoardService(&MockBoardSettingsRepo{settings: map[string]*domain.BoardSettings{
+		"r9k": {Board: "r9k", Robot9000: true},
+	}}, AuditService{})
+	repo := &MockFingerprintRepo{}
+	svc := NewDuplicateService(repo, boards)
+
+	fingerprint, err := svc.Check(context.Background(), "r9k", "lol")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.Remember(context.Background(), fingerprint)
+	repo.fingerprints[0].CreatedAt = time.Now().AddDate(-1, 0, 0)
+
+	if _, err := svc.Check(context.Background(), "r9k", "LOL!"); !errors.Is(err, domain.ErrRobot9000) {
+		t.Errorf("expected ErrRobot9000 for text posted a year ago, got %v", err)
+	}
+	if _, err := svc.Check(context.Background(), "b", "lol"); err != nil {
+		t.Errorf("expected old text to be fine on other boards, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+
+	"1337b04rd/internal/domain"
+)
+
+type ImageService struct {
+	imageRepo  domain.ImageRepository
+	hasher     domain.PerceptualHasher
+	banService BanService
+}
+
+func NewImageService(imageRepo domain.ImageRepository, hasher domain.PerceptualHasher, banService BanService) *ImageService {
+	return &ImageService{
+		imageRepo:  imageRepo,
+		hasher:     hasher,
+		banService: banService,
+	}
+}
+
+// Hash an uploaded image and check it against the exact and the perceptual
+// image bans. Formats the hasher can not decode are only checked by their
+// exact hash. The hashes are stored with Remember once the image is stored.
+
+func (s *ImageService) Inspect(ctx context.Context, data []byte) (*domain.ImageHash, error) {
+	if err := s.banService.CheckImage(ctx, data); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	image := &domain.ImageHash{SHA256: hex.EncodeToString(sum[:])}
+
+	if s.hasher == nil {
+		return image, nil
+	}
+	phash, err := s.hasher.Hash(data)
+	if err != nil {
+		slog.Debug("Image has no perceptual hash", "error", err)
+		return image, nil
+	}
+	image.PHash = &phash
+
+	if err := s.banService.CheckImagePHash(ctx, phash); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// Keep the hash of a stored image for similar image searches
+
+func (s *ImageService) Remember(ctx context.Context, url string, image *domain.ImageHash) {
+	if image == nil || s.imageRepo == nil {
+		return
+	}
+	image.URL = url
+	if err := s.imageRepo.Save(ctx, image); err != nil {
+		slog.Error("Failed to save image hash", "url", url, "error", err)
+	}
+}
+
+// Find images that look like an uploaded image or a perceptual hash. The
+// moderator only sees images posted on their boards, images that are not in
+// any post are left to global janitors.
+
+func (s *ImageService) FindSimilar(ctx context.Context, moderator *domain.Moderator, url string, phashValue string, distance int) ([]*domain.SimilarImage, error) {
+	if distance < 0 || distance > domain.PHashMaxDistance {
+		return nil, domain.ErrInvalidImageDistance
+	}
+
+	var phash uint64
+	switch url, phashValue = strings.TrimSpace(url), strings.TrimSpace(phashValue); {
+	case phashValue != "" && url == "":
+		var ok bool
+		if phash, ok = domain.ParsePHash(strings.ToLower(phashValue)); !ok {
+			return nil, domain.ErrInvalidImageQuery
+		}
+	case url != "" && phashValue == "":
+		image, err := s.imageRepo.FindByURL(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		if image.PHash == nil {
+			return nil, domain.ErrImageNotHashed
+		}
+		phash = *image.PHash
+	default:
+		return nil, domain.ErrInvalidImageQuery
+	}
+
+	images, err := s.imageRepo.FindSimilar(ctx, phash, distance, domain.SimilarImagesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*domain.SimilarImage, 0, len(images))
+	for _, image := range images {
+		board := image.Board
+		if board == "" {
+			board = domain.AllBoards
+		}
+		if moderator.HasRole(board, domain.RoleJanitor) {
+			visible = append(visible, image)
+		}
+	}
+	return visible, nil
+}
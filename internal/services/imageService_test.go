@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for ImageService dependencies
+// --------------------
+
+type MockImageRepo struct {
+	saved   []*domain.ImageHash
+	byURL   map[string]*domain.ImageHash
+	similar []*domain.SimilarImage
+	query   uint64
+}
+
+func (m *MockImageRepo) Save(ctx context.Context, image *domain.ImageHash) error {
+	m.saved = append(m.saved, image)
+	return nil
+}
+
+func (m *MockImageRepo) FindByURL(ctx context.Context, url string) (*domain.ImageHash, error) {
+	if image, ok := m.byURL[url]; ok {
+		return image, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockImageRepo) FindSimilar(ctx context.Context, phash uint64, maxDistance int, limit int) ([]*domain.SimilarImage, error) {
+	m.query = phash
+	return m.similar, nil
+}
+
+type MockPerceptualHasher struct {
+	hash uint64
+	err  error
+}
+
+func (m *MockPerceptualHasher) Hash(data []byte) (uint64, error) {
+	return m.hash, m.err
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestImageService_Inspect(t *testing.T) {
+	banRepo := &MockBanRepo{phash: []*domain.Ban{{ID: "ban1", Kind: domain.BanPHash, Value: "ffff0000ffff0000", Reason: "illegal content"}}}
+	imageRepo := &MockImageRepo{}
+	hasher := &MockPerceptualHasher{hash: 0xffff0000ffff0001}
+	svc := NewImageService(imageRepo, hasher, *NewBanService(banRepo, AuditService{}))
+
+	var notice *domain.BanNotice
+	if _, err := svc.Inspect(context.Background(), []byte("resized copy")); !errors.As(err, &notice) {
+		t.Fatalf("expected ban notice for a near copy, got %v", err)
+	}
+
+	hasher.hash = 0x0123456789abcdef
+	image, err := svc.Inspect(context.Background(), []byte("fine image"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image.PHash == nil || *image.PHash != hasher.hash || len(image.SHA256) != 64 {
+		t.Fatalf("expected both hashes, got %+v", image)
+	}
+
+	svc.Remember(context.Background(), "http://images/1.png", image)
+	if len(imageRepo.saved) != 1 || imageRepo.saved[0].URL != "http://images/1.png" {
+		t.Fatalf("expected saved image hash, got %+v", imageRepo.saved)
+	}
+
+	// Formats the hasher can not decode are still accepted
+	hasher.err = errors.New("unknown format")
+	image, err = svc.Inspect(context.Background(), []byte("webp image"))
+	if err != nil || image.PHash != nil {
+		t.Fatalf("expected image without perceptual hash, got %+v, %v", image, err)
+	}
+}
+
+func TestImageService_FindSimilar(t *testing.T) {
+	phash := uint64(0xffff0000ffff0000)
+	imageRepo := &MockImageRepo{
+		byURL: map[string]*domain.ImageHash{
+			"http://images/1.png": {URL: "http://images/1.png", PHash: &phash},
+			"http://images/2.gif": {URL: "http://images/2.gif"},
+		},
+		similar: []*domain.SimilarImage{
+			{URL: "http://images/1.png", Board: "b"},
+			{URL: "http://images/3.png", Board: "g"},
+			{URL: "http://images/4.png"},
+		},
+	}
+	svc := NewImageService(imageRepo, nil, BanService{})
+	janitorOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleJanitor}}}
+	globalJanitor := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleJanitor}}}
+
+	images, err := svc.FindSimilar(context.Background(), janitorOfB, "http://images/1.png", "", domain.PHashDefaultDistance)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imageRepo.query != phash || len(images) != 1 || images[0].Board != "b" {
+		t.Fatalf("expected only images of board b, got %+v", images)
+	}
+
+	images, err = svc.FindSimilar(context.Background(), globalJanitor, "", "FFFF0000FFFF0000", domain.PHashDefaultDistance)
+	if err != nil || len(images) != 3 {
+		t.Fatalf("expected every image for a global janitor, got %+v, %v", images, err)
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		phash    string
+		distance int
+		err      error
+	}{
+		{name: "nothing", err: domain.ErrInvalidImageQuery},
+		{name: "both", url: "http://images/1.png", phash: "ffff0000ffff0000", err: domain.ErrInvalidImageQuery},
+		{name: "bad hash", phash: "xyz", err: domain.ErrInvalidImageQuery},
+		{name: "too far", phash: "ffff0000ffff0000", distance: domain.PHashMaxDistance + 1, err: domain.ErrInvalidImageDistance},
+		{name: "not hashed", url: "http://images/2.gif", err: domain.ErrImageNotHashed},
+		{name: "unknown", url: "http://images/9.png", err: domain.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.FindSimilar(context.Background(), globalJanitor, tt.url, tt.phash, tt.distance); !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+type LinkPolicyService struct {
+	ruleRepo     domain.LinkRuleRepository
+	boardService BoardService
+	auditService AuditService
+	cache        *ruleCache[*domain.LinkRule]
+}
+
+func NewLinkPolicyService(ruleRepo domain.LinkRuleRepository, boardService BoardService, auditService AuditService) *LinkPolicyService {
+	return &LinkPolicyService{
+		ruleRepo:     ruleRepo,
+		boardService: boardService,
+		auditService: auditService,
+		cache:        &ruleCache[*domain.LinkRule]{},
+	}
+}
+
+// Rules of a board are managed by its moderators, rules of every board by
+// global moderators
+
+func (s *LinkPolicyService) CreateRule(ctx context.Context, moderator *domain.Moderator, req *domain.CreateLinkRuleReq) (*domain.LinkRule, error) {
+	board := req.Board
+	if board == "" {
+		board = domain.AllBoards
+	}
+	if board != domain.AllBoards && !domain.ValidBoard(board) {
+		return nil, domain.ErrInvalidBoard
+	}
+	if !moderator.HasRole(board, domain.RoleModerator) {
+		return nil, domain.ErrForbidden
+	}
+
+	if !req.Kind.Valid() {
+		return nil, domain.ErrInvalidLinkKind
+	}
+	linkDomain, ok := domain.NormalizeLinkDomain(req.Domain)
+	if !ok {
+		return nil, domain.ErrInvalidLinkDomain
+	}
+
+	rule := &domain.LinkRule{
+		Board:     board,
+		Domain:    linkDomain,
+		Kind:      req.Kind,
+		CreatedBy: moderator.ID,
+	}
+	if _, err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.cache.invalidate()
+
+	slog.Info("Moderator created link rule", "rule", rule.ID, "board", rule.Board, "domain", rule.Domain, "kind", rule.Kind, "moderator", moderator.Username)
+
+	if err := s.auditService.Record(ctx, moderator, "link.create", domain.TargetLink, rule.ID, "", nil, rule); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+func (s *LinkPolicyService) DeleteRule(ctx context.Context, moderator *domain.Moderator, id string) error {
+	rule, err := s.ruleRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !moderator.HasRole(rule.Board, domain.RoleModerator) {
+		return domain.ErrForbidden
+	}
+
+	if err := s.ruleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.cache.invalidate()
+
+	slog.Info("Moderator deleted link rule", "rule", id, "moderator", moderator.Username)
+	return s.auditService.Record(ctx, moderator, "link.delete", domain.TargetLink, id, "", rule, nil)
+}
+
+// Rules of the boards the moderator works on
+
+func (s *LinkPolicyService) ListRules(ctx context.Context, moderator *domain.Moderator) ([]*domain.LinkRule, error) {
+	rules, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*domain.LinkRule, 0, len(rules))
+	for _, rule := range rules {
+		if moderator.HasRole(rule.Board, domain.RoleJanitor) {
+			visible = append(visible, rule)
+		}
+	}
+	return visible, nil
+}
+
+// Check the links in the texts of a new post or comment: sessions younger
+// than the link age of the board can not post any, others only up to the
+// limit of the board. Denied domains are refused, and once a board has allow
+// rules only their domains can be linked.
+
+func (s *LinkPolicyService) Check(ctx context.Context, board string, user *domain.User, texts ...string) error {
+	var hosts []string
+	for _, text := range texts {
+		hosts = append(hosts, domain.ExtractLinkHosts(text)...)
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	settings, err := s.boardService.GetSettings(ctx, board)
+	if err != nil {
+		return err
+	}
+
+	minAge := time.Duration(settings.LinkMinSessionMinutes) * time.Minute
+	if time.Since(user.CreatedAt) < minAge {
+		return domain.ErrLinksNewSession
+	}
+	if settings.MaxLinks == 0 {
+		return &domain.PolicyError{Code: domain.ErrTooManyLinks.Code, Message: "links are not allowed on this board"}
+	}
+	if len(hosts) > settings.MaxLinks {
+		return &domain.PolicyError{Code: domain.ErrTooManyLinks.Code, Message: fmt.Sprintf("your post contains too many links (max %d)", settings.MaxLinks)}
+	}
+
+	rules, err := s.rules(ctx)
+	if err != nil {
+		return err
+	}
+
+	var allow, deny []*domain.LinkRule
+	for _, rule := range rules {
+		if rule.Board != board && rule.Board != domain.AllBoards {
+			continue
+		}
+		if rule.Kind == domain.LinkAllow {
+			allow = append(allow, rule)
+		} else {
+			deny = append(deny, rule)
+		}
+	}
+
+	for _, host := range hosts {
+		if host == "" {
+			return domain.ErrLinkNotAllowed
+		}
+		if covered(deny, host) || (len(allow) > 0 && !covered(allow, host)) {
+			return &domain.PolicyError{Code: domain.ErrLinkNotAllowed.Code, Message: fmt.Sprintf("links to %s are not allowed", host)}
+		}
+	}
+
+	return nil
+}
+
+func covered(rules []*domain.LinkRule, host string) bool {
+	for _, rule := range rules {
+		if rule.Covers(host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *LinkPolicyService) rules(ctx context.Context) ([]*domain.LinkRule, error) {
+	return s.cache.get(ctx, s.ruleRepo.List)
+}
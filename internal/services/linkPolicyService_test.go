@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for LinkPolicyService dependencies
+// --------------------
+
+type MockLinkRuleRepo struct {
+	rules []*domain.LinkRule
+	lists int
+}
+
+func (m *MockLinkRuleRepo) Create(ctx context.Context, rule *domain.LinkRule) (string, error) {
+	for _, existing := range m.rules {
+		if existing.Board == rule.Board && existing.Domain == rule.Domain {
+			return "", domain.ErrLinkRuleExists
+		}
+	}
+	rule.ID = "r" + string(rune('1'+len(m.rules)))
+	m.rules = append(m.rules, rule)
+	return rule.ID, nil
+}
+
+func (m *MockLinkRuleRepo) Delete(ctx context.Context, id string) error {
+	for i, rule := range m.rules {
+		if rule.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *MockLinkRuleRepo) FindByID(ctx context.Context, id string) (*domain.LinkRule, error) {
+	for _, rule := range m.rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockLinkRuleRepo) List(ctx context.Context) ([]*domain.LinkRule, error) {
+	m.lists++
+	return m.rules, nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestExtractLinkHosts(t *testing.T) {
+	hosts := domain.ExtractLinkHosts("see https://Example.com/x?y=1. and (www.foo.org/bar), HTTP://evil.example.net:8080/")
+	want := []string{"example.com", "www.foo.org", "evil.example.net"}
+	if len(hosts) != len(want) {
+		t.Fatalf("expected %v, got %v", want, hosts)
+	}
+	for i := range want {
+		if hosts[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, hosts)
+		}
+	}
+}
+
+func TestExtractLinkHosts_Evasion(t *testing.T) {
+	hosts := domain.ExtractLinkHosts("go to example.com/path or ｈｔｔｐ://ｅｖｉｌ．ｎｅｔ and bad\u200b.ru, not node.js or e.g. this")
+	want := []string{"example.com", "evil.net", "bad.ru"}
+	if len(hosts) != len(want) {
+		t.Fatalf("expected %v, got %v", want, hosts)
+	}
+	for i := range want {
+		if hosts[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, hosts)
+		}
+	}
+}
+
+func TestCreateLinkRule(t *testing.T) {
+	globalMod := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleModerator}}}
+	modOfB := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleModerator}}}
+
+	tests := []struct {
+		name      string
+		moderator *domain.Moderator
+		req       domain.CreateLinkRuleReq
+		domain    string
+		err       error
+	}{
+		{name: "global deny", moderator: globalMod, req: domain.CreateLinkRuleReq{Domain: "https://Spam.example/", Kind: domain.LinkDeny}, domain: "spam.example"},
+		{name: "board allow", moderator: modOfB, req: domain.CreateLinkRuleReq{Board: "b", Domain: "wikipedia.org", Kind: domain.LinkAllow}, domain: "wikipedia.org"},
+		{name: "every board needs global role", moderator: modOfB, req: domain.CreateLinkRuleReq{Domain: "spam.example", Kind: domain.LinkDeny}, err: domain.ErrForbidden},
+		{name: "bad kind", moderator: globalMod, req: domain.CreateLinkRuleReq{Domain: "spam.example", Kind: "block"}, err: domain.ErrInvalidLinkKind},
+		{name: "bad domain", moderator: globalMod, req: domain.CreateLinkRuleReq{Domain: "not a domain", Kind: domain.LinkDeny}, err: domain.ErrInvalidLinkDomain},
+		{name: "single label", moderator: globalMod, req: domain.CreateLinkRuleReq{Domain: "localhost", Kind: domain.LinkDeny}, err: domain.ErrInvalidLinkDomain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditRepo := &MockAuditRepo{}
+			svc := NewLinkPolicyService(&MockLinkRuleRepo{}, BoardService{}, *NewAuditService(auditRepo))
+
+			rule, err := svc.CreateRule(context.Background(), tt.moderator, &tt.req)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if rule.Domain != tt.domain {
+				t.Errorf("expected domain %q, got %q", tt.domain, rule.Domain)
+			}
+			if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != "link.create" {
+				t.Errorf("expected audited link.create, got %+v", auditRepo.entries)
+			}
+		})
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	settings := &MockBoardSettingsRepo{settings: map[string]*domain.BoardSettings{
+		"b":    {Board: "b", MaxLinks: 2, LinkMinSessionMinutes: 60},
+		"sci":  {Board: "sci", MaxLinks: 2, LinkMinSessionMinutes: 60},
+		"nolk": {Board: "nolk", MaxLinks: 0},
+	}}
+	repo := &MockLinkRuleRepo{rules: []*domain.LinkRule{
+		{ID: "r1", Board: domain.AllBoards, Domain: "spam.example", Kind: domain.LinkDeny},
+		{ID: "r2", Board: "sci", Domain: "arxiv.org", Kind: domain.LinkAllow},
+		{ID: "r3", Board: "sci", Domain: "wikipedia.org", Kind: domain.LinkAllow},
+		{ID: "r4", Board: "sci", Domain: "bad.wikipedia.org", Kind: domain.LinkDeny},
+	}}
+	svc := NewLinkPolicyService(repo, *NewBoardService(settings, AuditService{}), AuditService{})
+
+	old := &domain.User{CreatedAt: time.Now().Add(-2 * time.Hour)}
+	young := &domain.User{CreatedAt: time.Now().Add(-10 * time.Minute)}
+
+	tests := []struct {
+		name  string
+		board string
+		user  *domain.User
+		text  string
+		code  string
+	}{
+		{name: "no links", board: "b", user: young, text: "just text"},
+		{name: "link", board: "b", user: old, text: "look https://example.com"},
+		{name: "new session", board: "b", user: young, text: "look https://example.com", code: domain.ErrLinksNewSession.Code},
+		{name: "too many", board: "b", user: old, text: "https://a.example https://b.example www.c.example", code: domain.ErrTooManyLinks.Code},
+		{name: "no links on board", board: "nolk", user: old, text: "https://a.example", code: domain.ErrTooManyLinks.Code},
+		{name: "denied everywhere", board: "b", user: old, text: "https://www.SPAM.example/buy", code: domain.ErrLinkNotAllowed.Code},
+		{name: "allowed", board: "sci", user: old, text: "https://en.wikipedia.org/wiki/Go and https://arxiv.org/abs/1"},
+		{name: "not on allowlist", board: "sci", user: old, text: "https://example.com", code: domain.ErrLinkNotAllowed.Code},
+		{name: "deny beats allow", board: "sci", user: old, text: "https://bad.wikipedia.org", code: domain.ErrLinkNotAllowed.Code},
+		{name: "lookalike suffix", board: "sci", user: old, text: "https://notarxiv.org", code: domain.ErrLinkNotAllowed.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.Check(context.Background(), tt.board, tt.user, tt.text)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var policyErr *domain.PolicyError
+			if !errors.As(err, &policyErr) || policyErr.Code != tt.code {
+				t.Fatalf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+
+	if repo.lists != 1 {
+		t.Errorf("expected rules to be loaded once, got %d", repo.lists)
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// Login is limited to this many attempts per client per window
+const (
+	loginAttemptsLimit  = 10
+	loginAttemptsWindow = 15 * time.Minute
+)
+
+type ModeratorService struct {
+	moderatorRepo  domain.ModeratorRepository
+	passwordHasher domain.PasswordHasher
+	loginLimiter   *attemptLimiter
+	dummyHash      string
+	pruneInterval  time.Duration
+}
+
+func NewModeratorService(moderatorRepo domain.ModeratorRepository, passwordHasher domain.PasswordHasher) *ModeratorService {
+	return &ModeratorService{
+		moderatorRepo:  moderatorRepo,
+		passwordHasher: passwordHasher,
+		loginLimiter:   newAttemptLimiter(loginAttemptsLimit, loginAttemptsWindow),
+		dummyHash:      dummyPasswordHash(passwordHasher),
+		pruneInterval:  domain.ModeratorSessionPruneInterval,
+	}
+}
+
+// Logins of unknown moderators are checked against this hash, so that they
+// take as long as a wrong password and do not tell which usernames exist
+
+func dummyPasswordHash(passwordHasher domain.PasswordHasher) string {
+	hash, err := passwordHasher.Hash("password of no moderator")
+	if err != nil {
+		slog.Error("Failed to hash the dummy password", "error", err)
+	}
+	return hash
+}
+
+// Start deletes expired admin sessions every interval until the context is
+// cancelled
+
+func (s *ModeratorService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.pruneInterval)
+		defer ticker.Stop()
+
+		for {
+			s.pruneSessions(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *ModeratorService) pruneSessions(ctx context.Context) {
+	pruned, err := s.moderatorRepo.PruneExpiredSessions(ctx)
+	if err != nil {
+		slog.Error("Failed to prune admin sessions", "error", err)
+		return
+	}
+	if pruned > 0 {
+		slog.Info("Pruned expired admin sessions", "count", pruned)
+	}
+}
+
+// Check the password and open a new admin session, the returned token goes
+// into the admin cookie
+
+func (s *ModeratorService) Login(ctx context.Context, username string, password string, clientKey string) (string, *domain.Moderator, error) {
+	if s.loginLimiter != nil && !s.loginLimiter.Allow(clientKey) {
+		slog.Warn("Too many login attempts", "client", clientKey)
+		return "", nil, domain.ErrTooManyAttempts
+	}
+
+	moderator, err := s.moderatorRepo.FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			s.passwordHasher.Compare(s.dummyHash, password)
+			return "", nil, domain.ErrInvalidCredentials
+		}
+		return "", nil, err
+	}
+
+	if err := s.passwordHasher.Compare(moderator.PasswordHash, password); err != nil {
+		if !errors.Is(err, domain.ErrInvalidCredentials) {
+			slog.Error("Failed to compare password hash", "moderator", moderator.ID, "error", err)
+		}
+		return "", nil, domain.ErrInvalidCredentials
+	}
+
+	token, err := domain.NewSessionToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	expiresAt := time.Now().Add(domain.ModeratorSessionTTL)
+	if err := s.moderatorRepo.CreateSession(ctx, moderator.ID, domain.HashSessionToken(token), expiresAt); err != nil {
+		return "", nil, err
+	}
+
+	slog.Info("Moderator logged in", "moderator", moderator.Username)
+	return token, moderator, nil
+}
+
+func (s *ModeratorService) Logout(ctx context.Context, token string) error {
+	return s.moderatorRepo.DeleteSession(ctx, domain.HashSessionToken(token))
+}
+
+// Find the moderator of an admin session
+
+func (s *ModeratorService) Authenticate(ctx context.Context, token string) (*domain.Moderator, error) {
+	if token == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	moderator, err := s.moderatorRepo.FindBySession(ctx, domain.HashSessionToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	return moderator, nil
+}
+
+func (s *ModeratorService) CreateModerator(ctx context.Context, req *domain.CreateModeratorReq) (*domain.Moderator, error) {
+	username := domain.NormalizeUsername(req.Username)
+	if len([]rune(username)) < 3 || len([]rune(username)) > 32 {
+		return nil, domain.ErrModeratorNameSize
+	}
+
+	if len([]rune(req.Password)) < domain.ModeratorPasswordMinLength {
+		return nil, domain.ErrPasswordTooShort
+	}
+
+	if err := validateRoles(req.Roles); err != nil {
+		return nil, err
+	}
+
+	hash, err := s.passwordHasher.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	moderator := &domain.Moderator{
+		Username:     username,
+		PasswordHash: hash,
+		Roles:        req.Roles,
+	}
+
+	if _, err := s.moderatorRepo.Create(ctx, moderator); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Created moderator", "moderator", moderator.Username)
+	return moderator, nil
+}
+
+func (s *ModeratorService) SetRoles(ctx context.Context, moderatorID string, roles []domain.BoardRole) error {
+	if err := validateRoles(roles); err != nil {
+		return err
+	}
+
+	if _, err := s.moderatorRepo.FindByID(ctx, moderatorID); err != nil {
+		return err
+	}
+
+	return s.moderatorRepo.SetRoles(ctx, moderatorID, roles)
+}
+
+func (s *ModeratorService) ListModerators(ctx context.Context) ([]*domain.Moderator, error) {
+	return s.moderatorRepo.List(ctx)
+}
+
+// Create the first admin from the configuration if it does not exist yet
+
+func (s *ModeratorService) EnsureAdmin(ctx context.Context, username string, password string) error {
+	_, err := s.moderatorRepo.FindByUsername(ctx, username)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	_, err = s.CreateModerator(ctx, &domain.CreateModeratorReq{
+		Username: username,
+		Password: password,
+		Roles:    []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleAdmin}},
+	})
+	return err
+}
+
+func validateRoles(roles []domain.BoardRole) error {
+	for _, role := range roles {
+		if !role.Role.Valid() {
+			return domain.ErrInvalidRole
+		}
+		if role.Board != domain.AllBoards && !domain.ValidBoard(role.Board) {
+			return domain.ErrInvalidBoard
+		}
+	}
+	return nil
+}
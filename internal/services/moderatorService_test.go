@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for ModeratorService dependencies
+// --------------------
+
+type MockModeratorRepo struct {
+	moderators map[string]*domain.Moderator // by username
+	sessions   map[string]string            // token hash -> moderator id
+}
+
+func newMockModeratorRepo() *MockModeratorRepo {
+	return &MockModeratorRepo{
+		moderators: make(map[string]*domain.Moderator),
+		sessions:   make(map[string]string),
+	}
+}
+
+func (m *MockModeratorRepo) Create(ctx context.Context, moderator *domain.Moderator) (string, error) {
+	if _, ok := m.moderators[moderator.Username]; ok {
+		return "", domain.ErrModeratorExists
+	}
+	moderator.ID = "mod-" + moderator.Username
+	m.moderators[moderator.Username] = moderator
+	return moderator.ID, nil
+}
+
+func (m *MockModeratorRepo) FindByID(ctx context.Context, id string) (*domain.Moderator, error) {
+	for _, moderator := range m.moderators {
+		if moderator.ID == id {
+			return moderator, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockModeratorRepo) FindByUsername(ctx context.Context, username string) (*domain.Moderator, error) {
+	moderator, ok := m.moderators[username]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return moderator, nil
+}
+
+func (m *MockModeratorRepo) List(ctx context.Context) ([]*domain.Moderator, error) {
+	var moderators []*domain.Moderator
+	for _, moderator := range m.moderators {
+		moderators = append(moderators, moderator)
+	}
+	return moderators, nil
+}
+
+func (m *MockModeratorRepo) SetRoles(ctx context.Context, moderatorID string, roles []domain.BoardRole) error {
+	moderator, err := m.FindByID(ctx, moderatorID)
+	if err != nil {
+		return err
+	}
+	moderator.Roles = roles
+	return nil
+}
+
+func (m *MockModeratorRepo) CreateSession(ctx context.Context, moderatorID string, tokenHash string, expiresAt time.Time) error {
+	m.sessions[tokenHash] = moderatorID
+	return nil
+}
+
+func (m *MockModeratorRepo) FindBySession(ctx context.Context, tokenHash string) (*domain.Moderator, error) {
+	id, ok := m.sessions[tokenHash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return m.FindByID(ctx, id)
+}
+
+func (m *MockModeratorRepo) DeleteSession(ctx context.Context, tokenHash string) error {
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+func (m *MockModeratorRepo) PruneExpiredSessions(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// Stores passwords with a prefix instead of hashing them
+type MockPasswordHasher struct {
+	compared int
+}
+
+func (m *MockPasswordHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (m *MockPasswordHasher) Compare(hash string, password string) error {
+	m.compared++
+	if hash != "hashed:"+password {
+		return domain.ErrInvalidCredentials
+	}
+	return nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestModeratorLoginLogout(t *testing.T) {
+	repo := newMockModeratorRepo()
+	hasher := &MockPasswordHasher{}
+	svc := NewModeratorService(repo, hasher)
+
+	if err := svc.EnsureAdmin(context.Background(), "root-admin", "long enough password"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Second call does nothing
+	if err := svc.EnsureAdmin(context.Background(), "root-admin", "another password!"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, _, err := svc.Login(context.Background(), "root-admin", "wrong password", "1.1.1.1")
+	if !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	compared := hasher.compared
+	_, _, err = svc.Login(context.Background(), "nobody", "long enough password", "1.1.1.1")
+	if !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials for unknown user, got %v", err)
+	}
+	if hasher.compared != compared+1 {
+		t.Error("expected unknown users to be checked against a password hash too")
+	}
+
+	token, moderator, err := svc.Login(context.Background(), "root-admin", "long enough password", "1.1.1.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !moderator.HasRole("b", domain.RoleAdmin) {
+		t.Errorf("expected bootstrap admin to be admin on every board")
+	}
+
+	authenticated, err := svc.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if authenticated.ID != moderator.ID {
+		t.Errorf("expected %s, got %s", moderator.ID, authenticated.ID)
+	}
+
+	if err := svc.Logout(context.Background(), token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Authenticate(context.Background(), token); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized after logout, got %v", err)
+	}
+}
+
+func TestModeratorLogin_RateLimited(t *testing.T) {
+	svc := NewModeratorService(newMockModeratorRepo(), &MockPasswordHasher{})
+
+	var err error
+	for i := 0; i <= loginAttemptsLimit; i++ {
+		_, _, err = svc.Login(context.Background(), "nobody", "password", "1.1.1.1")
+	}
+	if !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Fatalf("expected ErrTooManyAttempts, got %v", err)
+	}
+}
+
+func TestCreateModerator_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  domain.CreateModeratorReq
+		err  error
+	}{
+		{
+			name: "short password",
+			req:  domain.CreateModeratorReq{Username: "janny", Password: "short"},
+			err:  domain.ErrPasswordTooShort,
+		},
+		{
+			name: "short username",
+			req:  domain.CreateModeratorReq{Username: "j", Password: "long enough password"},
+			err:  domain.ErrModeratorNameSize,
+		},
+		{
+			name: "unknown role",
+			req: domain.CreateModeratorReq{Username: "janny", Password: "long enough password",
+				Roles: []domain.BoardRole{{Board: "b", Role: "god"}}},
+			err: domain.ErrInvalidRole,
+		},
+		{
+			name: "invalid board",
+			req: domain.CreateModeratorReq{Username: "janny", Password: "long enough password",
+				Roles: []domain.BoardRole{{Board: "B!", Role: domain.RoleJanitor}}},
+			err: domain.ErrInvalidBoard,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewModeratorService(newMockModeratorRepo(), &MockPasswordHasher{})
+			_, err := svc.CreateModerator(context.Background(), &tt.req)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestModeratorHasRole(t *testing.T) {
+	moderator := &domain.Moderator{Roles: []domain.BoardRole{
+		{Board: "b", Role: domain.RoleModerator},
+		{Board: domain.AllBoards, Role: domain.RoleJanitor},
+	}}
+
+	tests := []struct {
+		board    string
+		role     domain.Role
+		expected bool
+	}{
+		{"b", domain.RoleJanitor, true},
+		{"b", domain.RoleModerator, true},
+		{"b", domain.RoleAdmin, false},
+		{"g", domain.RoleJanitor, true},
+		{"g", domain.RoleModerator, false},
+		{domain.AllBoards, domain.RoleModerator, false},
+	}
+
+	for _, tt := range tests {
+		if got := moderator.HasRole(tt.board, tt.role); got != tt.expected {
+			t.Errorf("HasRole(%q, %q) = %v, expected %v", tt.board, tt.role, got, tt.expected)
+		}
+	}
+}
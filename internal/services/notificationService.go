@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+type NotificationService struct {
+	notificationRepo domain.NotificationRepository
+	pruneInterval    time.Duration
+}
+
+func NewNotificationService(notificationRepo domain.NotificationRepository) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		pruneInterval:    domain.NotificationPruneInterval,
+	}
+}
+
+// Start prunes the notifications of expired sessions every interval until
+// the context is cancelled
+func (s *NotificationService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.pruneInterval)
+		defer ticker.Stop()
+
+		for {
+			s.prune(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *NotificationService) prune(ctx context.Context) {
+	pruned, err := s.notificationRepo.PruneExpired(ctx)
+	if err != nil {
+		slog.Error("Failed to prune notifications", "error", err)
+		return
+	}
+	if pruned > 0 {
+		slog.Info("Pruned notifications of expired sessions", "count", pruned)
+	}
+}
+
+// Newest notifications of the session and how many of all its notifications
+// are unread
+func (s *NotificationService) Inbox(ctx context.Context, sessionID string) (*domain.NotificationInbox, error) {
+	notifications, err := s.notificationRepo.ListBySession(ctx, sessionID, domain.NotificationsLimit)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if notifications == nil {
+		notifications = []*domain.Notification{}
+	}
+	for _, notification := range notifications {
+		notification.Excerpt = excerpt(notification.Excerpt, domain.NotificationExcerptLength)
+	}
+	return &domain.NotificationInbox{Unread: unread, Notifications: notifications}, nil
+}
+
+// Returns how many notifications were still unread, other sessions'
+// notifications are never touched
+func (s *NotificationService) MarkRead(ctx context.Context, sessionID string, req *domain.MarkNotificationsReadReq) (int, error) {
+	return s.notificationRepo.MarkRead(ctx, sessionID, req.IDs)
+}
+
+// First runes of the text, cut at the last space when there is one
+func excerpt(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+
+	cut := runes[:length]
+	for i := len(cut) - 1; i > length/2; i-- {
+		if cut[i] == ' ' {
+			cut = cut[:i]
+			break
+		}
+	}
+	return string(cut) + "…"
+}
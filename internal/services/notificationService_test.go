@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for NotificationService dependencies
+// --------------------
+
+type MockNotificationRepo struct {
+	notifications []*domain.Notification
+	unread        int
+	markedFor     string
+	markedIDs     []string
+	pruned        int
+}
+
+func (m *MockNotificationRepo) ListBySession(ctx context.Context, sessionID string, limit int) ([]*domain.Notification, error) {
+	return m.notifications, nil
+}
+
+func (m *MockNotificationRepo) CountUnread(ctx context.Context, sessionID string) (int, error) {
+	return m.unread, nil
+}
+
+func (m *MockNotificationRepo) MarkRead(ctx context.Context, sessionID string, ids []string) (int, error) {
+	m.markedFor = sessionID
+	m.markedIDs = ids
+	return len(ids), nil
+}
+
+func (m *MockNotificationRepo) PruneExpired(ctx context.Context) (int, error) {
+	m.pruned++
+	return 0, nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestNotificationService_Inbox(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	repo := &MockNotificationRepo{
+		notifications: []*domain.Notification{
+			{ID: "n1", Kind: domain.NotifyReply, Excerpt: "thanks"},
+			{ID: "n2", Kind: domain.NotifyQuote, Excerpt: long},
+		},
+		unread: 7,
+	}
+	svc := NewNotificationService(repo)
+
+	inbox, err := svc.Inbox(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inbox.Unread != 7 || len(inbox.Notifications) != 2 {
+		t.Fatalf("expected 2 notifications and 7 unread, got %+v", inbox)
+	}
+	if inbox.Notifications[0].Excerpt != "thanks" {
+		t.Errorf("expected short excerpt untouched, got %q", inbox.Notifications[0].Excerpt)
+	}
+	if got := inbox.Notifications[1].Excerpt; utf8.RuneCountInString(got) > domain.NotificationExcerptLength+1 || !strings.HasSuffix(got, "word…") {
+		t.Errorf("expected excerpt cut at a word, got %q", got)
+	}
+
+	empty, err := NewNotificationService(&MockNotificationRepo{}).Inbox(context.Background(), "s1")
+	if err != nil || empty.Notifications == nil {
+		t.Fatalf("expected empty list instead of null, got %+v, %v", empty, err)
+	}
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	repo := &MockNotificationRepo{}
+	svc := NewNotificationService(repo)
+
+	marked, err := svc.MarkRead(context.Background(), "s1", &domain.MarkNotificationsReadReq{IDs: []string{"n1", "n2"}})
+	if err != nil || marked != 2 {
+		t.Fatalf("expected 2 marked, got %d, %v", marked, err)
+	}
+	if repo.markedFor != "s1" {
+		t.Errorf("expected notifications of s1 marked, got %q", repo.markedFor)
+	}
+}
+
+func TestNotificationService_Prune(t *testing.T) {
+	repo := &MockNotificationRepo{}
+	svc := NewNotificationService(repo)
+
+	svc.prune(context.Background())
+	if repo.pruned != 1 {
+		t.Fatalf("expected one prune, got %d", repo.pruned)
+	}
+}
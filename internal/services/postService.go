@@ -3,25 +3,42 @@ package services
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"1337b04rd/internal/domain"
 )
 
 type PostService struct {
-	postRepo      domain.PostRepository
-	userService   UserService
-	imageStorage  domain.ImageStorageAPI
-	fileUtils     domain.FileUtils
-	defaultBucket string
+	postRepo         domain.PostRepository
+	userService      UserService
+	imageService     ImageService
+	auditService     AuditService
+	filterService    WordFilterService
+	boardService     BoardService
+	duplicateService DuplicateService
+	linkService      LinkPolicyService
+	spamScorer       domain.SpamScorer
+	imageStorage     domain.ImageStorageAPI
+	fileUtils        domain.FileUtils
+	defaultBucket    string
+	posterSecret     []byte
 }
 
-func NewPostService(postRepo domain.PostRepository, imageStorage domain.ImageStorageAPI, fileUtils domain.FileUtils, userService UserService, defaultBucket string) *PostService {
+func NewPostService(postRepo domain.PostRepository, imageStorage domain.ImageStorageAPI, fileUtils domain.FileUtils, userService UserService, imageService ImageService, auditService AuditService, filterService WordFilterService, boardService BoardService, duplicateService DuplicateService, linkService LinkPolicyService, spamScorer domain.SpamScorer, defaultBucket string, posterSecret []byte) *PostService {
 	return &PostService{
-		postRepo:      postRepo,
-		imageStorage:  imageStorage,
-		fileUtils:     fileUtils,
-		userService:   userService,
-		defaultBucket: defaultBucket,
+		postRepo:         postRepo,
+		imageStorage:     imageStorage,
+		fileUtils:        fileUtils,
+		userService:      userService,
+		imageService:     imageService,
+		auditService:     auditService,
+		filterService:    filterService,
+		boardService:     boardService,
+		duplicateService: duplicateService,
+		linkService:      linkService,
+		spamScorer:       spamScorer,
+		defaultBucket:    defaultBucket,
+		posterSecret:     posterSecret,
 	}
 }
 
@@ -43,12 +60,27 @@ func (s *PostService) CreatePost(ctx context.Context, createPostReq *domain.Crea
 			return nil, err
 		}
 
+		imageHash, err := s.imageService.Inspect(ctx, fileBytes)
+		if err != nil {
+			slog.Warn("Rejected banned image", "error", err)
+			return nil, err
+		}
+
 		imageURL, err := s.imageStorage.Store(fileBytes, s.defaultBucket)
 		if err != nil {
 			slog.Error("Failed to store the image:", "error", err)
 			return nil, err
 		}
 		post.ImageURLs = append(post.ImageURLs, imageURL)
+		s.imageService.Remember(ctx, imageURL, imageHash)
+	}
+
+	post.Board = createPostReq.Board
+	if post.Board == "" {
+		post.Board = domain.DefaultBoard
+	}
+	if !domain.ValidBoard(post.Board) {
+		return nil, domain.ErrInvalidBoard
 	}
 
 	post.Title = createPostReq.Title
@@ -61,26 +93,164 @@ func (s *PostService) CreatePost(ctx context.Context, createPostReq *domain.Crea
 	}
 
 	post.User = *user
+	post.IP = createPostReq.ClientIP
+
+	verdict, err := s.filterService.Apply(ctx, post.Board, &post.Title, &post.Content)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := post.Validate(); err != nil {
 		return nil, err
 	}
 
-	return s.postRepo.Save(ctx, &post)
+	if err := s.linkService.Check(ctx, post.Board, user, post.Title, post.Content); err != nil {
+		return nil, err
+	}
+
+	fingerprint, err := s.duplicateService.Check(ctx, post.Board, post.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	spamScore := scoreSpam(ctx, s.spamScorer, post.Title+"\n"+post.Content)
+
+	post.Status, post.PendingReason, err = s.boardService.InitialStatus(ctx, post.Board, user, verdict, spamScore)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.postRepo.Save(ctx, &post)
+	if err != nil {
+		return nil, err
+	}
+
+	s.duplicateService.Remember(ctx, fingerprint)
+	return saved, nil
 }
 
-func (s *PostService) GetPostByID(ctx context.Context, id string) (*domain.Post, error) {
-	return s.postRepo.FindByID(ctx, id)
+// The viewer is the session ID of the requester, shadow-banned threads are
+// only shown to their own poster. The opening post gets its poster ID like
+// the comments below it.
+
+func (s *PostService) GetPostByID(ctx context.Context, id string, viewer string) (*domain.Post, error) {
+	post, err := s.postRepo.FindByID(ctx, id, viewer, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if sessionID := post.User.SessionID; sessionID != "" {
+		post.PosterID = domain.PosterID(s.posterSecret, post.ID, sessionID)
+	}
+	return post, nil
 }
 
-func (s *PostService) GetActivePosts(ctx context.Context) ([]*domain.Post, error) {
-	return s.postRepo.FindActive(ctx)
+func (s *PostService) GetActivePosts(ctx context.Context, viewer string) ([]*domain.Post, error) {
+	return s.postRepo.FindActive(ctx, viewer)
 }
 
-func (s *PostService) GetArchivedPosts(ctx context.Context) ([]*domain.Post, error) {
-	return s.postRepo.FindArchived(ctx)
+func (s *PostService) GetArchivedPosts(ctx context.Context, viewer string) ([]*domain.Post, error) {
+	return s.postRepo.FindArchived(ctx, viewer)
 }
 
 func (s *PostService) ArchivePosts(ctx context.Context) error {
 	return s.postRepo.ArchiveOldPosts(ctx)
 }
+
+// Delete a thread on behalf of its author, allowed only shortly after posting
+
+func (s *PostService) DeletePostByPoster(ctx context.Context, id string, sessionID string) error {
+	post, err := s.postRepo.FindByID(ctx, id, sessionID, false)
+	if err != nil {
+		return err
+	}
+
+	if post.User.SessionID != sessionID {
+		return domain.ErrForbidden
+	}
+
+	if time.Since(post.CreatedAt) > domain.PosterDeleteWindow {
+		return domain.ErrDeleteWindowExpired
+	}
+
+	slog.Info("Poster deleted thread", "post", id)
+	return s.postRepo.SoftDelete(ctx, id, domain.DeletedByPoster, "")
+}
+
+// Threads deleted as spam teach the spam scorer
+
+func (s *PostService) DeletePostByModerator(ctx context.Context, id string, moderator *domain.Moderator, reason string, spam bool) error {
+	post, err := s.postRepo.FindByID(ctx, id, "", true)
+	if err != nil {
+		return err
+	}
+
+	if !moderator.HasRole(post.Board, domain.RoleJanitor) {
+		return domain.ErrForbidden
+	}
+
+	if err := s.postRepo.SoftDelete(ctx, id, moderator.ID, reason); err != nil {
+		return err
+	}
+	if spam {
+		trainSpam(ctx, s.spamScorer, post.Title+"\n"+post.Content, true)
+	}
+
+	slog.Info("Moderator deleted thread", "post", id, "spam", spam, "moderator", moderator.Username)
+	return s.auditService.Record(ctx, moderator, "thread.delete", domain.TargetPost, id, reason, post, nil)
+}
+
+// Turn a sticky, locked or cyclical flag of a thread on or off
+
+func (s *PostService) SetThreadFlag(ctx context.Context, id string, moderator *domain.Moderator, flag domain.ThreadFlag, on bool, reason string) error {
+	post, err := s.postRepo.FindByID(ctx, id, "", true)
+	if err != nil {
+		return err
+	}
+
+	if !moderator.HasRole(post.Board, domain.RoleModerator) {
+		return domain.ErrForbidden
+	}
+
+	if err := s.postRepo.SetFlag(ctx, id, flag, on); err != nil {
+		return err
+	}
+
+	action := "thread." + string(flag)
+	if !on {
+		action = "thread.un" + string(flag)
+	}
+
+	slog.Info("Moderator changed thread flag", "post", id, "flag", flag, "on", on, "moderator", moderator.Username)
+	before := map[domain.ThreadFlag]bool{flag: post.HasFlag(flag)}
+	after := map[domain.ThreadFlag]bool{flag: on}
+	return s.auditService.Record(ctx, moderator, action, domain.TargetPost, id, reason, before, after)
+}
+
+// Bring an archived thread back to the catalog, it gets a fresh inactivity
+// period before it can be archived again
+
+func (s *PostService) UnarchivePost(ctx context.Context, id string, moderator *domain.Moderator, reason string) error {
+	post, err := s.postRepo.FindByID(ctx, id, "", true)
+	if err != nil {
+		return err
+	}
+
+	if !moderator.HasRole(post.Board, domain.RoleModerator) {
+		return domain.ErrForbidden
+	}
+
+	if !post.IsArchived {
+		return domain.ErrNotArchived
+	}
+
+	if err := s.postRepo.Unarchive(ctx, id); err != nil {
+		return err
+	}
+
+	slog.Info("Moderator unarchived thread", "post", id, "moderator", moderator.Username)
+
+	before := map[string]any{"is_archived": true, "archived_at": post.ArchivedAt}
+	after := map[string]any{"is_archived": false}
+	return s.auditService.Record(ctx, moderator, "thread.unarchive", domain.TargetPost, id, reason, before, after)
+}
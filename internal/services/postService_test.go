@@ -6,6 +6,7 @@ import (
 	"mime/multipart"
 	"reflect"
 	"testing"
+	"time"
 
 	"1337b04rd/internal/domain"
 )
@@ -15,14 +16,21 @@ import (
 // --------------------
 
 type MockPostRepo struct {
-	savedPost  *domain.Post
-	savePost   *domain.Post
-	saveErr    error
-	findPost   *domain.Post
-	findErr    error
-	active     []*domain.Post
-	archived   []*domain.Post
-	archiveErr error
+	savedPost    *domain.Post
+	savePost     *domain.Post
+	saveErr      error
+	findPost     *domain.Post
+	findErr      error
+	posts        map[string]*domain.Post // Looked up by ID before falling back to findPost
+	active       []*domain.Post
+	archived     []*domain.Post
+	archiveErr   error
+	deletedID    string
+	deletedBy    string
+	flag         domain.ThreadFlag
+	flagOn       bool
+	unarchivedID string
+	hidden       bool // includeHidden of the last FindByID
 }
 
 func (m *MockPostRepo) Save(ctx context.Context, post *domain.Post) (*domain.Post, error) {
@@ -30,15 +38,19 @@ func (m *MockPostRepo) Save(ctx context.Context, post *domain.Post) (*domain.Pos
 	return m.savePost, m.saveErr
 }
 
-func (m *MockPostRepo) FindByID(ctx context.Context, id string) (*domain.Post, error) {
+func (m *MockPostRepo) FindByID(ctx context.Context, id string, viewer string, includeHidden bool) (*domain.Post, error) {
+	m.hidden = includeHidden
+	if post, ok := m.posts[id]; ok {
+		return post, nil
+	}
 	return m.findPost, m.findErr
 }
 
-func (m *MockPostRepo) FindActive(ctx context.Context) ([]*domain.Post, error) {
+func (m *MockPostRepo) FindActive(ctx context.Context, viewer string) ([]*domain.Post, error) {
 	return m.active, m.findErr
 }
 
-func (m *MockPostRepo) FindArchived(ctx context.Context) ([]*domain.Post, error) {
+func (m *MockPostRepo) FindArchived(ctx context.Context, viewer string) ([]*domain.Post, error) {
 	return m.archived, m.findErr
 }
 
@@ -46,6 +58,23 @@ func (m *MockPostRepo) ArchiveOldPosts(ctx context.Context) error {
 	return m.archiveErr
 }
 
+func (m *MockPostRepo) SetFlag(ctx context.Context, id string, flag domain.ThreadFlag, on bool) error {
+	m.flag = flag
+	m.flagOn = on
+	return nil
+}
+
+func (m *MockPostRepo) Unarchive(ctx context.Context, id string) error {
+	m.unarchivedID = id
+	return nil
+}
+
+func (m *MockPostRepo) SoftDelete(ctx context.Context, id string, deletedBy string, reason string) error {
+	m.deletedID = id
+	m.deletedBy = deletedBy
+	return nil
+}
+
 // --------------------
 // Tests
 // --------------------
@@ -56,9 +85,9 @@ func TestCreatePost_Success(t *testing.T) {
 	mockFileUtils := &MockFileUtils{bytes: []byte("img")}
 	mockUserRepo := &MockUserRepo{findUser: &domain.User{SessionID: "u1", Username: "Test User"}}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, "bucket123")
+	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, "bucket123", nil)
 
 	req := &domain.CreatePostReq{
 		Title:     "Post title",
@@ -82,15 +111,40 @@ func TestCreatePost_Success(t *testing.T) {
 	}
 }
 
+func TestCreatePost_FilteredAndHeld(t *testing.T) {
+	mockRepo := &MockPostRepo{savePost: &domain.Post{Title: "ok"}}
+	mockUserRepo := &MockUserRepo{findUser: &domain.User{SessionID: "u1", CreatedAt: time.Now().Add(-time.Hour)}}
+	realUserService := *NewUserService(mockUserRepo, &MockUserOutlookAPI{}, nil, false, AuditService{})
+	filters := &MockWordFilterRepo{filters: []*domain.WordFilter{
+		{ID: "1", Board: domain.AllBoards, Pattern: "tbh", Action: domain.FilterReplace, Replacement: "desu"},
+		{ID: "2", Board: "b", Pattern: "casino", Action: domain.FilterHold},
+	}}
+
+	svc := NewPostService(mockRepo, nil, nil, realUserService, ImageService{}, AuditService{}, *NewWordFilterService(filters, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, "bucket123", nil)
+
+	_, err := svc.CreatePost(context.Background(), &domain.CreatePostReq{Title: "Tbh, great", Content: "best сasino online", SessionID: "u1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved := mockRepo.savedPost
+	if saved.Title != "desu, great" {
+		t.Errorf("expected filtered title, got %q", saved.Title)
+	}
+	if saved.Status != domain.StatusPending || saved.PendingReason != domain.PendingWordFilter {
+		t.Errorf("expected post held by the word filter, got %s (%q)", saved.Status, saved.PendingReason)
+	}
+}
+
 func TestCreatePost_ValidateImageFails(t *testing.T) {
 	mockRepo := &MockPostRepo{}
 	mockImageStorage := &MockImageStorage{}
 	mockFileUtils := &MockFileUtils{validateErr: errors.New("invalid image")}
 	mockUserRepo := &MockUserRepo{}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, "bucket123")
+	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, "bucket123", nil)
 
 	req := &domain.CreatePostReq{ImageData: []*multipart.FileHeader{{Filename: "img.png"}}}
 
@@ -106,9 +160,9 @@ func TestCreatePost_FileHeaderToBytesFails(t *testing.T) {
 	mockFileUtils := &MockFileUtils{bytesErr: errors.New("bad bytes")}
 	mockUserRepo := &MockUserRepo{}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, "bucket123")
+	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, "bucket123", nil)
 
 	req := &domain.CreatePostReq{ImageData: []*multipart.FileHeader{{Filename: "img.png"}}}
 
@@ -124,9 +178,9 @@ func TestCreatePost_StoreFails(t *testing.T) {
 	mockFileUtils := &MockFileUtils{bytes: []byte("ok")}
 	mockUserRepo := &MockUserRepo{}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, "bucket123")
+	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, "bucket123", nil)
 
 	req := &domain.CreatePostReq{ImageData: []*multipart.FileHeader{{Filename: "img.png"}}}
 
@@ -142,9 +196,9 @@ func TestCreatePost_FindUserFails(t *testing.T) {
 	mockFileUtils := &MockFileUtils{bytes: []byte("ok")}
 	mockUserRepo := &MockUserRepo{findErr: errors.New("no user")}
 	mockOutlook := &MockUserOutlookAPI{}
-	realUserService := *NewUserService(mockUserRepo, mockOutlook)
+	realUserService := *NewUserService(mockUserRepo, mockOutlook, nil, false, AuditService{})
 
-	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, "bucket123")
+	svc := NewPostService(mockRepo, mockImageStorage, mockFileUtils, realUserService, *NewImageService(&MockImageRepo{}, nil, *NewBanService(&MockBanRepo{}, AuditService{})), AuditService{}, *NewWordFilterService(&MockWordFilterRepo{}, AuditService{}), *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), *NewDuplicateService(&MockFingerprintRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{})), *NewLinkPolicyService(&MockLinkRuleRepo{}, *NewBoardService(&MockBoardSettingsRepo{}, AuditService{}), AuditService{}), &MockSpamScorer{}, "bucket123", nil)
 
 	req := &domain.CreatePostReq{SessionID: "u1", ImageData: []*multipart.FileHeader{{Filename: "img.png"}}}
 
@@ -158,24 +212,42 @@ func TestGetPostByID_Success(t *testing.T) {
 	expected := &domain.Post{Title: "test"}
 	mockRepo := &MockPostRepo{findPost: expected}
 
-	svc := NewPostService(mockRepo, nil, nil, UserService{}, "")
+	svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, "", nil)
 
-	got, err := svc.GetPostByID(context.Background(), "id")
+	got, err := svc.GetPostByID(context.Background(), "id", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if !reflect.DeepEqual(got, expected) {
 		t.Errorf("expected %+v, got %+v", expected, got)
 	}
+	if mockRepo.hidden {
+		t.Error("expected readers not to see hidden threads")
+	}
+}
+
+func TestGetPostByID_PosterID(t *testing.T) {
+	mockRepo := &MockPostRepo{findPost: &domain.Post{ID: "p1", User: domain.User{SessionID: "s1"}}}
+	svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, "", []byte("secret"))
+
+	for _, viewer := range []string{"s1", "s2"} {
+		got, err := svc.GetPostByID(context.Background(), "p1", viewer)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.PosterID != domain.PosterID([]byte("secret"), "p1", "s1") {
+			t.Errorf("expected every viewer to see the poster's ID, got %q", got.PosterID)
+		}
+	}
 }
 
 func TestGetActivePosts_Success(t *testing.T) {
 	expected := []*domain.Post{{Title: "p1"}}
 	mockRepo := &MockPostRepo{active: expected}
 
-	svc := NewPostService(mockRepo, nil, nil, UserService{}, "")
+	svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, "", nil)
 
-	got, err := svc.GetActivePosts(context.Background())
+	got, err := svc.GetActivePosts(context.Background(), "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -188,9 +260,9 @@ func TestGetArchivedPosts_Success(t *testing.T) {
 	expected := []*domain.Post{{Title: "archived"}}
 	mockRepo := &MockPostRepo{archived: expected}
 
-	svc := NewPostService(mockRepo, nil, nil, UserService{}, "")
+	svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, "", nil)
 
-	got, err := svc.GetArchivedPosts(context.Background())
+	got, err := svc.GetArchivedPosts(context.Background(), "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -202,7 +274,7 @@ func TestGetArchivedPosts_Success(t *testing.T) {
 func TestArchivePosts_Success(t *testing.T) {
 	mockRepo := &MockPostRepo{}
 
-	svc := NewPostService(mockRepo, nil, nil, UserService{}, "")
+	svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, "", nil)
 
 	if err := svc.ArchivePosts(context.Background()); err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -212,9 +284,123 @@ func TestArchivePosts_Success(t *testing.T) {
 func TestArchivePosts_Error(t *testing.T) {
 	mockRepo := &MockPostRepo{archiveErr: errors.New("archive fail")}
 
-	svc := NewPostService(mockRepo, nil, nil, UserService{}, "")
+	svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, "", nil)
 
 	if err := svc.ArchivePosts(context.Background()); err == nil || err.Error() != "archive fail" {
 		t.Fatalf("expected 'archive fail', got %v", err)
 	}
 }
+
+func TestDeletePostByPoster(t *testing.T) {
+	tests := []struct {
+		name      string
+		post      *domain.Post
+		sessionID string
+		err       error
+	}{
+		{
+			name:      "own fresh post",
+			post:      &domain.Post{ID: "p1", User: domain.User{SessionID: "u1"}, CreatedAt: time.Now()},
+			sessionID: "u1",
+		},
+		{
+			name:      "someone else's post",
+			post:      &domain.Post{ID: "p1", User: domain.User{SessionID: "u2"}, CreatedAt: time.Now()},
+			sessionID: "u1",
+			err:       domain.ErrForbidden,
+		},
+		{
+			name:      "window expired",
+			post:      &domain.Post{ID: "p1", User: domain.User{SessionID: "u1"}, CreatedAt: time.Now().Add(-time.Hour)},
+			sessionID: "u1",
+			err:       domain.ErrDeleteWindowExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockPostRepo{findPost: tt.post}
+			svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, AuditService{}, WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, "", nil)
+
+			err := svc.DeletePostByPoster(context.Background(), "p1", tt.sessionID)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if tt.err == nil && mockRepo.deletedBy != domain.DeletedByPoster {
+				t.Errorf("expected post to be deleted by poster, got %q", mockRepo.deletedBy)
+			}
+			if tt.err != nil && mockRepo.deletedID != "" {
+				t.Errorf("expected post not to be deleted")
+			}
+		})
+	}
+}
+
+func TestDeletePostByModerator_BoardScope(t *testing.T) {
+	mockRepo := &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "g", Title: "Cheap pills", Content: "buy now"}}
+	scorer := &MockSpamScorer{}
+	svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, *NewAuditService(&MockAuditRepo{}), WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, scorer, "", nil)
+
+	janitorOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleJanitor}}}
+	if err := svc.DeletePostByModerator(context.Background(), "p1", janitorOfB, "spam", true); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+
+	janitorOfG := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: "g", Role: domain.RoleJanitor}}}
+	if err := svc.DeletePostByModerator(context.Background(), "p1", janitorOfG, "spam", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockRepo.deletedBy != "m2" {
+		t.Errorf("expected deleted_by to be the moderator, got %q", mockRepo.deletedBy)
+	}
+	if !mockRepo.hidden {
+		t.Error("expected moderators to look up hidden threads too")
+	}
+	if len(scorer.spam) != 1 || scorer.spam[0] != "Cheap pills\nbuy now" {
+		t.Errorf("expected the deleted thread to be learned as spam, got %q", scorer.spam)
+	}
+}
+
+func TestSetThreadFlag(t *testing.T) {
+	mockRepo := &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "g"}}
+	auditRepo := &MockAuditRepo{}
+	svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, *NewAuditService(auditRepo), WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, "", nil)
+
+	janitorOfG := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "g", Role: domain.RoleJanitor}}}
+	if err := svc.SetThreadFlag(context.Background(), "p1", janitorOfG, domain.FlagSticky, true, ""); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+
+	moderatorOfG := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: "g", Role: domain.RoleModerator}}}
+	if err := svc.SetThreadFlag(context.Background(), "p1", moderatorOfG, domain.FlagLocked, false, "calmed down"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockRepo.flag != domain.FlagLocked || mockRepo.flagOn {
+		t.Errorf("expected lock to be turned off, got %q %v", mockRepo.flag, mockRepo.flagOn)
+	}
+	if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != "thread.unlocked" {
+		t.Errorf("unexpected audit entries %+v", auditRepo.entries)
+	}
+}
+
+func TestUnarchivePost(t *testing.T) {
+	mockRepo := &MockPostRepo{findPost: &domain.Post{ID: "p1", Board: "g"}}
+	auditRepo := &MockAuditRepo{}
+	svc := NewPostService(mockRepo, nil, nil, UserService{}, ImageService{}, *NewAuditService(auditRepo), WordFilterService{}, BoardService{}, DuplicateService{}, LinkPolicyService{}, nil, "", nil)
+	moderator := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "g", Role: domain.RoleModerator}}}
+
+	if err := svc.UnarchivePost(context.Background(), "p1", moderator, ""); !errors.Is(err, domain.ErrNotArchived) {
+		t.Fatalf("expected ErrNotArchived, got %v", err)
+	}
+
+	mockRepo.findPost.IsArchived = true
+	if err := svc.UnarchivePost(context.Background(), "p1", moderator, "still relevant"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockRepo.unarchivedID != "p1" {
+		t.Errorf("expected p1 to be unarchived")
+	}
+	if len(auditRepo.entries) != 1 || string(auditRepo.entries[0].After) != `{"is_archived":false}` {
+		t.Errorf("unexpected audit entries %+v", auditRepo.entries)
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"1337b04rd/internal/domain"
+)
+
+type QueueService struct {
+	queueRepo    domain.QueueRepository
+	auditService AuditService
+}
+
+func NewQueueService(queueRepo domain.QueueRepository, auditService AuditService) *QueueService {
+	return &QueueService{
+		queueRepo:    queueRepo,
+		auditService: auditService,
+	}
+}
+
+// Pending posts and comments of the boards the moderator works on, oldest first
+
+func (s *QueueService) ListPending(ctx context.Context, moderator *domain.Moderator) ([]*domain.PendingItem, error) {
+	items, err := s.queueRepo.FindPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*domain.PendingItem, 0, len(items))
+	for _, item := range items {
+		if moderator.HasRole(item.Board, domain.RoleJanitor) {
+			visible = append(visible, item)
+		}
+	}
+	return visible, nil
+}
+
+func (s *QueueService) Approve(ctx context.Context, moderator *domain.Moderator, targetType domain.TargetType, id string, reason string) error {
+	return s.decide(ctx, moderator, targetType, id, domain.StatusPublished, reason)
+}
+
+func (s *QueueService) Reject(ctx context.Context, moderator *domain.Moderator, targetType domain.TargetType, id string, reason string) error {
+	return s.decide(ctx, moderator, targetType, id, domain.StatusRejected, reason)
+}
+
+func (s *QueueService) decide(ctx context.Context, moderator *domain.Moderator, targetType domain.TargetType, id string, status domain.ContentStatus, reason string) error {
+	if !targetType.Reportable() {
+		return domain.ErrInvalidReportTarget
+	}
+
+	item, err := s.queueRepo.FindPendingItem(ctx, targetType, id)
+	if err != nil {
+		return err
+	}
+
+	if !moderator.HasRole(item.Board, domain.RoleJanitor) {
+		return domain.ErrForbidden
+	}
+
+	if err := s.queueRepo.SetStatus(ctx, targetType, id, status); err != nil {
+		return err
+	}
+
+	// Same action names as the other thread and comment actions
+	action := "thread."
+	if targetType == domain.TargetComment {
+		action = "comment."
+	}
+	if status == domain.StatusRejected {
+		action += "reject"
+	} else {
+		action += "approve"
+	}
+
+	slog.Info("Moderator decided on pending content", "target_type", targetType, "target", id, "status", status, "moderator", moderator.Username)
+
+	return s.auditService.Record(ctx, moderator, action, targetType, id, reason,
+		map[string]any{"status": domain.StatusPending, "pending_reason": item.Reason},
+		map[string]any{"status": status})
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for QueueService dependencies
+// --------------------
+
+type MockQueueRepo struct {
+	items  []*domain.PendingItem
+	status map[string]domain.ContentStatus
+}
+
+func (m *MockQueueRepo) FindPending(ctx context.Context) ([]*domain.PendingItem, error) {
+	return m.items, nil
+}
+
+func (m *MockQueueRepo) FindPendingItem(ctx context.Context, targetType domain.TargetType, id string) (*domain.PendingItem, error) {
+	for _, item := range m.items {
+		if item.TargetType == targetType && item.TargetID == id {
+			if _, decided := m.status[id]; decided {
+				break
+			}
+			return item, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockQueueRepo) SetStatus(ctx context.Context, targetType domain.TargetType, id string, status domain.ContentStatus) error {
+	if m.status == nil {
+		m.status = map[string]domain.ContentStatus{}
+	}
+	m.status[id] = status
+	return nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func pendingQueue() *MockQueueRepo {
+	return &MockQueueRepo{items: []*domain.PendingItem{
+		{TargetType: domain.TargetPost, TargetID: "p1", PostID: "p1", Board: "b", Reason: domain.PendingNewSession},
+		{TargetType: domain.TargetComment, TargetID: "c1", PostID: "p2", Board: "g", Reason: domain.PendingWordFilter},
+	}}
+}
+
+func TestListPending_BoardScope(t *testing.T) {
+	svc := NewQueueService(pendingQueue(), AuditService{})
+	janitorOfB := &domain.Moderator{Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleJanitor}}}
+
+	items, err := svc.ListPending(context.Background(), janitorOfB)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 || items[0].TargetID != "p1" {
+		t.Errorf("expected only the thread of /b/, got %+v", items)
+	}
+}
+
+func TestApproveAndReject(t *testing.T) {
+	repo := pendingQueue()
+	auditRepo := &MockAuditRepo{}
+	svc := NewQueueService(repo, *NewAuditService(auditRepo))
+	janitorOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleJanitor}}}
+	global := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleJanitor}}}
+
+	if err := svc.Approve(context.Background(), janitorOfB, domain.TargetComment, "c1", ""); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden on another board, got %v", err)
+	}
+	if err := svc.Approve(context.Background(), janitorOfB, "user", "p1", ""); !errors.Is(err, domain.ErrInvalidReportTarget) {
+		t.Fatalf("expected ErrInvalidReportTarget, got %v", err)
+	}
+
+	if err := svc.Approve(context.Background(), janitorOfB, domain.TargetPost, "p1", "looks fine"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Reject(context.Background(), global, domain.TargetComment, "c1", "spam"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.status["p1"] != domain.StatusPublished || repo.status["c1"] != domain.StatusRejected {
+		t.Errorf("unexpected statuses %v", repo.status)
+	}
+	if len(auditRepo.entries) != 2 || auditRepo.entries[0].Action != "thread.approve" || auditRepo.entries[1].Action != "comment.reject" {
+		t.Errorf("expected audited thread.approve and comment.reject, got %+v", auditRepo.entries)
+	}
+
+	// Decided items leave the queue
+	if err := svc.Reject(context.Background(), global, domain.TargetPost, "p1", ""); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a decided thread, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"1337b04rd/internal/domain"
+)
+
+type RateLimiter struct {
+	store  domain.RateLimitStore
+	limits domain.RateLimits
+}
+
+func NewRateLimiter(store domain.RateLimitStore, limits domain.RateLimits) *RateLimiter {
+	return &RateLimiter{
+		store:  store,
+		limits: limits,
+	}
+}
+
+// Count a new thread or reply against the limits of the session and of the
+// address, and against the cooldown of the session. A post refused by one of
+// them takes nothing from the others.
+
+func (l *RateLimiter) AllowPost(ctx context.Context, sessionID string, ip string, action domain.RateAction) error {
+	var buckets []rateBucket
+	if sessionID != "" {
+		buckets = append(buckets, rateBucket{domain.RateCooldown, "session:" + sessionID})
+	}
+	return l.takeAll(ctx, append(buckets, bothBuckets(action, sessionID, ip)...), 1)
+}
+
+// Count the images attached to a post. More images than the bucket holds
+// could never be taken, so they are rejected outright instead of rate limited.
+
+func (l *RateLimiter) AllowImages(ctx context.Context, sessionID string, ip string, count int) error {
+	if count == 0 {
+		return nil
+	}
+	if limit, ok := l.limits[domain.RateImage]; ok && count > limit.Burst {
+		return domain.ErrTooManyImages
+	}
+	return l.takeAll(ctx, bothBuckets(domain.RateImage, sessionID, ip), count)
+}
+
+type rateBucket struct {
+	action domain.RateAction
+	key    string
+}
+
+func bothBuckets(action domain.RateAction, sessionID string, ip string) []rateBucket {
+	var buckets []rateBucket
+	if sessionID != "" {
+		buckets = append(buckets, rateBucket{action, "session:" + sessionID})
+	}
+	if ip != "" {
+		buckets = append(buckets, rateBucket{action, "ip:" + ip})
+	}
+	return buckets
+}
+
+// Take n tokens from every bucket, on a refusal the buckets already counted
+// get their tokens back
+func (l *RateLimiter) takeAll(ctx context.Context, buckets []rateBucket, n int) error {
+	for i, bucket := range buckets {
+		if err := l.take(ctx, bucket.action, bucket.key, n); err != nil {
+			l.giveBack(ctx, buckets[:i], n)
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *RateLimiter) take(ctx context.Context, action domain.RateAction, key string, n int) error {
+	limit, ok := l.limits[action]
+	if !ok {
+		return nil
+	}
+
+	retryAfter, err := l.store.Take(ctx, string(action)+":"+key, limit, n)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		slog.Warn("Rate limit hit", "action", action, "key", key, "retry_after", retryAfter)
+		return &domain.RateLimitError{Action: action, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+func (l *RateLimiter) giveBack(ctx context.Context, buckets []rateBucket, n int) {
+	for _, bucket := range buckets {
+		limit, ok := l.limits[bucket.action]
+		if !ok {
+			continue
+		}
+		if err := l.store.Give(ctx, string(bucket.action)+":"+bucket.key, limit, n); err != nil {
+			slog.Error("Failed to give back rate limit tokens", "action", bucket.action, "key", bucket.key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for RateLimiter dependencies
+// --------------------
+
+// Buckets that never refill, time stands still in the tests
+type MockRateLimitStore struct {
+	buckets map[string]domain.RateBucket
+	now     time.Time
+}
+
+func (m *MockRateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit, n int) (time.Duration, error) {
+	if m.buckets == nil {
+		m.buckets = map[string]domain.RateBucket{}
+		m.now = time.Now()
+	}
+	bucket, wait := limit.Take(m.buckets[key], m.now, n)
+	if wait == 0 {
+		m.buckets[key] = bucket
+	}
+	return wait, nil
+}
+
+func (m *MockRateLimitStore) Give(ctx context.Context, key string, limit domain.RateLimit, n int) error {
+	if bucket, ok := m.buckets[key]; ok {
+		m.buckets[key] = limit.Give(bucket, n)
+	}
+	return nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestAllowPost_SessionAndIP(t *testing.T) {
+	limits := domain.RateLimits{
+		domain.RateThread: {Burst: 2, Every: time.Minute},
+	}
+	svc := NewRateLimiter(&MockRateLimitStore{}, limits)
+
+	if err := svc.AllowPost(context.Background(), "s1", "203.0.113.7", domain.RateThread); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.AllowPost(context.Background(), "s2", "203.0.113.7", domain.RateThread); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A new session from the same address is still limited by the address
+	err := svc.AllowPost(context.Background(), "s3", "203.0.113.7", domain.RateThread)
+	var rateErr *domain.RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if rateErr.RetrySeconds() != 60 || rateErr.Action != domain.RateThread {
+		t.Errorf("expected to retry threads in 60s, got %+v", rateErr)
+	}
+
+	// Replies have no limit configured here
+	if err := svc.AllowPost(context.Background(), "s3", "203.0.113.7", domain.RateReply); err != nil {
+		t.Errorf("expected replies to pass, got %v", err)
+	}
+}
+
+func TestAllowPost_Cooldown(t *testing.T) {
+	limits := domain.RateLimits{
+		domain.RateReply:    {Burst: 10, Every: time.Second},
+		domain.RateCooldown: {Burst: 1, Every: 10 * time.Second},
+	}
+	svc := NewRateLimiter(&MockRateLimitStore{}, limits)
+
+	if err := svc.AllowPost(context.Background(), "s1", "203.0.113.7", domain.RateReply); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rateErr *domain.RateLimitError
+	err := svc.AllowPost(context.Background(), "s1", "203.0.113.8", domain.RateReply)
+	if !errors.As(err, &rateErr) || rateErr.Action != domain.RateCooldown {
+		t.Fatalf("expected the session cooldown, got %v", err)
+	}
+
+	if err := svc.AllowPost(context.Background(), "s2", "203.0.113.7", domain.RateReply); err != nil {
+		t.Errorf("expected another session to pass, got %v", err)
+	}
+}
+
+func TestAllowPost_RefusedTakesNothing(t *testing.T) {
+	limits := domain.RateLimits{
+		domain.RateThread: {Burst: 2, Every: time.Minute},
+	}
+	store := &MockRateLimitStore{}
+	svc := NewRateLimiter(store, limits)
+
+	for _, sessionID := range []string{"s1", "s2"} {
+		if err := svc.AllowPost(context.Background(), sessionID, "203.0.113.7", domain.RateThread); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The address is out of tokens, the session bucket must stay as it was
+	before := store.buckets["thread:session:s1"]
+	var rateErr *domain.RateLimitError
+	if err := svc.AllowPost(context.Background(), "s1", "203.0.113.7", domain.RateThread); !errors.As(err, &rateErr) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if after := store.buckets["thread:session:s1"]; after.Tokens != before.Tokens {
+		t.Errorf("expected the session to keep %v tokens, got %v", before.Tokens, after.Tokens)
+	}
+
+	if err := svc.AllowPost(context.Background(), "s1", "203.0.113.8", domain.RateThread); err != nil {
+		t.Errorf("expected the session to post from another address, got %v", err)
+	}
+}
+
+func TestAllowImages(t *testing.T) {
+	limits := domain.RateLimits{
+		domain.RateImage: {Burst: 4, Every: time.Minute},
+	}
+	svc := NewRateLimiter(&MockRateLimitStore{}, limits)
+
+	if err := svc.AllowImages(context.Background(), "s1", "203.0.113.7", 0); err != nil {
+		t.Fatalf("unexpected error for no images: %v", err)
+	}
+	if err := svc.AllowImages(context.Background(), "s1", "203.0.113.7", 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rateErr *domain.RateLimitError
+	if err := svc.AllowImages(context.Background(), "s1", "203.0.113.7", 2); !errors.As(err, &rateErr) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if rateErr.RetrySeconds() != 60 {
+		t.Errorf("expected to wait 60s for the missing image token, got %d", rateErr.RetrySeconds())
+	}
+}
+
+func TestAllowImages_OverBurst(t *testing.T) {
+	limits := domain.RateLimits{
+		domain.RateImage: {Burst: 4, Every: time.Minute},
+	}
+	svc := NewRateLimiter(&MockRateLimitStore{}, limits)
+
+	err := svc.AllowImages(context.Background(), "s1", "203.0.113.7", 5)
+	if !errors.Is(err, domain.ErrTooManyImages) {
+		t.Fatalf("expected ErrTooManyImages, got %v", err)
+	}
+
+	var rateErr *domain.RateLimitError
+	if errors.As(err, &rateErr) {
+		t.Fatal("an upload over the burst must not be retried")
+	}
+
+	// The rejected upload took no tokens
+	if err := svc.AllowImages(context.Background(), "s1", "203.0.113.7", 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"unicode/utf8"
+
+	"1337b04rd/internal/domain"
+)
+
+type ReportService struct {
+	reportRepo   domain.ReportRepository
+	postRepo     domain.PostRepository
+	commentRepo  domain.CommentRepository
+	banService   BanService
+	auditService AuditService
+	spamScorer   domain.SpamScorer
+}
+
+func NewReportService(reportRepo domain.ReportRepository, postRepo domain.PostRepository, commentRepo domain.CommentRepository, banService BanService, auditService AuditService, spamScorer domain.SpamScorer) *ReportService {
+	return &ReportService{
+		reportRepo:   reportRepo,
+		postRepo:     postRepo,
+		commentRepo:  commentRepo,
+		banService:   banService,
+		auditService: auditService,
+		spamScorer:   spamScorer,
+	}
+}
+
+// File a report against a visible post or comment, one open report per
+// session and target
+
+func (s *ReportService) CreateReport(ctx context.Context, sessionID string, req *domain.CreateReportReq) error {
+	if !req.TargetType.Reportable() {
+		return domain.ErrInvalidReportTarget
+	}
+	if !req.Category.Valid() {
+		return domain.ErrInvalidReportCategory
+	}
+	if utf8.RuneCountInString(req.Note) > domain.ReportNoteMaxLength {
+		return domain.ErrReportNoteTooLong
+	}
+
+	var err error
+	switch req.TargetType {
+	case domain.TargetPost:
+		_, err = s.postRepo.FindByID(ctx, req.TargetID, sessionID, false)
+	case domain.TargetComment:
+		_, err = s.commentRepo.FindByID(ctx, req.TargetID)
+	}
+	if err != nil {
+		return err
+	}
+
+	report := &domain.Report{
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		SessionID:  sessionID,
+		Category:   req.Category,
+		Note:       req.Note,
+	}
+
+	return s.reportRepo.Save(ctx, report)
+}
+
+// Open reports grouped by target, limited to the boards the moderator works on
+
+func (s *ReportService) ListOpenReports(ctx context.Context, moderator *domain.Moderator) ([]*domain.ReportSummary, error) {
+	summaries, err := s.reportRepo.FindOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*domain.ReportSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if moderator.HasRole(summary.Board, domain.RoleJanitor) {
+			visible = append(visible, summary)
+		}
+	}
+	return visible, nil
+}
+
+// Apply the action to the target and close all of its open reports, returns
+// how many reports were resolved. Dismissed reports teach the spam scorer that
+// the content was fine, content deleted as spam that it was spam.
+
+func (s *ReportService) ResolveReports(ctx context.Context, moderator *domain.Moderator, targetType domain.TargetType, targetID string, req *domain.ResolveReportsReq) (int, error) {
+	if !targetType.Reportable() {
+		return 0, domain.ErrInvalidReportTarget
+	}
+
+	target, err := s.reportRepo.FindTarget(ctx, targetType, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	resolution := &domain.ReportResolution{
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Action:      req.Action,
+		ModeratorID: moderator.ID,
+		Reason:      req.Reason,
+	}
+
+	switch req.Action {
+	case domain.ReportDismiss, domain.ReportDelete:
+		if !moderator.HasRole(target.Board, domain.RoleJanitor) {
+			return 0, domain.ErrForbidden
+		}
+	case domain.ReportBan:
+		if !moderator.HasRole(domain.AllBoards, domain.RoleModerator) {
+			return 0, domain.ErrForbidden
+		}
+		resolution.Bans, err = s.authorBans(moderator, target, req)
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, domain.ErrInvalidReportAction
+	}
+
+	resolved, err := s.reportRepo.Resolve(ctx, resolution)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, ban := range resolution.Bans {
+		if err := s.banService.recordCreated(ctx, moderator, ban); err != nil {
+			return resolved, err
+		}
+	}
+
+	if req.Action == domain.ReportDismiss {
+		trainSpam(ctx, s.spamScorer, target.Text, false)
+	} else if req.Spam {
+		trainSpam(ctx, s.spamScorer, target.Text, true)
+	}
+
+	slog.Info("Moderator resolved reports", "target", targetID, "action", req.Action, "count", resolved, "moderator", moderator.Username)
+
+	if err := s.auditService.Record(ctx, moderator, "report."+string(req.Action), targetType, targetID, req.Reason, nil, map[string]any{"resolved_reports": resolved}); err != nil {
+		return resolved, err
+	}
+	return resolved, nil
+}
+
+// Bans of the session and the address the reported content was posted
+// from, created together with the resolution
+
+func (s *ReportService) authorBans(moderator *domain.Moderator, target *domain.ReportTarget, req *domain.ResolveReportsReq) ([]*domain.Ban, error) {
+	var bans []*domain.Ban
+	for _, ban := range []domain.CreateBanReq{
+		{Kind: domain.BanSession, Value: target.SessionID},
+		{Kind: domain.BanIP, Value: target.IP},
+	} {
+		if ban.Value == "" {
+			continue
+		}
+		ban.Reason = req.Reason
+		ban.DurationHours = req.DurationHours
+
+		created, err := s.banService.newBan(moderator, &ban)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, created)
+	}
+	return bans, nil
+}
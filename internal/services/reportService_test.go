@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for ReportService dependencies
+// --------------------
+
+type MockReportRepo struct {
+	saved      []*domain.Report
+	summaries  []*domain.ReportSummary
+	target     *domain.ReportTarget
+	resolved   int
+	resolution *domain.ReportResolution
+}
+
+func (m *MockReportRepo) Save(ctx context.Context, report *domain.Report) error {
+	for _, r := range m.saved {
+		if r.TargetID == report.TargetID && r.SessionID == report.SessionID {
+			return domain.ErrAlreadyReported
+		}
+	}
+	m.saved = append(m.saved, report)
+	return nil
+}
+
+func (m *MockReportRepo) FindOpen(ctx context.Context) ([]*domain.ReportSummary, error) {
+	return m.summaries, nil
+}
+
+func (m *MockReportRepo) FindTarget(ctx context.Context, targetType domain.TargetType, targetID string) (*domain.ReportTarget, error) {
+	return m.target, nil
+}
+
+func (m *MockReportRepo) Resolve(ctx context.Context, resolution *domain.ReportResolution) (int, error) {
+	for i, ban := range resolution.Bans {
+		ban.ID = fmt.Sprintf("ban%d", i+1)
+	}
+	m.resolution = resolution
+	return m.resolved, nil
+}
+
+type MockAuditRepo struct {
+	entries []*domain.AuditEntry
+}
+
+func (m *MockAuditRepo) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MockAuditRepo) Find(ctx context.Context, filter *domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	return m.entries, nil
+}
+
+func (m *MockAuditRepo) Each(ctx context.Context, filter *domain.AuditFilter, fn func(*domain.AuditEntry) error) error {
+	for _, entry := range m.entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestCreateReport_Validation(t *testing.T) {
+	svc := NewReportService(&MockReportRepo{}, &MockPostRepo{findPost: &domain.Post{ID: "p1"}}, &MockCommentRepo{}, BanService{}, AuditService{}, &MockSpamScorer{})
+
+	tests := []struct {
+		name string
+		req  domain.CreateReportReq
+		err  error
+	}{
+		{"bad target", domain.CreateReportReq{TargetType: "user", TargetID: "p1", Category: domain.CategorySpam}, domain.ErrInvalidReportTarget},
+		{"bad category", domain.CreateReportReq{TargetType: domain.TargetPost, TargetID: "p1", Category: "boring"}, domain.ErrInvalidReportCategory},
+		{"long note", domain.CreateReportReq{TargetType: domain.TargetPost, TargetID: "p1", Category: domain.CategoryOther, Note: strings.Repeat("a", domain.ReportNoteMaxLength+1)}, domain.ErrReportNoteTooLong},
+		{"missing comment", domain.CreateReportReq{TargetType: domain.TargetComment, TargetID: "c1", Category: domain.CategorySpam}, domain.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.CreateReport(context.Background(), "s1", &tt.req); !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCreateReport_DeduplicatedPerSession(t *testing.T) {
+	reportRepo := &MockReportRepo{}
+	svc := NewReportService(reportRepo, &MockPostRepo{findPost: &domain.Post{ID: "p1"}}, &MockCommentRepo{}, BanService{}, AuditService{}, &MockSpamScorer{})
+	req := &domain.CreateReportReq{TargetType: domain.TargetPost, TargetID: "p1", Category: domain.CategorySpam}
+
+	if err := svc.CreateReport(context.Background(), "s1", req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.CreateReport(context.Background(), "s1", req); !errors.Is(err, domain.ErrAlreadyReported) {
+		t.Fatalf("expected ErrAlreadyReported, got %v", err)
+	}
+	if err := svc.CreateReport(context.Background(), "s2", req); err != nil {
+		t.Fatalf("another session should be able to report: %v", err)
+	}
+}
+
+func TestListOpenReports_BoardScope(t *testing.T) {
+	reportRepo := &MockReportRepo{summaries: []*domain.ReportSummary{
+		{TargetID: "p1", Board: "b"},
+		{TargetID: "p2", Board: "g"},
+	}}
+	svc := NewReportService(reportRepo, &MockPostRepo{}, &MockCommentRepo{}, BanService{}, AuditService{}, &MockSpamScorer{})
+
+	janitorOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleJanitor}}}
+	summaries, err := svc.ListOpenReports(context.Background(), janitorOfB)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].TargetID != "p1" {
+		t.Errorf("expected only the report on board b, got %+v", summaries)
+	}
+}
+
+func TestResolveReports_DeleteIsAudited(t *testing.T) {
+	reportRepo := &MockReportRepo{target: &domain.ReportTarget{Board: "b"}, resolved: 3}
+	auditRepo := &MockAuditRepo{}
+	svc := NewReportService(reportRepo, &MockPostRepo{}, &MockCommentRepo{}, BanService{}, *NewAuditService(auditRepo), &MockSpamScorer{})
+
+	moderator := &domain.Moderator{ID: "m1", Username: "mod", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleJanitor}}}
+	req := &domain.ResolveReportsReq{Action: domain.ReportDelete, Reason: "spam"}
+
+	resolved, err := svc.ResolveReports(context.Background(), moderator, domain.TargetPost, "p1", req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resolution := reportRepo.resolution
+	if resolved != 3 || resolution.TargetID != "p1" || resolution.Action != domain.ReportDelete {
+		t.Errorf("expected all reports on p1 to be resolved, got %d for %+v", resolved, resolution)
+	}
+	if resolution.ModeratorID != "m1" || resolution.Reason != "spam" || len(resolution.Bans) != 0 {
+		t.Errorf("expected p1 to be deleted by m1 without bans, got %+v", resolution)
+	}
+	if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != "report.delete" {
+		t.Errorf("expected one audit entry, got %+v", auditRepo.entries)
+	}
+}
+
+func TestResolveReports_Forbidden(t *testing.T) {
+	reportRepo := &MockReportRepo{target: &domain.ReportTarget{Board: "g"}}
+	svc := NewReportService(reportRepo, &MockPostRepo{}, &MockCommentRepo{}, BanService{}, *NewAuditService(&MockAuditRepo{}), &MockSpamScorer{})
+
+	janitorOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleJanitor}}}
+	_, err := svc.ResolveReports(context.Background(), janitorOfB, domain.TargetPost, "p1", &domain.ResolveReportsReq{Action: domain.ReportDismiss})
+	if !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if reportRepo.resolution != nil {
+		t.Errorf("reports should stay open")
+	}
+}
+
+func TestResolveReports_BanAuthor(t *testing.T) {
+	reportRepo := &MockReportRepo{target: &domain.ReportTarget{Board: "b", SessionID: "0b6c3c4e-8f0a-4d6e-9a55-3a1f0c2b7d10", IP: "203.0.113.7"}, resolved: 1}
+	auditRepo := &MockAuditRepo{}
+	auditService := *NewAuditService(auditRepo)
+	svc := NewReportService(reportRepo, &MockPostRepo{}, &MockCommentRepo{}, *NewBanService(&MockBanRepo{}, auditService), auditService, &MockSpamScorer{})
+	req := &domain.ResolveReportsReq{Action: domain.ReportBan, Reason: "spam", DurationHours: 24}
+
+	janitor := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleJanitor}}}
+	if _, err := svc.ResolveReports(context.Background(), janitor, domain.TargetComment, "c1", req); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for janitor, got %v", err)
+	}
+
+	moderator := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleModerator}}}
+	if _, err := svc.ResolveReports(context.Background(), moderator, domain.TargetComment, "c1", req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The bans are created with the deletion and the resolution
+	bans := reportRepo.resolution.Bans
+	if len(bans) != 2 {
+		t.Fatalf("expected session and ip ban, got %d bans", len(bans))
+	}
+	if bans[1].Value != "203.0.113.7/32" || bans[1].ExpiresAt == nil {
+		t.Errorf("unexpected ip ban %+v", bans[1])
+	}
+	if reportRepo.resolution.TargetID != "c1" || reportRepo.resolution.Action != domain.ReportBan {
+		t.Errorf("expected the comment to be deleted, got %+v", reportRepo.resolution)
+	}
+	if len(auditRepo.entries) != 3 || auditRepo.entries[0].Action != "ban.create" || auditRepo.entries[0].TargetID != "ban1" {
+		t.Errorf("expected both bans to be audited with their IDs, got %+v", auditRepo.entries)
+	}
+}
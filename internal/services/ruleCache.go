@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Rules of other instances are picked up at most this late
+const ruleCacheTTL = time.Minute
+
+// Moderator rules checked on every post are kept in memory. The cache is
+// shared by all copies of a service and dropped on every change made through
+// it. Changes made by other instances of the server only show up once the
+// cached rules are older than ruleCacheTTL.
+type ruleCache[T any] struct {
+	mu       sync.RWMutex
+	loadedAt time.Time
+	rules    []T
+}
+
+func (c *ruleCache[T]) fresh() bool {
+	return !c.loadedAt.IsZero() && time.Since(c.loadedAt) < ruleCacheTTL
+}
+
+// Cached rules, loaded again when they were dropped or are too old
+func (c *ruleCache[T]) get(ctx context.Context, load func(context.Context) ([]T, error)) ([]T, error) {
+	c.mu.RLock()
+	if c.fresh() {
+		defer c.mu.RUnlock()
+		return c.rules, nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fresh() {
+		return c.rules, nil
+	}
+
+	rules, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.rules = rules
+	c.loadedAt = time.Now()
+	return rules, nil
+}
+
+func (c *ruleCache[T]) invalidate() {
+	c.mu.Lock()
+	c.loadedAt = time.Time{}
+	c.rules = nil
+	c.mu.Unlock()
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"1337b04rd/internal/domain"
+)
+
+type ShadowBanService struct {
+	shadowBanRepo domain.ShadowBanRepository
+	auditService  AuditService
+}
+
+func NewShadowBanService(shadowBanRepo domain.ShadowBanRepository, auditService AuditService) *ShadowBanService {
+	return &ShadowBanService{
+		shadowBanRepo: shadowBanRepo,
+		auditService:  auditService,
+	}
+}
+
+// Like bans, shadow bans apply to every board and need a global moderator
+
+func (s *ShadowBanService) SetShadowBan(ctx context.Context, moderator *domain.Moderator, sessionID string, banned bool, reason string) error {
+	if !moderator.HasRole(domain.AllBoards, domain.RoleModerator) {
+		return domain.ErrForbidden
+	}
+
+	if err := s.shadowBanRepo.SetShadowBanned(ctx, sessionID, banned); err != nil {
+		return err
+	}
+
+	action := "shadowban.create"
+	if !banned {
+		action = "shadowban.lift"
+	}
+
+	slog.Info("Moderator changed shadow ban", "session", sessionID, "banned", banned, "moderator", moderator.Username)
+	return s.auditService.Record(ctx, moderator, action, domain.TargetSession, sessionID, reason, nil, map[string]bool{"shadow_banned": banned})
+}
+
+func (s *ShadowBanService) ListShadowBanned(ctx context.Context, moderator *domain.Moderator) ([]*domain.ShadowBannedSession, error) {
+	if !moderator.HasRole(domain.AllBoards, domain.RoleModerator) {
+		return nil, domain.ErrForbidden
+	}
+	return s.shadowBanRepo.ListSessions(ctx)
+}
+
+func (s *ShadowBanService) GetShadowContent(ctx context.Context, moderator *domain.Moderator, sessionID string) (*domain.ShadowContent, error) {
+	if !moderator.HasRole(domain.AllBoards, domain.RoleModerator) {
+		return nil, domain.ErrForbidden
+	}
+	return s.shadowBanRepo.FindContent(ctx, sessionID)
+}
+
+// Soft delete all content of the given shadow-banned sessions (all of them if
+// none are given), returns how many posts and comments were deleted
+
+func (s *ShadowBanService) Purge(ctx context.Context, moderator *domain.Moderator, req *domain.PurgeShadowBannedReq) (int, error) {
+	if !moderator.HasRole(domain.AllBoards, domain.RoleModerator) {
+		return 0, domain.ErrForbidden
+	}
+
+	purged, err := s.shadowBanRepo.Purge(ctx, req.SessionIDs, moderator.ID, req.Reason)
+	if err != nil {
+		return 0, err
+	}
+
+	slog.Info("Moderator purged shadow-banned content", "sessions", len(req.SessionIDs), "purged", purged, "moderator", moderator.Username)
+
+	targets := req.SessionIDs
+	if len(targets) == 0 {
+		targets = []string{"*"}
+	}
+	for _, sessionID := range targets {
+		if err := s.auditService.Record(ctx, moderator, "shadowban.purge", domain.TargetSession, sessionID, req.Reason, nil, map[string]int{"purged": purged}); err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for ShadowBanService dependencies
+// --------------------
+
+type MockShadowBanRepo struct {
+	banned       map[string]bool
+	purgedFor    []string
+	purgedBy     string
+	purgedResult int
+}
+
+func (m *MockShadowBanRepo) SetShadowBanned(ctx context.Context, sessionID string, banned bool) error {
+	if m.banned == nil {
+		m.banned = make(map[string]bool)
+	}
+	m.banned[sessionID] = banned
+	return nil
+}
+
+func (m *MockShadowBanRepo) ListSessions(ctx context.Context) ([]*domain.ShadowBannedSession, error) {
+	return nil, nil
+}
+
+func (m *MockShadowBanRepo) FindContent(ctx context.Context, sessionID string) (*domain.ShadowContent, error) {
+	return &domain.ShadowContent{}, nil
+}
+
+func (m *MockShadowBanRepo) Purge(ctx context.Context, sessionIDs []string, deletedBy string, reason string) (int, error) {
+	m.purgedFor = sessionIDs
+	m.purgedBy = deletedBy
+	return m.purgedResult, nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestSetShadowBan(t *testing.T) {
+	repo := &MockShadowBanRepo{}
+	auditRepo := &MockAuditRepo{}
+	svc := NewShadowBanService(repo, *NewAuditService(auditRepo))
+
+	janitor := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleJanitor}}}
+	if err := svc.SetShadowBan(context.Background(), janitor, "s1", true, ""); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+
+	moderator := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleModerator}}}
+	if err := svc.SetShadowBan(context.Background(), moderator, "s1", true, "spam"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.banned["s1"] {
+		t.Errorf("expected s1 to be shadow-banned")
+	}
+
+	if err := svc.SetShadowBan(context.Background(), moderator, "s1", false, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.banned["s1"] {
+		t.Errorf("expected shadow ban of s1 to be lifted")
+	}
+
+	if len(auditRepo.entries) != 2 || auditRepo.entries[0].Action != "shadowban.create" || auditRepo.entries[1].Action != "shadowban.lift" {
+		t.Errorf("unexpected audit entries %+v", auditRepo.entries)
+	}
+}
+
+func TestPurgeShadowBanned(t *testing.T) {
+	repo := &MockShadowBanRepo{purgedResult: 7}
+	auditRepo := &MockAuditRepo{}
+	svc := NewShadowBanService(repo, *NewAuditService(auditRepo))
+	moderator := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleModerator}}}
+
+	purged, err := svc.Purge(context.Background(), moderator, &domain.PurgeShadowBannedReq{SessionIDs: []string{"s1", "s2"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if purged != 7 || len(repo.purgedFor) != 2 || repo.purgedBy != "m2" {
+		t.Errorf("unexpected purge: %d for %v by %q", purged, repo.purgedFor, repo.purgedBy)
+	}
+	if len(auditRepo.entries) != 2 {
+		t.Errorf("expected one audit entry per session, got %d", len(auditRepo.entries))
+	}
+}
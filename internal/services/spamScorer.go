@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+
+	"1337b04rd/internal/domain"
+)
+
+// The scorer has no opinion until moderators taught it this many texts of
+// each kind
+const spamMinTexts = 10
+
+// Paul Graham's "A Plan for Spam" with Robinson's correction for rare
+// tokens: only the tokens that say the most about a text are combined
+const (
+	spamTokenMaxLength    = 40
+	spamMaxTokens         = 200
+	spamInterestingTokens = 15
+	spamRareStrength      = 1.0 // Weight of the neutral guess for rare tokens
+	spamMinProbability    = 0.01
+	spamMaxProbability    = 0.99
+)
+
+// Naive Bayes SpamScorer trained from moderator decisions
+type BayesSpamScorer struct {
+	corpus domain.SpamCorpusRepository
+}
+
+var _ domain.SpamScorer = (*BayesSpamScorer)(nil)
+
+func NewBayesSpamScorer(corpus domain.SpamCorpusRepository) *BayesSpamScorer {
+	return &BayesSpamScorer{
+		corpus: corpus,
+	}
+}
+
+func (s *BayesSpamScorer) Score(ctx context.Context, text string) (float64, error) {
+	tokens := spamTokens(text)
+	if len(tokens) == 0 {
+		return 0.5, nil
+	}
+
+	counts, totals, err := s.corpus.Counts(ctx, tokens)
+	if err != nil {
+		return 0, err
+	}
+	if totals.Spam < spamMinTexts || totals.Ham < spamMinTexts {
+		return 0.5, nil
+	}
+
+	probabilities := make([]float64, 0, len(tokens))
+	for _, token := range tokens {
+		count, ok := counts[token]
+		if !ok {
+			continue
+		}
+		probabilities = append(probabilities, tokenSpamProbability(count, totals))
+	}
+
+	// Most telling tokens first, the ones furthest from neutral
+	sort.Slice(probabilities, func(i, j int) bool {
+		return math.Abs(probabilities[i]-0.5) > math.Abs(probabilities[j]-0.5)
+	})
+	if len(probabilities) > spamInterestingTokens {
+		probabilities = probabilities[:spamInterestingTokens]
+	}
+
+	var logSpam, logHam float64
+	for _, p := range probabilities {
+		logSpam += math.Log(p)
+		logHam += math.Log(1 - p)
+	}
+	return 1 / (1 + math.Exp(logHam-logSpam)), nil
+}
+
+func (s *BayesSpamScorer) Train(ctx context.Context, text string, spam bool) error {
+	tokens := spamTokens(text)
+	if len(tokens) == 0 {
+		return nil
+	}
+	return s.corpus.Learn(ctx, tokens, spam)
+}
+
+// Probability that a text with the token is spam, pulled towards neutral for
+// tokens seen only a few times
+func tokenSpamProbability(count domain.SpamTokenCount, totals domain.SpamTotals) float64 {
+	spamFreq := float64(count.Spam) / float64(totals.Spam)
+	hamFreq := float64(count.Ham) / float64(totals.Ham)
+	if spamFreq+hamFreq == 0 {
+		return 0.5
+	}
+
+	seen := float64(count.Spam + count.Ham)
+	p := spamFreq / (spamFreq + hamFreq)
+	p = (spamRareStrength*0.5 + seen*p) / (spamRareStrength + seen)
+	return min(max(p, spamMinProbability), spamMaxProbability)
+}
+
+// Distinct words of the normalized text, so homoglyphs and punctuation do
+// not hide known spam words
+func spamTokens(text string) []string {
+	seen := map[string]bool{}
+	var tokens []string
+	for _, word := range strings.Fields(domain.NormalizeContent(text)) {
+		if len(word) > spamTokenMaxLength || seen[word] {
+			continue
+		}
+		seen[word] = true
+		tokens = append(tokens, word)
+		if len(tokens) == spamMaxTokens {
+			break
+		}
+	}
+	return tokens
+}
+
+// Scoring failures must not stop posting, the text is taken as neutral
+func scoreSpam(ctx context.Context, scorer domain.SpamScorer, text string) float64 {
+	score, err := scorer.Score(ctx, text)
+	if err != nil {
+		slog.Error("Failed to score spam", "error", err)
+		return 0.5
+	}
+	return score
+}
+
+// Moderator decisions are fed to the scorer as examples
+func trainSpam(ctx context.Context, scorer domain.SpamScorer, text string, spam bool) {
+	if err := scorer.Train(ctx, text, spam); err != nil {
+		slog.Error("Failed to train spam scorer", "spam", spam, "error", err)
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for spam scoring
+// --------------------
+
+// Scorer with a fixed score that remembers what it was taught
+type MockSpamScorer struct {
+	score float64
+	spam  []string
+	ham   []string
+}
+
+func (m *MockSpamScorer) Score(ctx context.Context, text string) (float64, error) {
+	return m.score, nil
+}
+
+func (m *MockSpamScorer) Train(ctx context.Context, text string, spam bool) error {
+	if spam {
+		m.spam = append(m.spam, text)
+	} else {
+		m.ham = append(m.ham, text)
+	}
+	return nil
+}
+
+type MockSpamCorpusRepo struct {
+	tokens map[string]domain.SpamTokenCount
+	totals domain.SpamTotals
+}
+
+func (m *MockSpamCorpusRepo) Counts(ctx context.Context, tokens []string) (map[string]domain.SpamTokenCount, domain.SpamTotals, error) {
+	counts := map[string]domain.SpamTokenCount{}
+	for _, token := range tokens {
+		if count, ok := m.tokens[token]; ok {
+			counts[token] = count
+		}
+	}
+	return counts, m.totals, nil
+}
+
+func (m *MockSpamCorpusRepo) Learn(ctx context.Context, tokens []string, spam bool) error {
+	if m.tokens == nil {
+		m.tokens = map[string]domain.SpamTokenCount{}
+	}
+	for _, token := range tokens {
+		count := m.tokens[token]
+		if spam {
+			count.Spam++
+		} else {
+			count.Ham++
+		}
+		m.tokens[token] = count
+	}
+	if spam {
+		m.totals.Spam++
+	} else {
+		m.totals.Ham++
+	}
+	return nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestBayesSpamScorer(t *testing.T) {
+	scorer := NewBayesSpamScorer(&MockSpamCorpusRepo{})
+	ctx := context.Background()
+
+	if score, _ := scorer.Score(ctx, "cheap pills online"); score != 0.5 {
+		t.Errorf("expected no opinion before training, got %f", score)
+	}
+
+	spam := []string{
+		"Cheap pills online, buy now at the best pharmacy",
+		"Buy cheap watches online now, free shipping",
+		"Best casino bonus, buy chips now and win",
+		"Cheap replica bags online, buy now",
+		"Free crypto giveaway, send coins now",
+		"Buy followers cheap, best prices online",
+		"Cheap pills without prescription, order now",
+		"Win money fast, best casino online now",
+		"Buy cheap software licenses online now",
+		"Hot singles online now, free signup",
+	}
+	ham := []string{
+		"Has anyone finished the new season of the show yet?",
+		"I think the second album was better than the first",
+		"What keyboard are you using for programming?",
+		"The ending of that book made no sense to me",
+		"Anyone here tried baking sourdough at home?",
+		"My cat keeps sitting on the keyboard while I work",
+		"Which linux distribution do you use and why?",
+		"The weather has been terrible all week here",
+		"Is the new graphics card worth the upgrade?",
+		"Recommend me a good science fiction novel please",
+	}
+	for _, text := range spam {
+		if err := scorer.Train(ctx, text, true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	for _, text := range ham {
+		scorer.Train(ctx, text, false)
+	}
+
+	spamScore, err := scorer.Score(ctx, "BUY cheap pills ONLINE now!!")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spamScore < domain.SpamHoldScore {
+		t.Errorf("expected spam to score at least %f, got %f", domain.SpamHoldScore, spamScore)
+	}
+
+	hamScore, _ := scorer.Score(ctx, "Which science fiction book should I read next?")
+	if hamScore > 0.5 {
+		t.Errorf("expected ham to score low, got %f", hamScore)
+	}
+
+	if score, _ := scorer.Score(ctx, "zebra xylophone quokka"); score != 0.5 {
+		t.Errorf("expected unknown words to be neutral, got %f", score)
+	}
+}
+
+func TestResolveReports_TrainsSpamScorer(t *testing.T) {
+	moderator := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleJanitor}}}
+
+	tests := []struct {
+		name string
+		req  *domain.ResolveReportsReq
+		spam int
+		ham  int
+	}{
+		{name: "dismissed", req: &domain.ResolveReportsReq{Action: domain.ReportDismiss}, ham: 1},
+		{name: "deleted as spam", req: &domain.ResolveReportsReq{Action: domain.ReportDelete, Spam: true}, spam: 1},
+		{name: "deleted for another reason", req: &domain.ResolveReportsReq{Action: domain.ReportDelete}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reportRepo := &MockReportRepo{target: &domain.ReportTarget{Board: "b", Text: "buy now"}, resolved: 1}
+			scorer := &MockSpamScorer{}
+			svc := NewReportService(reportRepo, &MockPostRepo{}, &MockCommentRepo{}, BanService{}, *NewAuditService(&MockAuditRepo{}), scorer)
+
+			if _, err := svc.ResolveReports(context.Background(), moderator, domain.TargetPost, "p1", tt.req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(scorer.spam) != tt.spam || len(scorer.ham) != tt.ham {
+				t.Errorf("expected %d spam and %d ham lessons, got %q and %q", tt.spam, tt.ham, scorer.spam, scorer.ham)
+			}
+		})
+	}
+}
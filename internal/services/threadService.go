@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"1337b04rd/internal/domain"
+)
+
+type ThreadService struct {
+	threadRepo   domain.ThreadRepository
+	postRepo     domain.PostRepository
+	commentRepo  domain.CommentRepository
+	auditService AuditService
+}
+
+func NewThreadService(threadRepo domain.ThreadRepository, postRepo domain.PostRepository, commentRepo domain.CommentRepository, auditService AuditService) *ThreadService {
+	return &ThreadService{
+		threadRepo:   threadRepo,
+		postRepo:     postRepo,
+		commentRepo:  commentRepo,
+		auditService: auditService,
+	}
+}
+
+// Move a thread to another board, the moderator needs both boards
+
+func (s *ThreadService) MoveThread(ctx context.Context, id string, moderator *domain.Moderator, req *domain.MoveThreadReq) error {
+	if !domain.ValidBoard(req.Board) {
+		return domain.ErrInvalidBoard
+	}
+
+	post, err := s.postRepo.FindByID(ctx, id, "", true)
+	if err != nil {
+		return err
+	}
+
+	if !moderator.HasRole(post.Board, domain.RoleModerator) || !moderator.HasRole(req.Board, domain.RoleModerator) {
+		return domain.ErrForbidden
+	}
+	if post.Board == req.Board {
+		return domain.ErrSameBoard
+	}
+
+	if err := s.threadRepo.Move(ctx, id, req.Board); err != nil {
+		return err
+	}
+
+	slog.Info("Moderator moved thread", "post", id, "from", post.Board, "to", req.Board, "moderator", moderator.Username)
+	return s.auditService.Record(ctx, moderator, "thread.move", domain.TargetPost, id, req.Reason,
+		map[string]string{"board": post.Board}, map[string]string{"board": req.Board})
+}
+
+// Merge the thread into another one, the merged thread is deleted and its
+// comments continue in the other one
+
+func (s *ThreadService) MergeThreads(ctx context.Context, id string, moderator *domain.Moderator, req *domain.MergeThreadReq) error {
+	if id == req.Into {
+		return domain.ErrMergeIntoItself
+	}
+
+	source, err := s.postRepo.FindByID(ctx, id, "", true)
+	if err != nil {
+		return err
+	}
+	target, err := s.postRepo.FindByID(ctx, req.Into, "", true)
+	if err != nil {
+		return err
+	}
+
+	if !moderator.HasRole(source.Board, domain.RoleModerator) || !moderator.HasRole(target.Board, domain.RoleModerator) {
+		return domain.ErrForbidden
+	}
+
+	moved, err := s.threadRepo.Merge(ctx, id, req.Into, moderator.ID)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Moderator merged threads", "post", id, "into", req.Into, "comments", moved, "moderator", moderator.Username)
+	return s.auditService.Record(ctx, moderator, "thread.merge", domain.TargetPost, id, req.Reason,
+		source, map[string]any{"merged_into": req.Into, "moved_comments": moved})
+}
+
+// Open a new thread from a comment and the replies below it
+
+func (s *ThreadService) SplitThread(ctx context.Context, commentID string, moderator *domain.Moderator, req *domain.SplitThreadReq) (string, error) {
+	title := strings.TrimSpace(req.Title)
+	if len(title) < 5 {
+		return "", domain.ErrTitleTooShort
+	}
+
+	comment, err := s.commentRepo.FindByID(ctx, commentID)
+	if err != nil {
+		return "", err
+	}
+	post, err := s.postRepo.FindByID(ctx, comment.PostID, "", true)
+	if err != nil {
+		return "", err
+	}
+
+	if !moderator.HasRole(post.Board, domain.RoleModerator) {
+		return "", domain.ErrForbidden
+	}
+
+	newID, moved, err := s.threadRepo.Split(ctx, commentID, title, moderator.ID)
+	if err != nil {
+		return "", err
+	}
+
+	slog.Info("Moderator split thread", "comment", commentID, "from", post.ID, "to", newID, "replies", moved, "moderator", moderator.Username)
+
+	err = s.auditService.Record(ctx, moderator, "comment.split", domain.TargetComment, commentID, req.Reason,
+		map[string]string{"post_id": post.ID}, map[string]any{"post_id": newID, "moved_replies": moved})
+	return newID, err
+}
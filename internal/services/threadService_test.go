@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for ThreadService dependencies
+// --------------------
+
+type MockThreadRepo struct {
+	movedTo    string
+	mergedInto string
+	splitFrom  string
+}
+
+func (m *MockThreadRepo) Move(ctx context.Context, postID string, board string) error {
+	m.movedTo = board
+	return nil
+}
+
+func (m *MockThreadRepo) Merge(ctx context.Context, sourceID string, targetID string, deletedBy string) (int, error) {
+	m.mergedInto = targetID
+	return 3, nil
+}
+
+func (m *MockThreadRepo) Split(ctx context.Context, commentID string, title string, deletedBy string) (string, int, error) {
+	m.splitFrom = commentID
+	return "p3", 2, nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func threadPosts() *MockPostRepo {
+	return &MockPostRepo{
+		findErr: domain.ErrNotFound,
+		posts: map[string]*domain.Post{
+			"p1": {ID: "p1", Board: "b"},
+			"p2": {ID: "p2", Board: "g"},
+		},
+	}
+}
+
+func TestMoveThread(t *testing.T) {
+	moderatorOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleModerator}}}
+	moderatorOfBoth := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleModerator}, {Board: "g", Role: domain.RoleModerator}}}
+
+	tests := []struct {
+		name      string
+		moderator *domain.Moderator
+		board     string
+		err       error
+	}{
+		{name: "both boards", moderator: moderatorOfBoth, board: "g"},
+		{name: "only source board", moderator: moderatorOfB, board: "g", err: domain.ErrForbidden},
+		{name: "same board", moderator: moderatorOfBoth, board: "b", err: domain.ErrSameBoard},
+		{name: "bad board", moderator: moderatorOfBoth, board: "G!", err: domain.ErrInvalidBoard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threadRepo := &MockThreadRepo{}
+			auditRepo := &MockAuditRepo{}
+			svc := NewThreadService(threadRepo, threadPosts(), &MockCommentRepo{}, *NewAuditService(auditRepo))
+
+			err := svc.MoveThread(context.Background(), "p1", tt.moderator, &domain.MoveThreadReq{Board: tt.board})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if tt.err != nil {
+				if threadRepo.movedTo != "" {
+					t.Errorf("expected no move, got move to %q", threadRepo.movedTo)
+				}
+				return
+			}
+			if threadRepo.movedTo != tt.board {
+				t.Errorf("expected move to %q, got %q", tt.board, threadRepo.movedTo)
+			}
+			if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != "thread.move" {
+				t.Errorf("expected audited thread.move, got %+v", auditRepo.entries)
+			}
+		})
+	}
+}
+
+func TestMergeThreads(t *testing.T) {
+	moderatorOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleModerator}}}
+	global := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleModerator}}}
+
+	threadRepo := &MockThreadRepo{}
+	auditRepo := &MockAuditRepo{}
+	svc := NewThreadService(threadRepo, threadPosts(), &MockCommentRepo{}, *NewAuditService(auditRepo))
+
+	if err := svc.MergeThreads(context.Background(), "p1", global, &domain.MergeThreadReq{Into: "p1"}); !errors.Is(err, domain.ErrMergeIntoItself) {
+		t.Fatalf("expected ErrMergeIntoItself, got %v", err)
+	}
+	if err := svc.MergeThreads(context.Background(), "p1", global, &domain.MergeThreadReq{Into: "missing"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := svc.MergeThreads(context.Background(), "p1", moderatorOfB, &domain.MergeThreadReq{Into: "p2"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden without the target board, got %v", err)
+	}
+
+	if err := svc.MergeThreads(context.Background(), "p1", global, &domain.MergeThreadReq{Into: "p2", Reason: "duplicate"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if threadRepo.mergedInto != "p2" {
+		t.Errorf("expected merge into p2, got %q", threadRepo.mergedInto)
+	}
+	if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != "thread.merge" || auditRepo.entries[0].TargetID != "p1" {
+		t.Errorf("expected audited thread.merge of p1, got %+v", auditRepo.entries)
+	}
+}
+
+func TestSplitThread(t *testing.T) {
+	moderatorOfB := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleModerator}}}
+	moderatorOfG := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: "g", Role: domain.RoleModerator}}}
+
+	threadRepo := &MockThreadRepo{}
+	auditRepo := &MockAuditRepo{}
+	commentRepo := &MockCommentRepo{findComment: &domain.Comment{ID: "c1", PostID: "p1"}}
+	svc := NewThreadService(threadRepo, threadPosts(), commentRepo, *NewAuditService(auditRepo))
+
+	if _, err := svc.SplitThread(context.Background(), "c1", moderatorOfB, &domain.SplitThreadReq{Title: " hi "}); !errors.Is(err, domain.ErrTitleTooShort) {
+		t.Fatalf("expected ErrTitleTooShort, got %v", err)
+	}
+	if _, err := svc.SplitThread(context.Background(), "c1", moderatorOfG, &domain.SplitThreadReq{Title: "Off topic part"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden on another board, got %v", err)
+	}
+
+	postID, err := svc.SplitThread(context.Background(), "c1", moderatorOfB, &domain.SplitThreadReq{Title: "Off topic part"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if postID != "p3" || threadRepo.splitFrom != "c1" {
+		t.Errorf("expected split of c1 into p3, got %q from %q", postID, threadRepo.splitFrom)
+	}
+	if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != "comment.split" {
+		t.Errorf("expected audited comment.split, got %+v", auditRepo.entries)
+	}
+}
@@ -3,43 +3,143 @@ package services
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"1337b04rd/internal/domain"
 )
 
+// Restoring a session is limited to this many attempts per client per window
+const (
+	restoreAttemptsLimit  = 5
+	restoreAttemptsWindow = 15 * time.Minute
+)
+
 type UserService struct {
-	userRepo       domain.UserRepository
-	userOutlookAPI domain.UserOutlookAPI
+	userRepo        domain.UserRepository
+	userOutlookAPI  domain.UserOutlookAPI
+	avatarAssigner  domain.AvatarAssigner
+	uniqueUsernames bool // Reject names already used by another active session
+	restoreLimiter  *attemptLimiter
+	auditService    AuditService
 }
 
-func NewUserService(userRepo domain.UserRepository, userOutlookAPI domain.UserOutlookAPI) *UserService {
+func NewUserService(userRepo domain.UserRepository, userOutlookAPI domain.UserOutlookAPI, avatarAssigner domain.AvatarAssigner, uniqueUsernames bool, auditService AuditService) *UserService {
 	return &UserService{
-		userRepo:       userRepo,
-		userOutlookAPI: userOutlookAPI,
+		userRepo:        userRepo,
+		userOutlookAPI:  userOutlookAPI,
+		avatarAssigner:  avatarAssigner,
+		uniqueUsernames: uniqueUsernames,
+		restoreLimiter:  newAttemptLimiter(restoreAttemptsLimit, restoreAttemptsWindow),
+		auditService:    auditService,
 	}
 }
 
 func (s *UserService) CreateUserAndGetID(ctx context.Context) (string, error) {
-	count, err := s.userRepo.GetNumberOfUsers(ctx)
-	slog.Info("got number of users:", "count", count)
+	characterID, err := s.avatarAssigner.NextCharacterID(ctx)
 	if err != nil {
+		slog.Error("Failed to assign character", "error", err)
 		return "", err
 	}
+	slog.Info("Assigned character:", "id", characterID)
 
-	userOutlook, err := s.userOutlookAPI.GenerateAvatarAndName(count + 1)
-	slog.Info("Generated avatar and username", "name", userOutlook.Name)
+	userOutlook, err := s.userOutlookAPI.GenerateAvatarAndName(characterID)
 	if err != nil {
 		slog.Error("Failed to generate avatar and name", "error", err)
 		return "", err
 	}
+	slog.Info("Generated avatar and username", "name", userOutlook.Name)
 
 	return s.userRepo.Save(ctx, userOutlook.AvatarURL, userOutlook.Name)
 }
 
+// Validate the new name against the naming policy before storing it
+
 func (s *UserService) ChangeUsername(ctx context.Context, session_id string, newUsername string) error {
-	return s.userRepo.ChangeName(ctx, newUsername, session_id)
+	name, err := domain.ValidateUsername(newUsername)
+	if err != nil {
+		return err
+	}
+
+	if s.uniqueUsernames {
+		taken, err := s.userRepo.UsernameTaken(ctx, domain.UsernameKey(name), session_id)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return domain.ErrUsernameTaken
+		}
+	}
+
+	return s.userRepo.ChangeName(ctx, name, session_id)
+}
+
+// Moderators rename sessions with offensive names, the name policy applies
+// to them as well but uniqueness does not
+
+func (s *UserService) RenameByModerator(ctx context.Context, moderator *domain.Moderator, session_id string, newUsername string, reason string) error {
+	if !moderator.HasRole(domain.AllBoards, domain.RoleModerator) {
+		return domain.ErrForbidden
+	}
+
+	name, err := domain.ValidateUsername(newUsername)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session_id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.ChangeName(ctx, name, session_id); err != nil {
+		return err
+	}
+
+	slog.Info("Moderator renamed session", "session", session_id, "moderator", moderator.Username)
+
+	before := map[string]string{"username": user.Username}
+	after := map[string]string{"username": name}
+	return s.auditService.Record(ctx, moderator, "session.rename", domain.TargetSession, session_id, reason, before, after)
 }
 
 func (s *UserService) FindUserByID(ctx context.Context, session_id string) (*domain.User, error) {
 	return s.userRepo.FindByID(ctx, session_id)
 }
+
+// Issue a new recovery phrase for the session, the previous one stops working
+
+func (s *UserService) ExportSession(ctx context.Context, session_id string) (string, error) {
+	if _, err := s.userRepo.FindByID(ctx, session_id); err != nil {
+		return "", err
+	}
+
+	phrase, err := domain.NewRecoveryPhrase()
+	if err != nil {
+		slog.Error("Failed to generate recovery phrase", "error", err)
+		return "", err
+	}
+
+	if err := s.userRepo.SaveRecoveryKey(ctx, session_id, domain.HashRecoveryPhrase(phrase)); err != nil {
+		slog.Error("Failed to save recovery key", "error", err)
+		return "", err
+	}
+
+	return phrase, nil
+}
+
+// Exchange a recovery phrase for the session it was issued to. The phrase can
+// be used only once and attempts are limited per client.
+
+func (s *UserService) RestoreSession(ctx context.Context, phrase string, clientKey string) (*domain.User, error) {
+	if s.restoreLimiter != nil && !s.restoreLimiter.Allow(clientKey) {
+		slog.Warn("Too many session restore attempts", "client", clientKey)
+		return nil, domain.ErrTooManyAttempts
+	}
+
+	sessionID, err := s.userRepo.UseRecoveryKey(ctx, domain.HashRecoveryPhrase(phrase))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.FindByID(ctx, sessionID)
+}
@@ -3,6 +3,7 @@ package services_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,19 +14,25 @@ import (
 // --- Fake implementations ---
 
 type fakeUserRepo struct {
-	count       int
 	saveID      string
 	users       map[string]*domain.User
 	saveErr     error
-	countErr    error
 	changeErr   error
 	findByIDErr error
+	keys        map[string]string // key hash -> session id
+	takenKeys   map[string]bool
+	changedName string
 }
 
 func (f *fakeUserRepo) ChangeName(ctx context.Context, newName string, sessionID string) error {
+	f.changedName = newName
 	return f.changeErr
 }
 
+func (f *fakeUserRepo) UsernameTaken(ctx context.Context, usernameKey string, exceptSessionID string) (bool, error) {
+	return f.takenKeys[usernameKey], nil
+}
+
 func (f *fakeUserRepo) Save(ctx context.Context, avatarURL string, name string) (string, error) {
 	if f.saveErr != nil {
 		return "", f.saveErr
@@ -34,13 +41,6 @@ func (f *fakeUserRepo) Save(ctx context.Context, avatarURL string, name string)
 	return f.saveID, nil
 }
 
-func (f *fakeUserRepo) GetNumberOfUsers(ctx context.Context) (int, error) {
-	if f.countErr != nil {
-		return 0, f.countErr
-	}
-	return f.count, nil
-}
-
 func (f *fakeUserRepo) FindByID(ctx context.Context, sessionID string) (*domain.User, error) {
 	if f.findByIDErr != nil {
 		return nil, f.findByIDErr
@@ -48,6 +48,28 @@ func (f *fakeUserRepo) FindByID(ctx context.Context, sessionID string) (*domain.
 	return f.users[sessionID], nil
 }
 
+func (f *fakeUserRepo) SaveRecoveryKey(ctx context.Context, sessionID string, keyHash string) error {
+	if f.keys == nil {
+		f.keys = make(map[string]string)
+	}
+	for hash, id := range f.keys {
+		if id == sessionID {
+			delete(f.keys, hash)
+		}
+	}
+	f.keys[keyHash] = sessionID
+	return nil
+}
+
+func (f *fakeUserRepo) UseRecoveryKey(ctx context.Context, keyHash string) (string, error) {
+	sessionID, ok := f.keys[keyHash]
+	if !ok {
+		return "", domain.ErrInvalidRecoveryPhrase
+	}
+	delete(f.keys, keyHash)
+	return sessionID, nil
+}
+
 type fakeOutlookAPI struct {
 	avatar string
 	name   string
@@ -64,12 +86,21 @@ func (f *fakeOutlookAPI) GenerateAvatarAndName(userNumber int) (*domain.UserOutl
 	}, nil
 }
 
+type fakeAssigner struct {
+	id  int
+	err error
+}
+
+func (f *fakeAssigner) NextCharacterID(ctx context.Context) (int, error) {
+	return f.id, f.err
+}
+
 // --- Tests ---
 
 func TestCreateUserAndGetID_Success(t *testing.T) {
-	repo := &fakeUserRepo{count: 3}
+	repo := &fakeUserRepo{}
 	api := &fakeOutlookAPI{avatar: "avatar.png", name: "Morty"}
-	svc := services.NewUserService(repo, api)
+	svc := services.NewUserService(repo, api, &fakeAssigner{id: 4}, false, services.AuditService{})
 
 	id, err := svc.CreateUserAndGetID(context.Background())
 	if err != nil {
@@ -80,10 +111,10 @@ func TestCreateUserAndGetID_Success(t *testing.T) {
 	}
 }
 
-func TestCreateUserAndGetID_RepoCountError(t *testing.T) {
-	repo := &fakeUserRepo{countErr: errors.New("count fail")}
+func TestCreateUserAndGetID_AssignerError(t *testing.T) {
+	repo := &fakeUserRepo{}
 	api := &fakeOutlookAPI{}
-	svc := services.NewUserService(repo, api)
+	svc := services.NewUserService(repo, api, &fakeAssigner{err: errors.New("sequence fail")}, false, services.AuditService{})
 
 	_, err := svc.CreateUserAndGetID(context.Background())
 	if err == nil {
@@ -91,10 +122,24 @@ func TestCreateUserAndGetID_RepoCountError(t *testing.T) {
 	}
 }
 
+func TestCreateUserAndGetID_OutlookError(t *testing.T) {
+	repo := &fakeUserRepo{}
+	api := &fakeOutlookAPI{err: errors.New("api is down")}
+	svc := services.NewUserService(repo, api, &fakeAssigner{id: 4}, false, services.AuditService{})
+
+	_, err := svc.CreateUserAndGetID(context.Background())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if repo.saveID != "" {
+		t.Error("expected no session to be saved")
+	}
+}
+
 func TestChangeUsername(t *testing.T) {
 	repo := &fakeUserRepo{}
 	api := &fakeOutlookAPI{}
-	svc := services.NewUserService(repo, api)
+	svc := services.NewUserService(repo, api, nil, false, services.AuditService{})
 
 	err := svc.ChangeUsername(context.Background(), "sid", "newname")
 	if err != nil {
@@ -102,6 +147,68 @@ func TestChangeUsername(t *testing.T) {
 	}
 }
 
+func TestChangeUsername_Policy(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      error
+	}{
+		{name: "trims and collapses spaces", input: "  Summer   Smith ", expected: "Summer Smith"},
+		{name: "unicode letters", input: "Жора_42", expected: "Жора_42"},
+		{name: "too short", input: " a ", err: domain.ErrUsernameTooShort},
+		{name: "too long", input: strings.Repeat("я", 33), err: domain.ErrUsernameTooLong},
+		{name: "emoji", input: "Rick 🥒", err: domain.ErrUsernameInvalidChars},
+		{name: "zero width space", input: "Ri\u200bck", err: domain.ErrUsernameInvalidChars},
+		{name: "digits only", input: "1337", err: domain.ErrUsernameInvalidChars},
+		{name: "reserved", input: "Admin", err: domain.ErrUsernameReserved},
+		{name: "reserved with cyrillic letters", input: "Аdmіn", err: domain.ErrUsernameReserved},
+		{name: "reserved with digits", input: "M0derat0r", err: domain.ErrUsernameReserved},
+		{name: "reserved with a one for the i", input: "adm1n", err: domain.ErrUsernameReserved},
+		{name: "reserved with an l for the i", input: "Admln", err: domain.ErrUsernameReserved},
+		{name: "reserved word inside name", input: "Rick the Janitor", err: domain.ErrUsernameReserved},
+		{name: "reserved word as a substring is fine", input: "Badminton", expected: "Badminton"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUserRepo{}
+			svc := services.NewUserService(repo, &fakeOutlookAPI{}, nil, false, services.AuditService{})
+
+			err := svc.ChangeUsername(context.Background(), "sid", tt.input)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if repo.changedName != tt.expected {
+				t.Errorf("expected %q to be stored, got %q", tt.expected, repo.changedName)
+			}
+		})
+	}
+}
+
+func TestChangeUsername_Unique(t *testing.T) {
+	repo := &fakeUserRepo{takenKeys: map[string]bool{"morty": true}}
+
+	// Lookalike of a name used by another session
+	svc := services.NewUserService(repo, &fakeOutlookAPI{}, nil, true, services.AuditService{})
+	err := svc.ChangeUsername(context.Background(), "sid", "Mоrty")
+	if !errors.Is(err, domain.ErrUsernameTaken) {
+		t.Fatalf("expected ErrUsernameTaken, got %v", err)
+	}
+
+	// Uniqueness is opt-in
+	svc = services.NewUserService(repo, &fakeOutlookAPI{}, nil, false, services.AuditService{})
+	if err := svc.ChangeUsername(context.Background(), "sid", "Morty"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
 func TestFindUserByID(t *testing.T) {
 	repo := &fakeUserRepo{
 		users: map[string]*domain.User{
@@ -113,7 +220,7 @@ func TestFindUserByID(t *testing.T) {
 		},
 	}
 	api := &fakeOutlookAPI{}
-	svc := services.NewUserService(repo, api)
+	svc := services.NewUserService(repo, api, nil, false, services.AuditService{})
 
 	user, err := svc.FindUserByID(context.Background(), "sid")
 	if err != nil {
@@ -123,3 +230,74 @@ func TestFindUserByID(t *testing.T) {
 		t.Errorf("expected Rick, got %s", user.Username)
 	}
 }
+
+func TestExportAndRestoreSession(t *testing.T) {
+	repo := &fakeUserRepo{
+		users: map[string]*domain.User{
+			"sid": {SessionID: "sid", Username: "Rick"},
+		},
+	}
+	svc := services.NewUserService(repo, &fakeOutlookAPI{}, nil, false, services.AuditService{})
+
+	phrase, err := svc.ExportSession(context.Background(), "sid")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(strings.Fields(phrase)) != domain.RecoveryPhraseWords {
+		t.Fatalf("expected %d words, got %q", domain.RecoveryPhraseWords, phrase)
+	}
+
+	// Case and spacing should not matter when typing the phrase back
+	user, err := svc.RestoreSession(context.Background(), "  "+strings.ToUpper(phrase), "1.1.1.1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if user.SessionID != "sid" {
+		t.Errorf("expected sid, got %s", user.SessionID)
+	}
+
+	// The phrase is single use
+	_, err = svc.RestoreSession(context.Background(), phrase, "1.1.1.1")
+	if !errors.Is(err, domain.ErrInvalidRecoveryPhrase) {
+		t.Errorf("expected ErrInvalidRecoveryPhrase, got %v", err)
+	}
+}
+
+func TestExportSession_InvalidatesOldPhrase(t *testing.T) {
+	repo := &fakeUserRepo{
+		users: map[string]*domain.User{"sid": {SessionID: "sid"}},
+	}
+	svc := services.NewUserService(repo, &fakeOutlookAPI{}, nil, false, services.AuditService{})
+
+	oldPhrase, err := svc.ExportSession(context.Background(), "sid")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := svc.ExportSession(context.Background(), "sid"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = svc.RestoreSession(context.Background(), oldPhrase, "1.1.1.1")
+	if !errors.Is(err, domain.ErrInvalidRecoveryPhrase) {
+		t.Errorf("expected ErrInvalidRecoveryPhrase, got %v", err)
+	}
+}
+
+func TestRestoreSession_RateLimited(t *testing.T) {
+	repo := &fakeUserRepo{}
+	svc := services.NewUserService(repo, &fakeOutlookAPI{}, nil, false, services.AuditService{})
+
+	var err error
+	for i := 0; i < 10; i++ {
+		_, err = svc.RestoreSession(context.Background(), "wrong phrase", "2.2.2.2")
+	}
+	if !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Fatalf("expected ErrTooManyAttempts, got %v", err)
+	}
+
+	// Other clients are not affected
+	_, err = svc.RestoreSession(context.Background(), "wrong phrase", "3.3.3.3")
+	if !errors.Is(err, domain.ErrInvalidRecoveryPhrase) {
+		t.Errorf("expected ErrInvalidRecoveryPhrase, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"1337b04rd/internal/domain"
+)
+
+type WatchService struct {
+	watchRepo domain.WatchRepository
+	postRepo  domain.PostRepository
+}
+
+func NewWatchService(watchRepo domain.WatchRepository, postRepo domain.PostRepository) *WatchService {
+	return &WatchService{
+		watchRepo: watchRepo,
+		postRepo:  postRepo,
+	}
+}
+
+// Watch a thread the session can see, watching it again changes nothing
+
+func (s *WatchService) Watch(ctx context.Context, sessionID string, postID string) error {
+	if _, err := s.postRepo.FindByID(ctx, postID, sessionID, false); err != nil {
+		return err
+	}
+
+	added, err := s.watchRepo.Watch(ctx, sessionID, postID, domain.MaxWatchedThreads)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return domain.ErrTooManyWatched
+	}
+	return nil
+}
+
+func (s *WatchService) Unwatch(ctx context.Context, sessionID string, postID string) error {
+	return s.watchRepo.Unwatch(ctx, sessionID, postID)
+}
+
+func (s *WatchService) Watched(ctx context.Context, sessionID string) ([]*domain.WatchedThread, error) {
+	threads, err := s.watchRepo.ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if threads == nil {
+		threads = []*domain.WatchedThread{}
+	}
+	return threads, nil
+}
+
+// Called when the session loads the replies of a thread, a failure only
+// shows some replies as new once more. Readers without a valid session
+// watch nothing, so the database is not asked.
+
+func (s *WatchService) MarkViewed(ctx context.Context, sessionID string, postID string) {
+	if !domain.IsUUID(sessionID) {
+		return
+	}
+	if err := s.watchRepo.MarkViewed(ctx, sessionID, postID); err != nil {
+		slog.Error("Failed to move last viewed marker", "post", postID, "error", err)
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for WatchService dependencies
+// --------------------
+
+type MockWatchRepo struct {
+	watched map[string]bool
+	count   int
+	viewed  []string
+	threads []*domain.WatchedThread
+}
+
+func (m *MockWatchRepo) Watch(ctx context.Context, sessionID string, postID string, limit int) (bool, error) {
+	if m.watched[postID] {
+		return true, nil
+	}
+	if m.count+len(m.watched) >= limit {
+		return false, nil
+	}
+	if m.watched == nil {
+		m.watched = make(map[string]bool)
+	}
+	m.watched[postID] = true
+	return true, nil
+}
+
+func (m *MockWatchRepo) Unwatch(ctx context.Context, sessionID string, postID string) error {
+	delete(m.watched, postID)
+	return nil
+}
+
+func (m *MockWatchRepo) MarkViewed(ctx context.Context, sessionID string, postID string) error {
+	m.viewed = append(m.viewed, postID)
+	return nil
+}
+
+func (m *MockWatchRepo) ListBySession(ctx context.Context, sessionID string) ([]*domain.WatchedThread, error) {
+	return m.threads, nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestWatchService_Watch(t *testing.T) {
+	watchRepo := &MockWatchRepo{}
+	postRepo := &MockPostRepo{posts: map[string]*domain.Post{"p1": {ID: "p1"}, "p2": {ID: "p2"}}, findErr: domain.ErrNotFound}
+	svc := NewWatchService(watchRepo, postRepo)
+
+	if err := svc.Watch(context.Background(), "s1", "p1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !watchRepo.watched["p1"] {
+		t.Fatal("expected p1 to be watched")
+	}
+
+	if err := svc.Watch(context.Background(), "s1", "gone"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing thread, got %v", err)
+	}
+
+	// Watching again is fine even with a full list, new threads are refused
+	watchRepo.count = domain.MaxWatchedThreads
+	if err := svc.Watch(context.Background(), "s1", "p1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Watch(context.Background(), "s1", "p2"); !errors.Is(err, domain.ErrTooManyWatched) {
+		t.Fatalf("expected ErrTooManyWatched, got %v", err)
+	}
+
+	if err := svc.Unwatch(context.Background(), "s1", "p1"); err != nil || watchRepo.watched["p1"] {
+		t.Fatalf("expected p1 to be unwatched, got %v", err)
+	}
+}
+
+func TestWatchService_WatchedAndViewed(t *testing.T) {
+	watchRepo := &MockWatchRepo{}
+	svc := NewWatchService(watchRepo, &MockPostRepo{})
+
+	threads, err := svc.Watched(context.Background(), "s1")
+	if err != nil || threads == nil {
+		t.Fatalf("expected empty list instead of null, got %v, %v", threads, err)
+	}
+
+	svc.MarkViewed(context.Background(), "", "p1")
+	svc.MarkViewed(context.Background(), "*", "p1")
+	svc.MarkViewed(context.Background(), "6f1c2a4e-8d3b-4c5a-9e7f-0a1b2c3d4e5f", "p1")
+	if len(watchRepo.viewed) != 1 {
+		t.Fatalf("expected only the session's view to be recorded, got %v", watchRepo.viewed)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"1337b04rd/internal/domain"
+)
+
+type WordFilterService struct {
+	filterRepo   domain.WordFilterRepository
+	auditService AuditService
+	cache        *ruleCache[*compiledFilter]
+}
+
+func NewWordFilterService(filterRepo domain.WordFilterRepository, auditService AuditService) *WordFilterService {
+	return &WordFilterService{
+		filterRepo:   filterRepo,
+		auditService: auditService,
+		cache:        &ruleCache[*compiledFilter]{},
+	}
+}
+
+type compiledFilter struct {
+	filter *domain.WordFilter
+	re     *regexp.Regexp
+}
+
+// Rules of a board are managed by its moderators, rules of every board by
+// global moderators
+
+func (s *WordFilterService) CreateFilter(ctx context.Context, moderator *domain.Moderator, req *domain.CreateWordFilterReq) (*domain.WordFilter, error) {
+	board := req.Board
+	if board == "" {
+		board = domain.AllBoards
+	}
+	if board != domain.AllBoards && !domain.ValidBoard(board) {
+		return nil, domain.ErrInvalidBoard
+	}
+	if !moderator.HasRole(board, domain.RoleModerator) {
+		return nil, domain.ErrForbidden
+	}
+
+	if !req.Action.Valid() {
+		return nil, domain.ErrInvalidFilterAction
+	}
+	if utf8.RuneCountInString(req.Message) > domain.FilterMessageMaxLength ||
+		utf8.RuneCountInString(req.Replacement) > domain.FilterMessageMaxLength {
+		return nil, domain.ErrFilterMessageTooLong
+	}
+
+	filter := &domain.WordFilter{
+		Board:     board,
+		Pattern:   strings.TrimSpace(req.Pattern),
+		IsRegex:   req.IsRegex,
+		Action:    req.Action,
+		Message:   strings.TrimSpace(req.Message),
+		CreatedBy: moderator.ID,
+	}
+	if filter.Action == domain.FilterReplace {
+		filter.Replacement = req.Replacement
+	}
+	if filter.Pattern == "" || utf8.RuneCountInString(filter.Pattern) > domain.FilterPatternMaxLength {
+		return nil, domain.ErrInvalidFilterPattern
+	}
+	if _, err := compileFilter(filter); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.filterRepo.Create(ctx, filter); err != nil {
+		return nil, err
+	}
+	s.cache.invalidate()
+
+	slog.Info("Moderator created word filter", "filter", filter.ID, "board", filter.Board, "action", filter.Action, "moderator", moderator.Username)
+
+	if err := s.auditService.Record(ctx, moderator, "filter.create", domain.TargetFilter, filter.ID, "", nil, filter); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func (s *WordFilterService) DeleteFilter(ctx context.Context, moderator *domain.Moderator, id string) error {
+	filter, err := s.filterRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !moderator.HasRole(filter.Board, domain.RoleModerator) {
+		return domain.ErrForbidden
+	}
+
+	if err := s.filterRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.cache.invalidate()
+
+	slog.Info("Moderator deleted word filter", "filter", id, "moderator", moderator.Username)
+	return s.auditService.Record(ctx, moderator, "filter.delete", domain.TargetFilter, id, "", filter, nil)
+}
+
+// Rules of the boards the moderator works on
+
+func (s *WordFilterService) ListFilters(ctx context.Context, moderator *domain.Moderator) ([]*domain.WordFilter, error) {
+	filters, err := s.filterRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*domain.WordFilter, 0, len(filters))
+	for _, filter := range filters {
+		if moderator.HasRole(filter.Board, domain.RoleJanitor) {
+			visible = append(visible, filter)
+		}
+	}
+	return visible, nil
+}
+
+// Run the texts of a new post or comment through the rules of its board, in
+// the order they were created. Replacements are written back into the texts,
+// a reject rule stops with its message.
+
+func (s *WordFilterService) Apply(ctx context.Context, board string, texts ...*string) (*domain.FilterVerdict, error) {
+	rules, err := s.rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verdict := &domain.FilterVerdict{}
+	for _, rule := range rules {
+		if rule.filter.Board != board && rule.filter.Board != domain.AllBoards {
+			continue
+		}
+
+		for _, text := range texts {
+			folded, offsets := domain.FoldText(*text)
+			matches := rule.re.FindAllStringIndex(folded, -1)
+			if matches == nil {
+				continue
+			}
+
+			switch rule.filter.Action {
+			case domain.FilterReject:
+				if rule.filter.Message == "" {
+					return nil, domain.ErrFilteredContent
+				}
+				return nil, &domain.PolicyError{Code: domain.ErrFilteredContent.Code, Message: rule.filter.Message}
+			case domain.FilterHold:
+				if !verdict.Held {
+					verdict.Held = true
+					verdict.HeldBy = rule.filter.Pattern
+				}
+			case domain.FilterReplace:
+				*text = replaceMatches(*text, matches, offsets, rule.filter.Replacement)
+				verdict.Changed = true
+			}
+		}
+	}
+
+	return verdict, nil
+}
+
+func (s *WordFilterService) rules(ctx context.Context) ([]*compiledFilter, error) {
+	return s.cache.get(ctx, s.loadRules)
+}
+
+// Rules are compiled once per load
+func (s *WordFilterService) loadRules(ctx context.Context) ([]*compiledFilter, error) {
+	filters, err := s.filterRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*compiledFilter, 0, len(filters))
+	for _, filter := range filters {
+		rule, err := compileFilter(filter)
+		if err != nil {
+			// Stored rules were checked on creation, skip instead of blocking posting
+			slog.Error("Skipping broken word filter", "filter", filter.ID, "error", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Literal patterns are folded like the text they are matched against, regex
+// patterns are taken as written
+
+func compileFilter(filter *domain.WordFilter) (*compiledFilter, error) {
+	pattern := filter.Pattern
+	if !filter.IsRegex {
+		folded, _ := domain.FoldText(pattern)
+		pattern = regexp.QuoteMeta(folded)
+	}
+
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil || re.MatchString("") {
+		return nil, domain.ErrInvalidFilterPattern
+	}
+	return &compiledFilter{filter: filter, re: re}, nil
+}
+
+// Replace matches found in the folded text at the same places of the original
+
+func replaceMatches(text string, matches [][]int, offsets []int, replacement string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := offsets[match[0]], offsets[match[1]]
+		b.WriteString(text[last:start])
+		b.WriteString(replacement)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
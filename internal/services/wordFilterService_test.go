@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"1337b04rd/internal/domain"
+)
+
+// --------------------
+// Mocks for WordFilterService dependencies
+// --------------------
+
+type MockWordFilterRepo struct {
+	filters []*domain.WordFilter
+	lists   int
+}
+
+func (m *MockWordFilterRepo) Create(ctx context.Context, filter *domain.WordFilter) (string, error) {
+	m.filters = append(m.filters, filter)
+	filter.ID = "f1"
+	return filter.ID, nil
+}
+
+func (m *MockWordFilterRepo) Delete(ctx context.Context, id string) error {
+	for i, filter := range m.filters {
+		if filter.ID == id {
+			m.filters = append(m.filters[:i], m.filters[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *MockWordFilterRepo) FindByID(ctx context.Context, id string) (*domain.WordFilter, error) {
+	for _, filter := range m.filters {
+		if filter.ID == id {
+			return filter, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockWordFilterRepo) List(ctx context.Context) ([]*domain.WordFilter, error) {
+	m.lists++
+	return m.filters, nil
+}
+
+// --------------------
+// Tests
+// --------------------
+
+func TestApplyWordFilters(t *testing.T) {
+	repo := &MockWordFilterRepo{filters: []*domain.WordFilter{
+		{ID: "1", Board: domain.AllBoards, Pattern: "tbh", Action: domain.FilterReplace, Replacement: "desu"},
+		{ID: "2", Board: "b", Pattern: "spam", Action: domain.FilterReject, Message: "no spam here"},
+		{ID: "3", Board: "b", Pattern: `\bbuy\s+now\b`, IsRegex: true, Action: domain.FilterHold},
+		{ID: "4", Board: "g", Pattern: "moot", Action: domain.FilterReject},
+	}}
+	svc := NewWordFilterService(repo, AuditService{})
+
+	tests := []struct {
+		name     string
+		board    string
+		text     string
+		expected string
+		held     bool
+		err      string
+	}{
+		{name: "no match", board: "b", text: "hello there", expected: "hello there"},
+		{name: "replace", board: "b", text: "tbh I agree", expected: "desu I agree"},
+		{name: "replace every match keeps case of the rest", board: "b", text: "TBH, Tbh.", expected: "desu, desu."},
+		{name: "cyrillic homoglyphs", board: "b", text: "tbһ ok", expected: "desu ok"},
+		{name: "fullwidth", board: "b", text: "ｔｂｈ ok", expected: "desu ok"},
+		{name: "zero width joiners", board: "b", text: "t​b‍h ok", expected: "desu ok"},
+		{name: "digits as letters", board: "b", text: "7bh ok", expected: "desu ok"},
+		{name: "combining marks", board: "b", text: "tb́h ok", expected: "desu ok"},
+		{name: "multibyte around match", board: "b", text: "привет tbh мир", expected: "привет desu мир"},
+		{name: "reject with message", board: "b", text: "free ѕраm", err: "no spam here"},
+		{name: "reject default message", board: "g", text: "m00t", err: domain.ErrFilteredContent.Message},
+		{name: "other board", board: "g", text: "spam", expected: "spam"},
+		{name: "hold", board: "b", text: "Buy  now, friend", expected: "Buy  now, friend", held: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := tt.text
+			verdict, err := svc.Apply(context.Background(), tt.board, &text)
+			if tt.err != "" {
+				var policyErr *domain.PolicyError
+				if !errors.As(err, &policyErr) || policyErr.Message != tt.err {
+					t.Fatalf("expected policy error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, text)
+			}
+			if verdict.Held != tt.held {
+				t.Errorf("expected held %v, got %v", tt.held, verdict.Held)
+			}
+		})
+	}
+}
+
+func TestCreateWordFilter_Validation(t *testing.T) {
+	global := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleModerator}}}
+	moderatorOfB := &domain.Moderator{ID: "m2", Roles: []domain.BoardRole{{Board: "b", Role: domain.RoleModerator}}}
+
+	tests := []struct {
+		name      string
+		moderator *domain.Moderator
+		req       domain.CreateWordFilterReq
+		err       error
+	}{
+		{name: "literal", moderator: moderatorOfB, req: domain.CreateWordFilterReq{Board: "b", Pattern: "tbh", Action: domain.FilterReplace, Replacement: "desu"}},
+		{name: "regex", moderator: global, req: domain.CreateWordFilterReq{Pattern: `\bfoo\b`, IsRegex: true, Action: domain.FilterHold}},
+		{name: "every board needs global moderator", moderator: moderatorOfB, req: domain.CreateWordFilterReq{Pattern: "tbh", Action: domain.FilterReject}, err: domain.ErrForbidden},
+		{name: "other board", moderator: moderatorOfB, req: domain.CreateWordFilterReq{Board: "g", Pattern: "tbh", Action: domain.FilterReject}, err: domain.ErrForbidden},
+		{name: "bad board", moderator: global, req: domain.CreateWordFilterReq{Board: "B!", Pattern: "tbh", Action: domain.FilterReject}, err: domain.ErrInvalidBoard},
+		{name: "bad action", moderator: global, req: domain.CreateWordFilterReq{Pattern: "tbh", Action: "ban"}, err: domain.ErrInvalidFilterAction},
+		{name: "empty pattern", moderator: global, req: domain.CreateWordFilterReq{Pattern: "  ", Action: domain.FilterReject}, err: domain.ErrInvalidFilterPattern},
+		{name: "invisible pattern", moderator: global, req: domain.CreateWordFilterReq{Pattern: "​", Action: domain.FilterReject}, err: domain.ErrInvalidFilterPattern},
+		{name: "broken regex", moderator: global, req: domain.CreateWordFilterReq{Pattern: "(foo", IsRegex: true, Action: domain.FilterReject}, err: domain.ErrInvalidFilterPattern},
+		{name: "regex matching everything", moderator: global, req: domain.CreateWordFilterReq{Pattern: "x*", IsRegex: true, Action: domain.FilterReplace}, err: domain.ErrInvalidFilterPattern},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditRepo := &MockAuditRepo{}
+			svc := NewWordFilterService(&MockWordFilterRepo{}, *NewAuditService(auditRepo))
+
+			filter, err := svc.CreateFilter(context.Background(), tt.moderator, &tt.req)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != "filter.create" || auditRepo.entries[0].TargetID != filter.ID {
+				t.Errorf("expected audited filter.create, got %+v", auditRepo.entries)
+			}
+		})
+	}
+}
+
+func TestWordFilterCache(t *testing.T) {
+	repo := &MockWordFilterRepo{}
+	svc := NewWordFilterService(repo, *NewAuditService(&MockAuditRepo{}))
+	moderator := &domain.Moderator{ID: "m1", Roles: []domain.BoardRole{{Board: domain.AllBoards, Role: domain.RoleModerator}}}
+
+	text := "tbh"
+	svc.Apply(context.Background(), "b", &text)
+	svc.Apply(context.Background(), "b", &text)
+	if repo.lists != 1 {
+		t.Fatalf("expected rules to be loaded once, got %d loads", repo.lists)
+	}
+
+	// A copy of the service shares the cache and sees the new rule
+	copied := *svc
+	filter, err := copied.CreateFilter(context.Background(), moderator, &domain.CreateWordFilterReq{Pattern: "tbh", Action: domain.FilterReplace, Replacement: "desu"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc.Apply(context.Background(), "b", &text)
+	if text != "desu" {
+		t.Errorf("expected new rule to apply, got %q", text)
+	}
+
+	if err := svc.DeleteFilter(context.Background(), moderator, filter.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	text = "tbh"
+	svc.Apply(context.Background(), "b", &text)
+	if text != "tbh" {
+		t.Errorf("expected deleted rule to stop applying, got %q", text)
+	}
+	if repo.lists != 3 {
+		t.Errorf("expected a reload after every change, got %d loads", repo.lists)
+	}
+}
+
+func TestRuleCacheExpires(t *testing.T) {
+	repo := &MockWordFilterRepo{}
+	svc := NewWordFilterService(repo, *NewAuditService(&MockAuditRepo{}))
+
+	text := "tbh"
+	svc.Apply(context.Background(), "b", &text)
+
+	// Rules created by another instance show up once the cache is too old
+	svc.cache.loadedAt = time.Now().Add(-ruleCacheTTL)
+	svc.Apply(context.Background(), "b", &text)
+	if repo.lists != 2 {
+		t.Fatalf("expected rules to be loaded again, got %d loads", repo.lists)
+	}
+}